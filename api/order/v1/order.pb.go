@@ -0,0 +1,1558 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: api/order/v1/order.proto
+
+package orderv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Level struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         string                 `protobuf:"bytes,1,opt,name=price,proto3" json:"price,omitempty"`
+	BaseQty       string                 `protobuf:"bytes,2,opt,name=base_qty,json=baseQty,proto3" json:"base_qty,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Level) Reset() {
+	*x = Level{}
+	mi := &file_api_order_v1_order_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Level) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Level) ProtoMessage() {}
+
+func (x *Level) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Level.ProtoReflect.Descriptor instead.
+func (*Level) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Level) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Level) GetBaseQty() string {
+	if x != nil {
+		return x.BaseQty
+	}
+	return ""
+}
+
+type OrderBook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Exchange      string                 `protobuf:"bytes,2,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Pair          string                 `protobuf:"bytes,3,opt,name=pair,proto3" json:"pair,omitempty"`
+	Asks          []*Level               `protobuf:"bytes,4,rep,name=asks,proto3" json:"asks,omitempty"`
+	Bids          []*Level               `protobuf:"bytes,5,rep,name=bids,proto3" json:"bids,omitempty"`
+	ExchangeTime  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=exchange_time,json=exchangeTime,proto3" json:"exchange_time,omitempty"`
+	ReceivedTime  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=received_time,json=receivedTime,proto3" json:"received_time,omitempty"`
+	Sequence      int64                  `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBook) Reset() {
+	*x = OrderBook{}
+	mi := &file_api_order_v1_order_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBook) ProtoMessage() {}
+
+func (x *OrderBook) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBook.ProtoReflect.Descriptor instead.
+func (*OrderBook) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *OrderBook) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *OrderBook) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *OrderBook) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *OrderBook) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+func (x *OrderBook) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *OrderBook) GetExchangeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExchangeTime
+	}
+	return nil
+}
+
+func (x *OrderBook) GetReceivedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReceivedTime
+	}
+	return nil
+}
+
+func (x *OrderBook) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+type OrderBookDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Exchange      string                 `protobuf:"bytes,1,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Pair          string                 `protobuf:"bytes,2,opt,name=pair,proto3" json:"pair,omitempty"`
+	Sequence      int64                  `protobuf:"varint,3,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	ExchangeTime  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=exchange_time,json=exchangeTime,proto3" json:"exchange_time,omitempty"`
+	Asks          []*Level               `protobuf:"bytes,5,rep,name=asks,proto3" json:"asks,omitempty"`
+	Bids          []*Level               `protobuf:"bytes,6,rep,name=bids,proto3" json:"bids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookDelta) Reset() {
+	*x = OrderBookDelta{}
+	mi := &file_api_order_v1_order_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookDelta) ProtoMessage() {}
+
+func (x *OrderBookDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookDelta.ProtoReflect.Descriptor instead.
+func (*OrderBookDelta) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderBookDelta) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *OrderBookDelta) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *OrderBookDelta) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *OrderBookDelta) GetExchangeTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExchangeTime
+	}
+	return nil
+}
+
+func (x *OrderBookDelta) GetAsks() []*Level {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+func (x *OrderBookDelta) GetBids() []*Level {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+type Client struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	ExchangeName  string                 `protobuf:"bytes,2,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Pair          string                 `protobuf:"bytes,4,opt,name=pair,proto3" json:"pair,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Client) Reset() {
+	*x = Client{}
+	mi := &file_api_order_v1_order_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Client) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Client) ProtoMessage() {}
+
+func (x *Client) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Client.ProtoReflect.Descriptor instead.
+func (*Client) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Client) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *Client) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *Client) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *Client) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+type HistoryOrder struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	OrderId             string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	ClientOrderId       string                 `protobuf:"bytes,2,opt,name=client_order_id,json=clientOrderId,proto3" json:"client_order_id,omitempty"`
+	ClientName          string                 `protobuf:"bytes,3,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	ExchangeName        string                 `protobuf:"bytes,4,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Label               string                 `protobuf:"bytes,5,opt,name=label,proto3" json:"label,omitempty"`
+	Pair                string                 `protobuf:"bytes,6,opt,name=pair,proto3" json:"pair,omitempty"`
+	Side                string                 `protobuf:"bytes,7,opt,name=side,proto3" json:"side,omitempty"`
+	Type                string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	BaseQty             string                 `protobuf:"bytes,9,opt,name=base_qty,json=baseQty,proto3" json:"base_qty,omitempty"`
+	Price               string                 `protobuf:"bytes,10,opt,name=price,proto3" json:"price,omitempty"`
+	AlgorithmNamePlaced string                 `protobuf:"bytes,11,opt,name=algorithm_name_placed,json=algorithmNamePlaced,proto3" json:"algorithm_name_placed,omitempty"`
+	LowestSellPrc       string                 `protobuf:"bytes,12,opt,name=lowest_sell_prc,json=lowestSellPrc,proto3" json:"lowest_sell_prc,omitempty"`
+	HighestBuyPrc       string                 `protobuf:"bytes,13,opt,name=highest_buy_prc,json=highestBuyPrc,proto3" json:"highest_buy_prc,omitempty"`
+	CommissionQuoteQty  string                 `protobuf:"bytes,14,opt,name=commission_quote_qty,json=commissionQuoteQty,proto3" json:"commission_quote_qty,omitempty"`
+	TimePlaced          *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=time_placed,json=timePlaced,proto3" json:"time_placed,omitempty"`
+	Status              string                 `protobuf:"bytes,16,opt,name=status,proto3" json:"status,omitempty"`
+	UpdatedAt           *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *HistoryOrder) Reset() {
+	*x = HistoryOrder{}
+	mi := &file_api_order_v1_order_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryOrder) ProtoMessage() {}
+
+func (x *HistoryOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryOrder.ProtoReflect.Descriptor instead.
+func (*HistoryOrder) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HistoryOrder) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetClientOrderId() string {
+	if x != nil {
+		return x.ClientOrderId
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetBaseQty() string {
+	if x != nil {
+		return x.BaseQty
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetAlgorithmNamePlaced() string {
+	if x != nil {
+		return x.AlgorithmNamePlaced
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetLowestSellPrc() string {
+	if x != nil {
+		return x.LowestSellPrc
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetHighestBuyPrc() string {
+	if x != nil {
+		return x.HighestBuyPrc
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetCommissionQuoteQty() string {
+	if x != nil {
+		return x.CommissionQuoteQty
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetTimePlaced() *timestamppb.Timestamp {
+	if x != nil {
+		return x.TimePlaced
+	}
+	return nil
+}
+
+func (x *HistoryOrder) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HistoryOrder) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type GetOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExchangeName  string                 `protobuf:"bytes,1,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Pair          string                 `protobuf:"bytes,2,opt,name=pair,proto3" json:"pair,omitempty"`
+	Depth         int32                  `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	Tick          string                 `protobuf:"bytes,4,opt,name=tick,proto3" json:"tick,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookRequest) Reset() {
+	*x = GetOrderBookRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookRequest) ProtoMessage() {}
+
+func (x *GetOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetOrderBookRequest) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *GetOrderBookRequest) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *GetOrderBookRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *GetOrderBookRequest) GetTick() string {
+	if x != nil {
+		return x.Tick
+	}
+	return ""
+}
+
+type GetOrderBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderBooks    []*OrderBook           `protobuf:"bytes,1,rep,name=order_books,json=orderBooks,proto3" json:"order_books,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookResponse) Reset() {
+	*x = GetOrderBookResponse{}
+	mi := &file_api_order_v1_order_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookResponse) ProtoMessage() {}
+
+func (x *GetOrderBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderBookResponse) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetOrderBookResponse) GetOrderBooks() []*OrderBook {
+	if x != nil {
+		return x.OrderBooks
+	}
+	return nil
+}
+
+type GetLatestOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExchangeName  string                 `protobuf:"bytes,1,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Pair          string                 `protobuf:"bytes,2,opt,name=pair,proto3" json:"pair,omitempty"`
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestOrderBookRequest) Reset() {
+	*x = GetLatestOrderBookRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestOrderBookRequest) ProtoMessage() {}
+
+func (x *GetLatestOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetLatestOrderBookRequest) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *GetLatestOrderBookRequest) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *GetLatestOrderBookRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type SaveOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderBook     *OrderBook             `protobuf:"bytes,1,opt,name=order_book,json=orderBook,proto3" json:"order_book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveOrderBookRequest) Reset() {
+	*x = SaveOrderBookRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveOrderBookRequest) ProtoMessage() {}
+
+func (x *SaveOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*SaveOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SaveOrderBookRequest) GetOrderBook() *OrderBook {
+	if x != nil {
+		return x.OrderBook
+	}
+	return nil
+}
+
+type SaveOrderBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveOrderBookResponse) Reset() {
+	*x = SaveOrderBookResponse{}
+	mi := &file_api_order_v1_order_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveOrderBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveOrderBookResponse) ProtoMessage() {}
+
+func (x *SaveOrderBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveOrderBookResponse.ProtoReflect.Descriptor instead.
+func (*SaveOrderBookResponse) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{9}
+}
+
+type SaveOrderBookDeltaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Delta         *OrderBookDelta        `protobuf:"bytes,1,opt,name=delta,proto3" json:"delta,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveOrderBookDeltaRequest) Reset() {
+	*x = SaveOrderBookDeltaRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveOrderBookDeltaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveOrderBookDeltaRequest) ProtoMessage() {}
+
+func (x *SaveOrderBookDeltaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveOrderBookDeltaRequest.ProtoReflect.Descriptor instead.
+func (*SaveOrderBookDeltaRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SaveOrderBookDeltaRequest) GetDelta() *OrderBookDelta {
+	if x != nil {
+		return x.Delta
+	}
+	return nil
+}
+
+type SaveOrderBookDeltaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveOrderBookDeltaResponse) Reset() {
+	*x = SaveOrderBookDeltaResponse{}
+	mi := &file_api_order_v1_order_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveOrderBookDeltaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveOrderBookDeltaResponse) ProtoMessage() {}
+
+func (x *SaveOrderBookDeltaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveOrderBookDeltaResponse.ProtoReflect.Descriptor instead.
+func (*SaveOrderBookDeltaResponse) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{11}
+}
+
+type GetOrderHistoryRequest struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ClientName          string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	ExchangeName        string                 `protobuf:"bytes,2,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Label               string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Pair                string                 `protobuf:"bytes,4,opt,name=pair,proto3" json:"pair,omitempty"`
+	From                *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=from,proto3" json:"from,omitempty"`
+	To                  *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=to,proto3" json:"to,omitempty"`
+	Side                string                 `protobuf:"bytes,7,opt,name=side,proto3" json:"side,omitempty"`
+	Type                string                 `protobuf:"bytes,8,opt,name=type,proto3" json:"type,omitempty"`
+	AlgorithmNamePlaced string                 `protobuf:"bytes,9,opt,name=algorithm_name_placed,json=algorithmNamePlaced,proto3" json:"algorithm_name_placed,omitempty"`
+	Sort                string                 `protobuf:"bytes,10,opt,name=sort,proto3" json:"sort,omitempty"`
+	Limit               int32                  `protobuf:"varint,11,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor              string                 `protobuf:"bytes,12,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryRequest) Reset() {
+	*x = GetOrderHistoryRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryRequest) ProtoMessage() {}
+
+func (x *GetOrderHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetOrderHistoryRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetOrderHistoryRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetOrderHistoryRequest) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetAlgorithmNamePlaced() string {
+	if x != nil {
+		return x.AlgorithmNamePlaced
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *GetOrderHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetOrderHistoryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type GetOrderHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*HistoryOrder        `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderHistoryResponse) Reset() {
+	*x = GetOrderHistoryResponse{}
+	mi := &file_api_order_v1_order_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderHistoryResponse) ProtoMessage() {}
+
+func (x *GetOrderHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetOrderHistoryResponse) GetOrders() []*HistoryOrder {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *GetOrderHistoryResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type SaveOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Client        *Client                `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	Order         *HistoryOrder          `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveOrderRequest) Reset() {
+	*x = SaveOrderRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveOrderRequest) ProtoMessage() {}
+
+func (x *SaveOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveOrderRequest.ProtoReflect.Descriptor instead.
+func (*SaveOrderRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SaveOrderRequest) GetClient() *Client {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+func (x *SaveOrderRequest) GetOrder() *HistoryOrder {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type SubscribeOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ExchangeName  string                 `protobuf:"bytes,1,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Pair          string                 `protobuf:"bytes,2,opt,name=pair,proto3" json:"pair,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeOrderBookRequest) Reset() {
+	*x = SubscribeOrderBookRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeOrderBookRequest) ProtoMessage() {}
+
+func (x *SubscribeOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubscribeOrderBookRequest) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *SubscribeOrderBookRequest) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+type OrderBookUpdate struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Update:
+	//
+	//	*OrderBookUpdate_Snapshot
+	//	*OrderBookUpdate_Delta
+	Update        isOrderBookUpdate_Update `protobuf_oneof:"update"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBookUpdate) Reset() {
+	*x = OrderBookUpdate{}
+	mi := &file_api_order_v1_order_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBookUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBookUpdate) ProtoMessage() {}
+
+func (x *OrderBookUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBookUpdate.ProtoReflect.Descriptor instead.
+func (*OrderBookUpdate) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OrderBookUpdate) GetUpdate() isOrderBookUpdate_Update {
+	if x != nil {
+		return x.Update
+	}
+	return nil
+}
+
+func (x *OrderBookUpdate) GetSnapshot() *OrderBook {
+	if x != nil {
+		if x, ok := x.Update.(*OrderBookUpdate_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *OrderBookUpdate) GetDelta() *OrderBookDelta {
+	if x != nil {
+		if x, ok := x.Update.(*OrderBookUpdate_Delta); ok {
+			return x.Delta
+		}
+	}
+	return nil
+}
+
+type isOrderBookUpdate_Update interface {
+	isOrderBookUpdate_Update()
+}
+
+type OrderBookUpdate_Snapshot struct {
+	Snapshot *OrderBook `protobuf:"bytes,1,opt,name=snapshot,proto3,oneof"`
+}
+
+type OrderBookUpdate_Delta struct {
+	Delta *OrderBookDelta `protobuf:"bytes,2,opt,name=delta,proto3,oneof"`
+}
+
+func (*OrderBookUpdate_Snapshot) isOrderBookUpdate_Update() {}
+
+func (*OrderBookUpdate_Delta) isOrderBookUpdate_Update() {}
+
+type SubscribeOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientName    string                 `protobuf:"bytes,1,opt,name=client_name,json=clientName,proto3" json:"client_name,omitempty"`
+	ExchangeName  string                 `protobuf:"bytes,2,opt,name=exchange_name,json=exchangeName,proto3" json:"exchange_name,omitempty"`
+	Label         string                 `protobuf:"bytes,3,opt,name=label,proto3" json:"label,omitempty"`
+	Pair          string                 `protobuf:"bytes,4,opt,name=pair,proto3" json:"pair,omitempty"`
+	LastEventId   string                 `protobuf:"bytes,5,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeOrdersRequest) Reset() {
+	*x = SubscribeOrdersRequest{}
+	mi := &file_api_order_v1_order_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeOrdersRequest) ProtoMessage() {}
+
+func (x *SubscribeOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeOrdersRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SubscribeOrdersRequest) GetClientName() string {
+	if x != nil {
+		return x.ClientName
+	}
+	return ""
+}
+
+func (x *SubscribeOrdersRequest) GetExchangeName() string {
+	if x != nil {
+		return x.ExchangeName
+	}
+	return ""
+}
+
+func (x *SubscribeOrdersRequest) GetLabel() string {
+	if x != nil {
+		return x.Label
+	}
+	return ""
+}
+
+func (x *SubscribeOrdersRequest) GetPair() string {
+	if x != nil {
+		return x.Pair
+	}
+	return ""
+}
+
+func (x *SubscribeOrdersRequest) GetLastEventId() string {
+	if x != nil {
+		return x.LastEventId
+	}
+	return ""
+}
+
+type OrderEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Order         *HistoryOrder          `protobuf:"bytes,2,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_api_order_v1_order_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_order_v1_order_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_api_order_v1_order_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *OrderEvent) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOrder() *HistoryOrder {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+var File_api_order_v1_order_proto protoreflect.FileDescriptor
+
+const file_api_order_v1_order_proto_rawDesc = "" +
+	"\n" +
+	"\x18api/order/v1/order.proto\x12\border.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"8\n" +
+	"\x05Level\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\tR\x05price\x12\x19\n" +
+	"\bbase_qty\x18\x02 \x01(\tR\abaseQty\"\xb3\x02\n" +
+	"\tOrderBook\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bexchange\x18\x02 \x01(\tR\bexchange\x12\x12\n" +
+	"\x04pair\x18\x03 \x01(\tR\x04pair\x12#\n" +
+	"\x04asks\x18\x04 \x03(\v2\x0f.order.v1.LevelR\x04asks\x12#\n" +
+	"\x04bids\x18\x05 \x03(\v2\x0f.order.v1.LevelR\x04bids\x12?\n" +
+	"\rexchange_time\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\fexchangeTime\x12?\n" +
+	"\rreceived_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\freceivedTime\x12\x1a\n" +
+	"\bsequence\x18\b \x01(\x03R\bsequence\"\xe7\x01\n" +
+	"\x0eOrderBookDelta\x12\x1a\n" +
+	"\bexchange\x18\x01 \x01(\tR\bexchange\x12\x12\n" +
+	"\x04pair\x18\x02 \x01(\tR\x04pair\x12\x1a\n" +
+	"\bsequence\x18\x03 \x01(\x03R\bsequence\x12?\n" +
+	"\rexchange_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\fexchangeTime\x12#\n" +
+	"\x04asks\x18\x05 \x03(\v2\x0f.order.v1.LevelR\x04asks\x12#\n" +
+	"\x04bids\x18\x06 \x03(\v2\x0f.order.v1.LevelR\x04bids\"x\n" +
+	"\x06Client\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\x12#\n" +
+	"\rexchange_name\x18\x02 \x01(\tR\fexchangeName\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12\x12\n" +
+	"\x04pair\x18\x04 \x01(\tR\x04pair\"\xe0\x04\n" +
+	"\fHistoryOrder\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12&\n" +
+	"\x0fclient_order_id\x18\x02 \x01(\tR\rclientOrderId\x12\x1f\n" +
+	"\vclient_name\x18\x03 \x01(\tR\n" +
+	"clientName\x12#\n" +
+	"\rexchange_name\x18\x04 \x01(\tR\fexchangeName\x12\x14\n" +
+	"\x05label\x18\x05 \x01(\tR\x05label\x12\x12\n" +
+	"\x04pair\x18\x06 \x01(\tR\x04pair\x12\x12\n" +
+	"\x04side\x18\a \x01(\tR\x04side\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x12\x19\n" +
+	"\bbase_qty\x18\t \x01(\tR\abaseQty\x12\x14\n" +
+	"\x05price\x18\n" +
+	" \x01(\tR\x05price\x122\n" +
+	"\x15algorithm_name_placed\x18\v \x01(\tR\x13algorithmNamePlaced\x12&\n" +
+	"\x0flowest_sell_prc\x18\f \x01(\tR\rlowestSellPrc\x12&\n" +
+	"\x0fhighest_buy_prc\x18\r \x01(\tR\rhighestBuyPrc\x120\n" +
+	"\x14commission_quote_qty\x18\x0e \x01(\tR\x12commissionQuoteQty\x12;\n" +
+	"\vtime_placed\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"timePlaced\x12\x16\n" +
+	"\x06status\x18\x10 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"updated_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"x\n" +
+	"\x13GetOrderBookRequest\x12#\n" +
+	"\rexchange_name\x18\x01 \x01(\tR\fexchangeName\x12\x12\n" +
+	"\x04pair\x18\x02 \x01(\tR\x04pair\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\x12\x12\n" +
+	"\x04tick\x18\x04 \x01(\tR\x04tick\"L\n" +
+	"\x14GetOrderBookResponse\x124\n" +
+	"\vorder_books\x18\x01 \x03(\v2\x13.order.v1.OrderBookR\n" +
+	"orderBooks\"\x85\x01\n" +
+	"\x19GetLatestOrderBookRequest\x12#\n" +
+	"\rexchange_name\x18\x01 \x01(\tR\fexchangeName\x12\x12\n" +
+	"\x04pair\x18\x02 \x01(\tR\x04pair\x12/\n" +
+	"\x05as_of\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"J\n" +
+	"\x14SaveOrderBookRequest\x122\n" +
+	"\n" +
+	"order_book\x18\x01 \x01(\v2\x13.order.v1.OrderBookR\torderBook\"\x17\n" +
+	"\x15SaveOrderBookResponse\"K\n" +
+	"\x19SaveOrderBookDeltaRequest\x12.\n" +
+	"\x05delta\x18\x01 \x01(\v2\x18.order.v1.OrderBookDeltaR\x05delta\"\x1c\n" +
+	"\x1aSaveOrderBookDeltaResponse\"\x82\x03\n" +
+	"\x16GetOrderHistoryRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\x12#\n" +
+	"\rexchange_name\x18\x02 \x01(\tR\fexchangeName\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12\x12\n" +
+	"\x04pair\x18\x04 \x01(\tR\x04pair\x12.\n" +
+	"\x04from\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x12\n" +
+	"\x04side\x18\a \x01(\tR\x04side\x12\x12\n" +
+	"\x04type\x18\b \x01(\tR\x04type\x122\n" +
+	"\x15algorithm_name_placed\x18\t \x01(\tR\x13algorithmNamePlaced\x12\x12\n" +
+	"\x04sort\x18\n" +
+	" \x01(\tR\x04sort\x12\x14\n" +
+	"\x05limit\x18\v \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06cursor\x18\f \x01(\tR\x06cursor\"j\n" +
+	"\x17GetOrderHistoryResponse\x12.\n" +
+	"\x06orders\x18\x01 \x03(\v2\x16.order.v1.HistoryOrderR\x06orders\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"j\n" +
+	"\x10SaveOrderRequest\x12(\n" +
+	"\x06client\x18\x01 \x01(\v2\x10.order.v1.ClientR\x06client\x12,\n" +
+	"\x05order\x18\x02 \x01(\v2\x16.order.v1.HistoryOrderR\x05order\"T\n" +
+	"\x19SubscribeOrderBookRequest\x12#\n" +
+	"\rexchange_name\x18\x01 \x01(\tR\fexchangeName\x12\x12\n" +
+	"\x04pair\x18\x02 \x01(\tR\x04pair\"\x80\x01\n" +
+	"\x0fOrderBookUpdate\x121\n" +
+	"\bsnapshot\x18\x01 \x01(\v2\x13.order.v1.OrderBookH\x00R\bsnapshot\x120\n" +
+	"\x05delta\x18\x02 \x01(\v2\x18.order.v1.OrderBookDeltaH\x00R\x05deltaB\b\n" +
+	"\x06update\"\xac\x01\n" +
+	"\x16SubscribeOrdersRequest\x12\x1f\n" +
+	"\vclient_name\x18\x01 \x01(\tR\n" +
+	"clientName\x12#\n" +
+	"\rexchange_name\x18\x02 \x01(\tR\fexchangeName\x12\x14\n" +
+	"\x05label\x18\x03 \x01(\tR\x05label\x12\x12\n" +
+	"\x04pair\x18\x04 \x01(\tR\x04pair\x12\"\n" +
+	"\rlast_event_id\x18\x05 \x01(\tR\vlastEventId\"J\n" +
+	"\n" +
+	"OrderEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12,\n" +
+	"\x05order\x18\x02 \x01(\v2\x16.order.v1.HistoryOrderR\x05order2\x9e\x05\n" +
+	"\fOrderService\x12M\n" +
+	"\fGetOrderBook\x12\x1d.order.v1.GetOrderBookRequest\x1a\x1e.order.v1.GetOrderBookResponse\x12N\n" +
+	"\x12GetLatestOrderBook\x12#.order.v1.GetLatestOrderBookRequest\x1a\x13.order.v1.OrderBook\x12P\n" +
+	"\rSaveOrderBook\x12\x1e.order.v1.SaveOrderBookRequest\x1a\x1f.order.v1.SaveOrderBookResponse\x12_\n" +
+	"\x12SaveOrderBookDelta\x12#.order.v1.SaveOrderBookDeltaRequest\x1a$.order.v1.SaveOrderBookDeltaResponse\x12V\n" +
+	"\x0fGetOrderHistory\x12 .order.v1.GetOrderHistoryRequest\x1a!.order.v1.GetOrderHistoryResponse\x12?\n" +
+	"\tSaveOrder\x12\x1a.order.v1.SaveOrderRequest\x1a\x16.order.v1.HistoryOrder\x12V\n" +
+	"\x12SubscribeOrderBook\x12#.order.v1.SubscribeOrderBookRequest\x1a\x19.order.v1.OrderBookUpdate0\x01\x12K\n" +
+	"\x0fSubscribeOrders\x12 .order.v1.SubscribeOrdersRequest\x1a\x14.order.v1.OrderEvent0\x01B4Z2github.com/kymaka/vortex-test/api/order/v1;orderv1b\x06proto3"
+
+var (
+	file_api_order_v1_order_proto_rawDescOnce sync.Once
+	file_api_order_v1_order_proto_rawDescData []byte
+)
+
+func file_api_order_v1_order_proto_rawDescGZIP() []byte {
+	file_api_order_v1_order_proto_rawDescOnce.Do(func() {
+		file_api_order_v1_order_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_order_v1_order_proto_rawDesc), len(file_api_order_v1_order_proto_rawDesc)))
+	})
+	return file_api_order_v1_order_proto_rawDescData
+}
+
+var file_api_order_v1_order_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_api_order_v1_order_proto_goTypes = []any{
+	(*Level)(nil),                      // 0: order.v1.Level
+	(*OrderBook)(nil),                  // 1: order.v1.OrderBook
+	(*OrderBookDelta)(nil),             // 2: order.v1.OrderBookDelta
+	(*Client)(nil),                     // 3: order.v1.Client
+	(*HistoryOrder)(nil),               // 4: order.v1.HistoryOrder
+	(*GetOrderBookRequest)(nil),        // 5: order.v1.GetOrderBookRequest
+	(*GetOrderBookResponse)(nil),       // 6: order.v1.GetOrderBookResponse
+	(*GetLatestOrderBookRequest)(nil),  // 7: order.v1.GetLatestOrderBookRequest
+	(*SaveOrderBookRequest)(nil),       // 8: order.v1.SaveOrderBookRequest
+	(*SaveOrderBookResponse)(nil),      // 9: order.v1.SaveOrderBookResponse
+	(*SaveOrderBookDeltaRequest)(nil),  // 10: order.v1.SaveOrderBookDeltaRequest
+	(*SaveOrderBookDeltaResponse)(nil), // 11: order.v1.SaveOrderBookDeltaResponse
+	(*GetOrderHistoryRequest)(nil),     // 12: order.v1.GetOrderHistoryRequest
+	(*GetOrderHistoryResponse)(nil),    // 13: order.v1.GetOrderHistoryResponse
+	(*SaveOrderRequest)(nil),           // 14: order.v1.SaveOrderRequest
+	(*SubscribeOrderBookRequest)(nil),  // 15: order.v1.SubscribeOrderBookRequest
+	(*OrderBookUpdate)(nil),            // 16: order.v1.OrderBookUpdate
+	(*SubscribeOrdersRequest)(nil),     // 17: order.v1.SubscribeOrdersRequest
+	(*OrderEvent)(nil),                 // 18: order.v1.OrderEvent
+	(*timestamppb.Timestamp)(nil),      // 19: google.protobuf.Timestamp
+}
+var file_api_order_v1_order_proto_depIdxs = []int32{
+	0,  // 0: order.v1.OrderBook.asks:type_name -> order.v1.Level
+	0,  // 1: order.v1.OrderBook.bids:type_name -> order.v1.Level
+	19, // 2: order.v1.OrderBook.exchange_time:type_name -> google.protobuf.Timestamp
+	19, // 3: order.v1.OrderBook.received_time:type_name -> google.protobuf.Timestamp
+	19, // 4: order.v1.OrderBookDelta.exchange_time:type_name -> google.protobuf.Timestamp
+	0,  // 5: order.v1.OrderBookDelta.asks:type_name -> order.v1.Level
+	0,  // 6: order.v1.OrderBookDelta.bids:type_name -> order.v1.Level
+	19, // 7: order.v1.HistoryOrder.time_placed:type_name -> google.protobuf.Timestamp
+	19, // 8: order.v1.HistoryOrder.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 9: order.v1.GetOrderBookResponse.order_books:type_name -> order.v1.OrderBook
+	19, // 10: order.v1.GetLatestOrderBookRequest.as_of:type_name -> google.protobuf.Timestamp
+	1,  // 11: order.v1.SaveOrderBookRequest.order_book:type_name -> order.v1.OrderBook
+	2,  // 12: order.v1.SaveOrderBookDeltaRequest.delta:type_name -> order.v1.OrderBookDelta
+	19, // 13: order.v1.GetOrderHistoryRequest.from:type_name -> google.protobuf.Timestamp
+	19, // 14: order.v1.GetOrderHistoryRequest.to:type_name -> google.protobuf.Timestamp
+	4,  // 15: order.v1.GetOrderHistoryResponse.orders:type_name -> order.v1.HistoryOrder
+	3,  // 16: order.v1.SaveOrderRequest.client:type_name -> order.v1.Client
+	4,  // 17: order.v1.SaveOrderRequest.order:type_name -> order.v1.HistoryOrder
+	1,  // 18: order.v1.OrderBookUpdate.snapshot:type_name -> order.v1.OrderBook
+	2,  // 19: order.v1.OrderBookUpdate.delta:type_name -> order.v1.OrderBookDelta
+	4,  // 20: order.v1.OrderEvent.order:type_name -> order.v1.HistoryOrder
+	5,  // 21: order.v1.OrderService.GetOrderBook:input_type -> order.v1.GetOrderBookRequest
+	7,  // 22: order.v1.OrderService.GetLatestOrderBook:input_type -> order.v1.GetLatestOrderBookRequest
+	8,  // 23: order.v1.OrderService.SaveOrderBook:input_type -> order.v1.SaveOrderBookRequest
+	10, // 24: order.v1.OrderService.SaveOrderBookDelta:input_type -> order.v1.SaveOrderBookDeltaRequest
+	12, // 25: order.v1.OrderService.GetOrderHistory:input_type -> order.v1.GetOrderHistoryRequest
+	14, // 26: order.v1.OrderService.SaveOrder:input_type -> order.v1.SaveOrderRequest
+	15, // 27: order.v1.OrderService.SubscribeOrderBook:input_type -> order.v1.SubscribeOrderBookRequest
+	17, // 28: order.v1.OrderService.SubscribeOrders:input_type -> order.v1.SubscribeOrdersRequest
+	6,  // 29: order.v1.OrderService.GetOrderBook:output_type -> order.v1.GetOrderBookResponse
+	1,  // 30: order.v1.OrderService.GetLatestOrderBook:output_type -> order.v1.OrderBook
+	9,  // 31: order.v1.OrderService.SaveOrderBook:output_type -> order.v1.SaveOrderBookResponse
+	11, // 32: order.v1.OrderService.SaveOrderBookDelta:output_type -> order.v1.SaveOrderBookDeltaResponse
+	13, // 33: order.v1.OrderService.GetOrderHistory:output_type -> order.v1.GetOrderHistoryResponse
+	4,  // 34: order.v1.OrderService.SaveOrder:output_type -> order.v1.HistoryOrder
+	16, // 35: order.v1.OrderService.SubscribeOrderBook:output_type -> order.v1.OrderBookUpdate
+	18, // 36: order.v1.OrderService.SubscribeOrders:output_type -> order.v1.OrderEvent
+	29, // [29:37] is the sub-list for method output_type
+	21, // [21:29] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_api_order_v1_order_proto_init() }
+func file_api_order_v1_order_proto_init() {
+	if File_api_order_v1_order_proto != nil {
+		return
+	}
+	file_api_order_v1_order_proto_msgTypes[16].OneofWrappers = []any{
+		(*OrderBookUpdate_Snapshot)(nil),
+		(*OrderBookUpdate_Delta)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_order_v1_order_proto_rawDesc), len(file_api_order_v1_order_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   19,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_api_order_v1_order_proto_goTypes,
+		DependencyIndexes: file_api_order_v1_order_proto_depIdxs,
+		MessageInfos:      file_api_order_v1_order_proto_msgTypes,
+	}.Build()
+	File_api_order_v1_order_proto = out.File
+	file_api_order_v1_order_proto_goTypes = nil
+	file_api_order_v1_order_proto_depIdxs = nil
+}
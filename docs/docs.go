@@ -15,40 +15,71 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/order/book": {
+        "/algorithms": {
             "get": {
-                "description": "Returns the order books for a given exchange and pair.",
+                "description": "Returns every algorithm that placed orders, with the number of orders it placed and when, ordered by name.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "algorithms"
                 ],
-                "summary": "Get order books",
+                "summary": "List algorithms",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Algorithm"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/algorithms/{name}/stats": {
+            "get": {
+                "description": "Returns order counts, base and quote volume, buy/sell ratio, commission, FIFO PnL and slippage\nof the orders an algorithm placed across all clients, optionally within a time window.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "algorithms"
+                ],
+                "summary": "Get algorithm stats",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Exchange Name",
-                        "name": "exchangeName",
-                        "in": "query",
+                        "description": "Algorithm Name",
+                        "name": "name",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Trading Pair",
-                        "name": "pair",
-                        "in": "query",
-                        "required": true
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.OrderBook"
-                            }
+                            "$ref": "#/definitions/models.AlgorithmStats"
                         }
                     },
                     "400": {
@@ -70,32 +101,70 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Saves the order book details for a given exchange and pair.",
-                "consumes": [
+            }
+        },
+        "/candles": {
+            "get": {
+                "description": "Returns OHLCV candles of the executions of a pair on an exchange, oldest first.\nExecutions are the fills of orders, aggregated into minute candles as they are saved.\nOrders without fills are left out, even if FILLED.\nWith mids set, OHLC candles of the mid price of the stored order books are returned as well.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "candles"
                 ],
-                "summary": "Save order book",
+                "summary": "Get candles",
                 "parameters": [
                     {
-                        "description": "Order Book DTO",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.OrderBookDTO"
-                        }
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "1m",
+                            "5m",
+                            "1h",
+                            "1d"
+                        ],
+                        "type": "string",
+                        "description": "Candle interval",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include mid price candles",
+                        "name": "mids",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.Candles"
                         }
                     },
                     "400": {
@@ -113,25 +182,64 @@ const docTemplate = `{
                 }
             }
         },
-        "/order/history": {
+        "/client/pnl": {
             "get": {
-                "description": "Returns the order history for a given client.",
+                "description": "Replays the orders of a client and returns realized PnL, position, average entry price,\ncommission and net PnL per exchange, label and pair, with fifo (default) or average cost matching.\nOrders with fills are replayed fill by fill, FILLED orders without fills as executed in full at their price.\nOrders with a side other than buy or sell are skipped and listed in skippedOrders.\nOpen positions are marked to the mid of the latest order book for unrealized PnL.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "pnl"
                 ],
-                "summary": "Get order history",
+                "summary": "Get client PnL",
                 "parameters": [
                     {
-                        "description": "Client",
-                        "name": "client",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Client"
-                        }
+                        "type": "string",
+                        "description": "Client Name",
+                        "name": "clientName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label, may contain * wildcards",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair, may contain * wildcards",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "fifo",
+                            "average"
+                        ],
+                        "type": "string",
+                        "default": "fifo",
+                        "description": "Matching method",
+                        "name": "method",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -140,7 +248,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/models.HistoryOrder"
+                                "$ref": "#/definitions/analytics.PnL"
                             }
                         }
                     },
@@ -163,32 +271,219 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Saves an order for a given client.",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/export": {
+            "get": {
+                "description": "Streams every order of a client matching the order history filters (dataset history),\nor every order book of an exchange and pair received between from and to (dataset books),\nread from ClickHouse as the response is written so that large exports are not held in memory.\nOrder history is not paged, limit and cursor are ignored.\nOrder book CSV exports flatten the levels into ask_N_price, ask_N_qty, bid_N_price and bid_N_qty columns\nfor the top depth levels of each side, depth is required. Parquet exports keep the levels as lists.\nDecimals are exported as strings in Parquet to keep full precision.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
                 ],
                 "tags": [
-                    "orders"
+                    "export"
                 ],
-                "summary": "Save order",
+                "summary": "Export order history or order books",
                 "parameters": [
                     {
-                        "description": "History Order Payload",
-                        "name": "payload",
-                        "in": "body",
-                        "required": true,
+                        "enum": [
+                            "history",
+                            "books"
+                        ],
+                        "type": "string",
+                        "description": "Dataset",
+                        "name": "dataset",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client Name, required for history",
+                        "name": "clientName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name, required for books",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair, required for books",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order Type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm",
+                        "name": "algorithmNamePlaced",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order by placement time",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the time range (RFC 3339 or Unix milliseconds)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the time range (RFC 3339 or Unix milliseconds)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of levels per side, required for books in CSV",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Price bucket size",
+                        "name": "tick",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.HistoryOrderPayload"
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
                         }
                     }
+                }
+            }
+        },
+        "/fees": {
+            "get": {
+                "description": "Groups the commissions of orders by UTC month (default) or day, client, exchange, pair and label,\nwith the executed notional and effective fee rate in bps per row and in total.\ncommissionQuoteQty of an order is its commission for the whole quantity, the executed share of it is charged:\nthe quantity of its fills, or the whole order if it is FILLED without fills.\nWith format csv, or an Accept header of text/csv, the rows are returned as a CSV attachment.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "fees"
+                ],
+                "summary": "Get fee report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client Name",
+                        "name": "clientName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label, may contain * wildcards",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair, may contain * wildcards",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "month"
+                        ],
+                        "type": "string",
+                        "default": "month",
+                        "description": "Period to group by",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.FeeReport"
                         }
                     },
                     "400": {
@@ -205,37 +500,1985 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Client": {
+        },
+        "/order/book": {
+            "get": {
+                "description": "Returns the order books for a given exchange and pair.\nLevels can be grouped into price buckets of size tick and truncated to the top depth levels.\nWith format csv, ndjson or parquet, the order books received between from and to are streamed\nas an attachment, the same as GET /export with dataset books.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of levels per side to return",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Price increment to group levels by",
+                        "name": "tick",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the export, RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the export, RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrderBook"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves an order book snapshot for a given exchange and pair.\nExchangeTime and Sequence identify the exchange update, ReceivedTime defaults to the time of saving.\nAsks must be sorted ascending and bids descending, with unique finite positive prices and quantities\nand the best bid below the best ask.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Save order book",
+                "parameters": [
+                    {
+                        "description": "Order Book DTO",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderBookDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/service.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/batch": {
+            "post": {
+                "description": "Saves a JSON array of order book snapshots, or an NDJSON stream of them with Content-Type application/x-ndjson,\nin a single insert. Every order book is validated as by POST /order/book,\ninvalid ones are rejected with their violations and the others are saved.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Save order books in bulk",
+                "parameters": [
+                    {
+                        "description": "Order Book DTOs",
+                        "name": "orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.OrderBookDTO"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.BatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/consolidated": {
+            "get": {
+                "description": "Merges the latest order book of every exchange trading the pair, keeping per-level venue quantities.\nIncludes the best bid and offer of each venue and cross-venue arbitrage\nwhere one venue's bid is above another venue's ask.\nExchanges whose latest snapshot is older than maxAge are left out and listed as excluded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get consolidated order book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "30s",
+                        "description": "Maximum snapshot age as a Go duration",
+                        "name": "maxAge",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.ConsolidatedOrderBook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/delta": {
+            "post": {
+                "description": "Applies price level updates to the order book of a given exchange and pair.\nLevels with zero BaseQty are removed. Sequence must directly follow the last applied update,\nafter a gap a new snapshot has to be saved before further deltas are accepted.\nDeltas leaving the book crossed, with non-positive levels or more precise than the pair are rejected.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Save order book delta",
+                "parameters": [
+                    {
+                        "description": "Order Book Delta",
+                        "name": "delta",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderBookDelta"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/service.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/impact": {
+            "get": {
+                "description": "Walks the latest order book to fill baseQty and returns the VWAP fill price, worst price,\nslippage versus mid in bps and whether the book has insufficient liquidity for the size.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Estimate market impact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "buy",
+                            "sell"
+                        ],
+                        "type": "string",
+                        "description": "Order side",
+                        "name": "side",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Order size in base asset",
+                        "name": "baseQty",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.MarketImpact"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/latest": {
+            "get": {
+                "description": "Returns the most recent order book for a given exchange and pair.\nIf asOf is set, returns the order book in effect at that instant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get latest order book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "asOf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderBookDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/book/metrics": {
+            "get": {
+                "description": "Returns best bid and ask, mid, spread, microprice, top levels imbalance\nand cumulative depth within bands around the mid for the latest order book,\nor the order book in effect at asOf.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order book metrics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "asOf",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 5,
+                        "description": "Number of top levels used for imbalance",
+                        "name": "levels",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "10,25,50,100",
+                        "description": "Comma separated depth bands in bps",
+                        "name": "bands",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.OrderBookMetrics"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/execution-quality": {
+            "get": {
+                "description": "Compares the orders of a client with the best bid and ask captured at their placement.\nReturns per order the arrival mid, slippage in bps (negative is price improvement), whether the order crossed the spread\nand fee drag in bps, aggregated by the algorithm that placed them weighted by notional.\nOrders with fills are evaluated at their average fill price with the commission prorated by the filled quantity,\nFILLED orders without fills at their price, other orders without fills are skipped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Get execution quality",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client Name",
+                        "name": "clientName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label, may contain * wildcards",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair, may contain * wildcards",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order Type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm Name",
+                        "name": "algorithmNamePlaced",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.ExecutionQualityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/fills": {
+            "post": {
+                "description": "Saves a batch of fills, each referencing a stored order.\nFills already stored under the same fillId and orderId are ignored, so a batch can be sent again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Save fills",
+                "parameters": [
+                    {
+                        "description": "Fills",
+                        "name": "fills",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Fill"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/service.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/history": {
+            "get": {
+                "description": "Returns the order history for a given client, with a summary of the fills of every order.\nEmpty filters match every order, label and pair may contain * wildcards.\nOrders placed at or after from and before to are sorted by placement time, asc (default) or desc.\nWithout limit and cursor, every matching order is returned as a JSON array.\nWith limit or cursor, a models.OrderHistoryPage is returned instead,\npass nextCursor of a page as cursor to get the next one, the last page has no nextCursor.\nA JSON filter in the request body is still accepted without query parameters, but deprecated\nin favour of POST /order/history/search and answered with a Deprecation header.\nWith format csv, ndjson or parquet, every matching order is streamed as an attachment without paging,\nthe same as GET /export with dataset history.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get order history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client Name",
+                        "name": "clientName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label, may contain * wildcards",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair, may contain * wildcards",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 timestamp or Unix milliseconds",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Side",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Order Type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Algorithm Name",
+                        "name": "algorithmNamePlaced",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Orders per page, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HistoryOrder"
+                            }
+                        },
+                        "headers": {
+                            "Deprecation": {
+                                "type": "string",
+                                "description": "true if the filter was read from the request body"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves an order for a given client and returns it.\nOrders without an orderId get a generated one, orders without a status are NEW.\nAn orderId that already exists is rejected, the status of stored orders is changed through /order/history/{id}/status.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Save order",
+                "parameters": [
+                    {
+                        "description": "History Order Payload",
+                        "name": "payload",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryOrderPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/service.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/history/batch": {
+            "post": {
+                "description": "Saves a JSON array of order history payloads, or an NDJSON stream of them with Content-Type application/x-ndjson,\nin a single insert. Every order is validated as by POST /order/history,\ninvalid ones are rejected with their violations and the others are saved with their order ID reported.",
+                "consumes": [
+                    "application/json",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "history"
+                ],
+                "summary": "Save order history in bulk",
+                "parameters": [
+                    {
+                        "description": "History Order Payloads",
+                        "name": "orders",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.HistoryOrderPayload"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.BatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/history/search": {
+            "post": {
+                "description": "Returns a page of the order history matching the filter, with a summary of the fills of every order.\nEmpty filter fields match every order, label and pair may contain * wildcards.\nOrders placed at or after from and before to are sorted by placement time, asc (default) or desc.\nPass nextCursor of a page as cursor to get the next one, the last page has no nextCursor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Search order history",
+                "parameters": [
+                    {
+                        "description": "Order History Filter",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderHistoryFilter"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderHistoryPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/history/stream": {
+            "get": {
+                "description": "Streams each order state saved for the client, exchange, label and pair, by SaveOrder or a status update,\nas an \"order\" Server-Sent Event with the HistoryOrder as data. Empty filter fields match any order.\nReconnecting with the Last-Event-ID header, or the lastEventId parameter, replays the states stored\nafter that event, oldest first, including states not yet written to ClickHouse, then continues with the live ones.\nStates replaced by a later state of their order when ClickHouse merges parts are not replayed, the later state is.\nComments are sent as heartbeats while no orders are saved.\nStreams that do not keep up, or whose replay fails, are ended with an \"error\" event and should reconnect,\nas should streams ended when the server shuts down.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Stream order history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client Name",
+                        "name": "clientName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Label",
+                        "name": "label",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/order/history/{id}/status": {
+            "post": {
+                "description": "Records a status transition of an order and returns its new state.\nNEW orders may become PARTIALLY_FILLED, FILLED, CANCELLED or REJECTED,\nPARTIALLY_FILLED orders may become PARTIALLY_FILLED, FILLED or CANCELLED. Other statuses are final.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Status Update",
+                        "name": "update",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderStatusUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.HistoryOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/service.ValidationError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/pair/precision": {
+            "get": {
+                "description": "Returns the number of decimal places prices and quantities of a pair are quoted with on an exchange.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pairs"
+                ],
+                "summary": "Get pair precision",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Exchange Name",
+                        "name": "exchangeName",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Trading Pair",
+                        "name": "pair",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PairPrecision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves the number of decimal places prices and quantities of a pair are quoted with on an exchange.\nOrder books and history orders with more decimal places are rejected. Scales range from 0 to 18.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "pairs"
+                ],
+                "summary": "Save pair precision",
+                "parameters": [
+                    {
+                        "description": "Pair Precision",
+                        "name": "precision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PairPrecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws/order/book": {
+            "get": {
+                "description": "Upgrades to a WebSocket connection streaming the snapshots and deltas of subscribed exchanges and pairs.\nSubscribe with {\"action\":\"subscribe\",\"exchange\":\"...\",\"pair\":\"...\"} and unsubscribe with action unsubscribe,\nor pass subscribe=exchange:pair query parameters to resubscribe when reconnecting.\nEach subscription starts with the current snapshot of the book, followed by updates of type snapshot or delta.\nConnections that do not keep up with updates are closed with status 1008, and with status 1001 when the server shuts down.\nBrowser connections are only accepted from the server's origin and the origins allowed by WS_ALLOWED_ORIGINS.",
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Stream order book updates",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Exchange and pair to subscribe to as exchange:pair",
+                        "name": "subscribe",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/models.OrderBookUpdate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "analytics.AlgorithmExecution": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "crossedSpreadRate": {
+                    "type": "number"
+                },
+                "feeDragBps": {
+                    "type": "number"
+                },
+                "notional": {
+                    "type": "number"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "slippageBps": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.Arbitrage": {
+            "type": "object",
+            "properties": {
+                "askPrice": {
+                    "type": "number"
+                },
+                "baseQty": {
+                    "type": "number"
+                },
+                "bidPrice": {
+                    "type": "number"
+                },
+                "buyExchange": {
+                    "type": "string"
+                },
+                "sellExchange": {
+                    "type": "string"
+                },
+                "spreadBps": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.ConsolidatedLevel": {
+            "type": "object",
+            "properties": {
+                "baseQty": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "venues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.VenueQty"
+                    }
+                }
+            }
+        },
+        "analytics.ConsolidatedOrderBook": {
+            "type": "object",
+            "properties": {
+                "arbitrage": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.Arbitrage"
+                    }
+                },
+                "asks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.ConsolidatedLevel"
+                    }
+                },
+                "bestAsk": {
+                    "$ref": "#/definitions/analytics.Quote"
+                },
+                "bestBid": {
+                    "$ref": "#/definitions/analytics.Quote"
+                },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.ConsolidatedLevel"
+                    }
+                },
+                "crossed": {
+                    "type": "boolean"
+                },
+                "exchanges": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "excluded": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.StaleVenue"
+                    }
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "venues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.VenueQuote"
+                    }
+                }
+            }
+        },
+        "analytics.DepthBand": {
+            "type": "object",
+            "properties": {
+                "askQty": {
+                    "type": "number"
+                },
+                "askQuoteQty": {
+                    "type": "number"
+                },
+                "bidQty": {
+                    "type": "number"
+                },
+                "bidQuoteQty": {
+                    "type": "number"
+                },
+                "bps": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.ExecutionQualityReport": {
+            "type": "object",
+            "properties": {
+                "algorithms": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.AlgorithmExecution"
+                    }
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.OrderExecution"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "analytics.MarketImpact": {
+            "type": "object",
+            "properties": {
+                "baseQty": {
+                    "type": "number"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "filledQty": {
+                    "type": "number"
+                },
+                "insufficientLiquidity": {
+                    "type": "boolean"
+                },
+                "levelsConsumed": {
+                    "type": "integer"
+                },
+                "mid": {
+                    "type": "number"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "quoteQty": {
+                    "type": "number"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "slippageBps": {
+                    "type": "number"
+                },
+                "vwapPrice": {
+                    "type": "number"
+                },
+                "worstPrice": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.OrderBookMetrics": {
+            "type": "object",
+            "properties": {
+                "bestAsk": {
+                    "type": "number"
+                },
+                "bestAskQty": {
+                    "type": "number"
+                },
+                "bestBid": {
+                    "type": "number"
+                },
+                "bestBidQty": {
+                    "type": "number"
+                },
+                "depth": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.DepthBand"
+                    }
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "exchangeTime": {
+                    "type": "string"
+                },
+                "imbalance": {
+                    "type": "number"
+                },
+                "levels": {
+                    "type": "integer"
+                },
+                "microprice": {
+                    "type": "number"
+                },
+                "mid": {
+                    "type": "number"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "spread": {
+                    "type": "number"
+                },
+                "spreadBps": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.OrderExecution": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "arrivalMid": {
+                    "type": "number"
+                },
+                "commission": {
+                    "type": "number"
+                },
+                "crossedSpread": {
+                    "type": "boolean"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "executedQty": {
+                    "type": "number"
+                },
+                "executionPrice": {
+                    "type": "number"
+                },
+                "feeDragBps": {
+                    "type": "number"
+                },
+                "notional": {
+                    "type": "number"
+                },
+                "orderId": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "slippageBps": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.PnL": {
+            "type": "object",
+            "properties": {
+                "avgEntryPrice": {
+                    "type": "number"
+                },
+                "commission": {
+                    "type": "number"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "markPrice": {
+                    "type": "number"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "netPnl": {
+                    "type": "number"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "number"
+                },
+                "realizedPnl": {
+                    "type": "number"
+                },
+                "skippedOrders": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "trades": {
+                    "type": "integer"
+                },
+                "unrealizedPnl": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.Quote": {
+            "type": "object",
+            "properties": {
+                "baseQty": {
+                    "type": "number"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "analytics.StaleVenue": {
+            "type": "object",
+            "properties": {
+                "ageMillis": {
+                    "type": "integer"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "exchangeTime": {
+                    "type": "string"
+                }
+            }
+        },
+        "analytics.VenueQty": {
+            "type": "object",
+            "properties": {
+                "baseQty": {
+                    "type": "number"
+                },
+                "exchange": {
+                    "type": "string"
+                }
+            }
+        },
+        "analytics.VenueQuote": {
+            "type": "object",
+            "properties": {
+                "ask": {
+                    "$ref": "#/definitions/analytics.Quote"
+                },
+                "bid": {
+                    "$ref": "#/definitions/analytics.Quote"
+                },
+                "exchange": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Algorithm": {
+            "type": "object",
+            "properties": {
+                "firstPlaced": {
+                    "type": "string"
+                },
+                "lastPlaced": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AlgorithmStats": {
+            "type": "object",
+            "properties": {
+                "algorithm": {
+                    "type": "string"
+                },
+                "baseVolume": {
+                    "type": "number"
+                },
+                "buyOrders": {
+                    "type": "integer"
+                },
+                "buySellRatio": {
+                    "type": "number"
+                },
+                "cancelledOrders": {
+                    "type": "integer"
+                },
+                "commission": {
+                    "type": "number"
+                },
+                "crossedSpreadRate": {
+                    "type": "number"
+                },
+                "evaluatedOrders": {
+                    "type": "integer"
+                },
+                "feeDragBps": {
+                    "type": "number"
+                },
+                "filledOrders": {
+                    "type": "integer"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "netPnl": {
+                    "type": "number"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "quoteVolume": {
+                    "type": "number"
+                },
+                "realizedPnl": {
+                    "type": "number"
+                },
+                "rejectedOrders": {
+                    "type": "integer"
+                },
+                "sellOrders": {
+                    "type": "integer"
+                },
+                "slippageBps": {
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "unrealizedPnl": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Candle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "high": {
+                    "type": "number"
+                },
+                "low": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "quoteVolume": {
+                    "type": "number"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "trades": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Candles": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "mids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MidCandle"
+                    }
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "trades": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Candle"
+                    }
+                }
+            }
+        },
+        "models.Client": {
+            "type": "object",
+            "properties": {
+                "clientName": {
+                    "type": "string"
+                },
+                "exchangeName": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DepthOrder": {
+            "type": "object",
+            "properties": {
+                "baseQty": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.FeeReport": {
+            "type": "object",
+            "properties": {
+                "commission": {
+                    "type": "number"
+                },
+                "feeRateBps": {
+                    "type": "number"
+                },
+                "notional": {
+                    "type": "number"
+                },
+                "orders": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FeeReportRow"
+                    }
+                }
+            }
+        },
+        "models.FeeReportRow": {
             "type": "object",
             "properties": {
                 "clientName": {
                     "type": "string"
                 },
+                "commission": {
+                    "type": "number"
+                },
                 "exchangeName": {
                     "type": "string"
                 },
+                "feeRateBps": {
+                    "type": "number"
+                },
                 "label": {
                     "type": "string"
                 },
+                "notional": {
+                    "type": "number"
+                },
+                "orders": {
+                    "type": "integer"
+                },
                 "pair": {
                     "type": "string"
+                },
+                "periodStart": {
+                    "type": "string"
                 }
             }
         },
-        "models.DepthOrder": {
+        "models.Fill": {
             "type": "object",
             "properties": {
                 "baseQty": {
                     "type": "number"
                 },
+                "executedAt": {
+                    "type": "string"
+                },
+                "fee": {
+                    "type": "number"
+                },
+                "feeAsset": {
+                    "type": "string"
+                },
+                "fillId": {
+                    "type": "string"
+                },
+                "liquidity": {
+                    "type": "string"
+                },
+                "orderId": {
+                    "type": "string"
+                },
                 "price": {
                     "type": "number"
                 }
             }
         },
+        "models.FillSummary": {
+            "type": "object",
+            "properties": {
+                "avgFillPrice": {
+                    "type": "number"
+                },
+                "fillCount": {
+                    "type": "integer"
+                },
+                "filledQty": {
+                    "type": "number"
+                },
+                "lastFillAt": {
+                    "type": "string"
+                },
+                "quoteQty": {
+                    "type": "number"
+                },
+                "totalFees": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                }
+            }
+        },
         "models.HistoryOrder": {
             "type": "object",
             "properties": {
@@ -248,12 +2491,18 @@ const docTemplate = `{
                 "clientName": {
                     "type": "string"
                 },
+                "clientOrderId": {
+                    "type": "string"
+                },
                 "commissionQuoteQty": {
                     "type": "number"
                 },
                 "exchangeName": {
                     "type": "string"
                 },
+                "fills": {
+                    "$ref": "#/definitions/models.FillSummary"
+                },
                 "highestBuyPrc": {
                     "type": "number"
                 },
@@ -263,6 +2512,9 @@ const docTemplate = `{
                 "lowestSellPrc": {
                     "type": "number"
                 },
+                "orderId": {
+                    "type": "string"
+                },
                 "pair": {
                     "type": "string"
                 },
@@ -272,11 +2524,17 @@ const docTemplate = `{
                 "side": {
                     "type": "string"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "timePlaced": {
                     "type": "string"
                 },
                 "type": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
@@ -291,6 +2549,29 @@ const docTemplate = `{
                 }
             }
         },
+        "models.MidCandle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "high": {
+                    "type": "number"
+                },
+                "low": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "snapshots": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "models.OrderBook": {
             "type": "object",
             "properties": {
@@ -315,11 +2596,20 @@ const docTemplate = `{
                 "exchange": {
                     "type": "string"
                 },
+                "exchangeTime": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "pair": {
                     "type": "string"
+                },
+                "receivedTime": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
                 }
             }
         },
@@ -341,11 +2631,214 @@ const docTemplate = `{
                 "exchange": {
                     "type": "string"
                 },
+                "exchangeTime": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "pair": {
                     "type": "string"
+                },
+                "receivedTime": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderBookDelta": {
+            "type": "object",
+            "properties": {
+                "asks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DepthOrder"
+                    }
+                },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DepthOrder"
+                    }
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "exchangeTime": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "sequence": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.OrderBookUpdate": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/models.OrderBookDTO"
+                },
+                "delta": {
+                    "$ref": "#/definitions/models.OrderBookDelta"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderHistoryFilter": {
+            "type": "object",
+            "properties": {
+                "algorithmNamePlaced": {
+                    "type": "string"
+                },
+                "clientName": {
+                    "type": "string"
+                },
+                "cursor": {
+                    "type": "string"
+                },
+                "exchangeName": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "side": {
+                    "type": "string"
+                },
+                "sort": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.OrderHistoryPage": {
+            "type": "object",
+            "properties": {
+                "nextCursor": {
+                    "type": "string"
+                },
+                "orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HistoryOrder"
+                    }
+                }
+            }
+        },
+        "models.OrderStatusUpdate": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PairPrecision": {
+            "type": "object",
+            "properties": {
+                "exchange": {
+                    "type": "string"
+                },
+                "pair": {
+                    "type": "string"
+                },
+                "priceScale": {
+                    "type": "integer"
+                },
+                "qtyScale": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.BatchItemResult": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "boolean"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "orderId": {
+                    "type": "string"
+                },
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Violation"
+                    }
+                }
+            }
+        },
+        "service.BatchResult": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.BatchItemResult"
+                    }
+                },
+                "rejected": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ValidationError": {
+            "type": "object",
+            "properties": {
+                "violations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Violation"
+                    }
+                }
+            }
+        },
+        "service.Violation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
                 }
             }
         }
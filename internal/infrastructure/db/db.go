@@ -1,7 +1,10 @@
 package db
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
+	"time"
 
 	"fmt"
 
@@ -51,34 +54,540 @@ func Migrate(db *gorm.DB) error {
 				id Int64,
 				exchange String,
 				pair String,
-				asks String,
-				bids String
+				asks Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18))),
+				bids Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18))),
+				exchange_time DateTime64(6, 'UTC'),
+				received_time DateTime64(6, 'UTC'),
+				sequence Int64
 			) ENGINE = MergeTree()
 			PRIMARY KEY (exchange, pair)
 			ORDER BY (exchange, pair);`).Error; err != nil {
 		return err
 	}
 
+	if err := migrateOrderBookLevels(db); err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			ALTER TABLE order_books
+				ADD COLUMN IF NOT EXISTS exchange_time DateTime64(6, 'UTC'),
+				ADD COLUMN IF NOT EXISTS received_time DateTime64(6, 'UTC'),
+				ADD COLUMN IF NOT EXISTS sequence Int64;`).Error; err != nil {
+		return err
+	}
+
 	if err := db.Exec(`
 			CREATE TABLE IF NOT EXISTS history_orders (
+				order_id String,
+				client_order_id String,
 				client_name String,
 				exchange_name String,
 				label String,
 				pair String,
 				side String,
 				type String,
-				base_qty Float64,
-				price Float64,
+				base_qty Decimal(38, 18),
+				price Decimal(38, 18),
 				algorithm_name_placed String,
-				lowest_sell_prc Float64,
-				highest_buy_prc Float64,
-				commission_quote_qty Float64,
-				time_placed DateTime
-			) ENGINE = MergeTree()
+				lowest_sell_prc Decimal(38, 18),
+				highest_buy_prc Decimal(38, 18),
+				commission_quote_qty Decimal(38, 18),
+				time_placed DateTime,
+				status String,
+				updated_at DateTime64(6, 'UTC')
+			) ENGINE = ReplacingMergeTree(updated_at)
 			PRIMARY KEY (client_name, exchange_name, pair)
-			ORDER BY (client_name, exchange_name, pair);`).Error; err != nil {
+			ORDER BY (client_name, exchange_name, pair, order_id);`).Error; err != nil {
+		return err
+	}
+
+	if err := migrateDecimalColumns(db); err != nil {
+		return err
+	}
+
+	if err := migrateHistoryOrderIDs(db); err != nil {
+		return err
+	}
+
+	// States stored before ingest times were recorded are ordered by their update time on the order history stream
+	if err := db.Exec(`
+			ALTER TABLE history_orders
+				ADD COLUMN IF NOT EXISTS ingested_at DateTime64(6, 'UTC') DEFAULT updated_at;`).Error; err != nil {
+		return err
+	}
+
+	// Orders are looked up by ID, which is not a leading key of the table, without reading every granule
+	if err := migrateSkipIndex(db, "history_orders", "order_id_bloom", "order_id TYPE bloom_filter GRANULARITY 1"); err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS fills (
+				fill_id String,
+				order_id String,
+				exchange_name String,
+				pair String,
+				base_qty Decimal(38, 18),
+				price Decimal(38, 18),
+				fee Decimal(38, 18),
+				fee_asset String,
+				liquidity String,
+				executed_at DateTime64(6, 'UTC')
+			) ENGINE = ReplacingMergeTree()
+			PRIMARY KEY (order_id, fill_id)
+			ORDER BY (order_id, fill_id);`).Error; err != nil {
+		return err
+	}
+
+	if err := migrateFillPairs(db); err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS pair_precisions (
+				exchange String,
+				pair String,
+				price_scale Int32,
+				qty_scale Int32,
+				updated_at DateTime64(6, 'UTC')
+			) ENGINE = ReplacingMergeTree(updated_at)
+			PRIMARY KEY (exchange, pair)
+			ORDER BY (exchange, pair);`).Error; err != nil {
+		return err
+	}
+
+	return MigrateCandles(db)
+}
+
+/*
+MigrateCandles creates the minute candles of the mid price of the stored order books and of the fills,
+each kept up to date by a materialized view. Candles of longer intervals are merged from the minute candles at query time.
+*/
+func MigrateCandles(db *gorm.DB) error {
+	if err := migrateMidCandles(db); err != nil {
+		return err
+	}
+
+	return migrateTradeCandles(db)
+}
+
+/*
+migrateMidCandles creates order_book_mid_candles, one minute candles of the mid price of the stored order books.
+Snapshots are bucketed by their exchange time, or their received time if the exchange time is not set,
+and snapshots with neither are left out. The mid is taken between the highest bid and the lowest ask.
+*/
+func migrateMidCandles(db *gorm.DB) error {
+	return migrateAggregation(db, "order_book_mid_candles", "order_book_mid_candles_mv", `
+			CREATE TABLE order_book_mid_candles (
+				exchange String,
+				pair String,
+				bucket DateTime('UTC'),
+				open AggregateFunction(argMin, Decimal(38, 18), DateTime64(6, 'UTC')),
+				high AggregateFunction(max, Decimal(38, 18)),
+				low AggregateFunction(min, Decimal(38, 18)),
+				close AggregateFunction(argMax, Decimal(38, 18), DateTime64(6, 'UTC')),
+				snapshots AggregateFunction(count)
+			) ENGINE = AggregatingMergeTree()
+			PRIMARY KEY (exchange, pair)
+			ORDER BY (exchange, pair, bucket);`,
+		func(bound string, backfill bool) string {
+			return fmt.Sprintf(`
+			SELECT
+				exchange,
+				pair,
+				toStartOfMinute(snapshot_time) AS bucket,
+				argMinState(mid, snapshot_time) AS open,
+				maxState(mid) AS high,
+				minState(mid) AS low,
+				argMaxState(mid, snapshot_time) AS close,
+				countState() AS snapshots
+			FROM (
+				SELECT
+					exchange,
+					pair,
+					if(toUnixTimestamp64Micro(exchange_time) > 0, exchange_time, received_time) AS snapshot_time,
+					(arrayMax(arrayMap(l -> l.1, bids)) + arrayMin(arrayMap(l -> l.1, asks))) / 2 AS mid
+				FROM order_books
+				WHERE notEmpty(bids) AND notEmpty(asks)
+			)
+			WHERE toUnixTimestamp64Micro(snapshot_time) > 0 AND snapshot_time %s
+			GROUP BY exchange, pair, bucket`, bound)
+		})
+}
+
+/*
+migrateTradeCandles creates trade_candles, one minute OHLCV candles of the fills of a pair on an exchange.
+Only fills are aggregated, FILLED orders without fills are not. The backfill reads every stored fill once,
+new fills are only saved once by the service, which saves the fills of an order one batch at a time.
+*/
+func migrateTradeCandles(db *gorm.DB) error {
+	return migrateAggregation(db, "trade_candles", "trade_candles_mv", `
+			CREATE TABLE trade_candles (
+				exchange String,
+				pair String,
+				bucket DateTime('UTC'),
+				open AggregateFunction(argMin, Decimal(38, 18), DateTime64(6, 'UTC')),
+				high AggregateFunction(max, Decimal(38, 18)),
+				low AggregateFunction(min, Decimal(38, 18)),
+				close AggregateFunction(argMax, Decimal(38, 18), DateTime64(6, 'UTC')),
+				volume AggregateFunction(sum, Decimal(38, 18)),
+				quote_volume AggregateFunction(sum, Decimal(76, 18)),
+				trades AggregateFunction(count)
+			) ENGINE = AggregatingMergeTree()
+			PRIMARY KEY (exchange, pair)
+			ORDER BY (exchange, pair, bucket);`,
+		func(bound string, backfill bool) string {
+			source := "fills"
+			if backfill {
+				source = "(SELECT * FROM fills LIMIT 1 BY order_id, fill_id)"
+			}
+
+			return fmt.Sprintf(`
+			SELECT
+				exchange_name AS exchange,
+				pair,
+				toStartOfMinute(executed_at) AS bucket,
+				argMinState(price, executed_at) AS open,
+				maxState(price) AS high,
+				minState(price) AS low,
+				argMaxState(price, executed_at) AS close,
+				sumState(base_qty) AS volume,
+				sumState(multiplyDecimal(base_qty, price, 18)) AS quote_volume,
+				countState() AS trades
+			FROM %s
+			WHERE executed_at %s
+			GROUP BY exchange, pair, bucket`, source, bound)
+		})
+}
+
+/*
+migrateAggregation creates an AggregatingMergeTree table with the create statement and the materialized view
+keeping it up to date. aggregate returns the aggregating query of the source rows whose time matches bound,
+reading every stored row once if backfill is set.
+The view is commented with a hash of the definitions, both are created again when the comment differs
+from the current definitions or either is missing, and the table is filled again from the source.
+The view aggregates rows from the time it is created, the rows before are backfilled right after,
+so every row is aggregated once. Migrations run before the service accepts writes, only rows another instance
+inserts while the view is created, with a time after it, can be missed.
+*/
+func migrateAggregation(db *gorm.DB, table, view, create string, aggregate func(bound string, backfill bool) string) error {
+	sum := sha256.Sum256([]byte(create + aggregate("", false) + aggregate("", true)))
+	version := hex.EncodeToString(sum[:])
+
+	var current uint64
+	if err := db.Raw(`
+			SELECT count() FROM system.tables
+			WHERE database = currentDatabase() AND name = ? AND comment = ?;`, view, version).
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	var tableExists uint8
+	if err := db.Raw(fmt.Sprintf(`EXISTS TABLE %s;`, table)).Scan(&tableExists).Error; err != nil {
+		return err
+	}
+
+	if current > 0 && tableExists == 1 {
+		return nil
+	}
+
+	if err := db.Exec(fmt.Sprintf(`DROP VIEW IF EXISTS %s;`, view)).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s;`, table)).Error; err != nil {
+		return err
+	}
+	if err := db.Exec(create).Error; err != nil {
+		return err
+	}
+
+	var now time.Time
+	if err := db.Raw(`SELECT now64(6, 'UTC');`).Row().Scan(&now); err != nil {
+		return err
+	}
+	cutoff := fmt.Sprintf("toDateTime64('%s', 6, 'UTC')", now.UTC().Format("2006-01-02 15:04:05.000000"))
+
+	if err := db.Exec(fmt.Sprintf(`
+			CREATE MATERIALIZED VIEW %s
+			TO %s AS%s
+			COMMENT '%s';`, view, table, aggregate(">= "+cutoff, false), version)).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf(`INSERT INTO %s%s;`, table, aggregate("< "+cutoff, true))).Error
+}
+
+/*
+migrateSkipIndex adds the data skipping index to the table unless it exists,
+and builds it for the parts stored before, as only parts written afterwards get it otherwise.
+*/
+func migrateSkipIndex(db *gorm.DB, table, index, definition string) error {
+	var exists uint64
+	if err := db.Raw(`
+			SELECT count() FROM system.data_skipping_indices
+			WHERE database = currentDatabase() AND table = ? AND name = ?;`, table, index).
+		Scan(&exists).Error; err != nil {
+		return err
+	}
+
+	if exists > 0 {
+		return nil
+	}
+
+	if err := db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD INDEX %s %s;`, table, index, definition)).Error; err != nil {
 		return err
 	}
 
+	return db.Exec(fmt.Sprintf(`ALTER TABLE %s MATERIALIZE INDEX %s;`, table, index)).Error
+}
+
+/*
+migrateFillPairs adds the exchange and pair of their order to fills created without them,
+so that fills are aggregated into candles without joining the orders.
+Rows are copied into a new table, which then replaces the old one.
+The original table is kept as fills_legacy for verification.
+*/
+func migrateFillPairs(db *gorm.DB) error {
+	var columnType string
+	if err := db.Raw(`
+			SELECT type FROM system.columns
+			WHERE database = currentDatabase() AND table = 'fills' AND name = 'exchange_name';`).
+		Scan(&columnType).Error; err != nil {
+		return err
+	}
+
+	if columnType != "" {
+		return nil
+	}
+
+	if err := db.Exec(`DROP TABLE IF EXISTS fills_with_pairs;`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			CREATE TABLE fills_with_pairs (
+				fill_id String,
+				order_id String,
+				exchange_name String,
+				pair String,
+				base_qty Decimal(38, 18),
+				price Decimal(38, 18),
+				fee Decimal(38, 18),
+				fee_asset String,
+				liquidity String,
+				executed_at DateTime64(6, 'UTC')
+			) ENGINE = ReplacingMergeTree()
+			PRIMARY KEY (order_id, fill_id)
+			ORDER BY (order_id, fill_id);`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			INSERT INTO fills_with_pairs
+			SELECT
+				f.fill_id,
+				f.order_id,
+				o.exchange_name,
+				o.pair,
+				f.base_qty,
+				f.price,
+				f.fee,
+				f.fee_asset,
+				f.liquidity,
+				f.executed_at
+			FROM fills AS f
+			LEFT JOIN (
+				SELECT order_id, any(exchange_name) AS exchange_name, any(pair) AS pair
+				FROM history_orders
+				GROUP BY order_id
+			) AS o ON f.order_id = o.order_id;`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+			RENAME TABLE
+				fills TO fills_legacy,
+				fills_with_pairs TO fills;`).Error
+}
+
+/*
+migrateOrderBookLevels converts order_books created with JSON encoded String
+asks/bids columns to native Array(Tuple(price Float64, qty Float64)) columns,
+which are then converted to decimals by migrateDecimalColumns.
+Rows are copied into a new table, which then replaces the old one.
+The original table is kept as order_books_json for verification.
+*/
+func migrateOrderBookLevels(db *gorm.DB) error {
+	var columnType string
+	if err := db.Raw(`
+			SELECT type FROM system.columns
+			WHERE database = currentDatabase() AND table = 'order_books' AND name = 'asks';`).
+		Scan(&columnType).Error; err != nil {
+		return err
+	}
+
+	if columnType != "String" {
+		return nil
+	}
+
+	if err := db.Exec(`DROP TABLE IF EXISTS order_books_native;`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			CREATE TABLE order_books_native (
+				id Int64,
+				exchange String,
+				pair String,
+				asks Array(Tuple(price Float64, qty Float64)),
+				bids Array(Tuple(price Float64, qty Float64))
+			) ENGINE = MergeTree()
+			PRIMARY KEY (exchange, pair)
+			ORDER BY (exchange, pair);`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			INSERT INTO order_books_native
+			SELECT
+				id,
+				exchange,
+				pair,
+				arrayMap(l -> (l[1], l[2]), JSONExtract(asks, 'Array(Array(Float64))')),
+				arrayMap(l -> (l[1], l[2]), JSONExtract(bids, 'Array(Array(Float64))'))
+			FROM order_books;`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+			RENAME TABLE
+				order_books TO order_books_json,
+				order_books_native TO order_books;`).Error
+}
+
+/*
+migrateDecimalColumns converts prices and quantities stored as Float64 to Decimal(38, 18).
+*/
+func migrateDecimalColumns(db *gorm.DB) error {
+	levels := []string{"asks", "bids"}
+	for _, column := range levels {
+		if err := migrateColumnType(db, "order_books", column,
+			"Array(Tuple(price Float64, qty Float64))",
+			"Array(Tuple(price String, qty String))",
+			"Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18)))"); err != nil {
+			return err
+		}
+	}
+
+	history := []string{"base_qty", "price", "lowest_sell_prc", "highest_buy_prc", "commission_quote_qty"}
+	for _, column := range history {
+		if err := migrateColumnType(db, "history_orders", column, "Float64", "String", "Decimal(38, 18)"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
+
+/*
+migrateColumnType changes the type of a column currently of fromType to toType.
+Values go through textType first, so floats are converted from their shortest text form
+and 0.1 becomes exactly 0.1 rather than its binary approximation.
+*/
+func migrateColumnType(db *gorm.DB, table, column, fromType, textType, toType string) error {
+	var columnType string
+	if err := db.Raw(`
+			SELECT type FROM system.columns
+			WHERE database = currentDatabase() AND table = ? AND name = ?;`, table, column).
+		Scan(&columnType).Error; err != nil {
+		return err
+	}
+
+	if columnType != fromType {
+		return nil
+	}
+
+	if err := db.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", table, column, textType)).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s;", table, column, toType)).Error
+}
+
+/*
+migrateHistoryOrderIDs converts history_orders created as a MergeTree without order identifiers
+to a ReplacingMergeTree keeping the latest state of every order.
+Existing orders get a generated order ID and the NEW status, as their outcome was not recorded.
+The original table is kept as history_orders_legacy for verification.
+*/
+func migrateHistoryOrderIDs(db *gorm.DB) error {
+	var engine string
+	if err := db.Raw(`
+			SELECT engine FROM system.tables
+			WHERE database = currentDatabase() AND name = 'history_orders';`).
+		Scan(&engine).Error; err != nil {
+		return err
+	}
+
+	if engine != "MergeTree" {
+		return nil
+	}
+
+	if err := db.Exec(`DROP TABLE IF EXISTS history_orders_replacing;`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			CREATE TABLE history_orders_replacing (
+				order_id String,
+				client_order_id String,
+				client_name String,
+				exchange_name String,
+				label String,
+				pair String,
+				side String,
+				type String,
+				base_qty Decimal(38, 18),
+				price Decimal(38, 18),
+				algorithm_name_placed String,
+				lowest_sell_prc Decimal(38, 18),
+				highest_buy_prc Decimal(38, 18),
+				commission_quote_qty Decimal(38, 18),
+				time_placed DateTime,
+				status String,
+				updated_at DateTime64(6, 'UTC')
+			) ENGINE = ReplacingMergeTree(updated_at)
+			PRIMARY KEY (client_name, exchange_name, pair)
+			ORDER BY (client_name, exchange_name, pair, order_id);`).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec(`
+			INSERT INTO history_orders_replacing
+			SELECT
+				toString(generateUUIDv4()),
+				'',
+				client_name,
+				exchange_name,
+				label,
+				pair,
+				side,
+				type,
+				base_qty,
+				price,
+				algorithm_name_placed,
+				lowest_sell_prc,
+				highest_buy_prc,
+				commission_quote_qty,
+				time_placed,
+				'NEW',
+				time_placed
+			FROM history_orders;`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+			RENAME TABLE
+				history_orders TO history_orders_legacy,
+				history_orders_replacing TO history_orders;`).Error
+}
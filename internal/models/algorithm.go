@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Algorithm is an algorithm that placed orders, with the number of orders it placed and when.
+type Algorithm struct {
+	Name        string    `json:"name"`
+	Orders      uint64    `json:"orders"`
+	FirstPlaced time.Time `json:"firstPlaced"`
+	LastPlaced  time.Time `json:"lastPlaced"`
+}
+
+/*
+AlgorithmVolume holds the order counts and volumes of an algorithm aggregated by the database.
+Volumes are the filled quantity and notional of the fills, or the quantity and notional of FILLED orders without fills.
+Commission is the share of the order commissions for that execution, see HistoryOrder.CommissionFor.
+*/
+type AlgorithmVolume struct {
+	Orders          uint64          `json:"orders"`
+	BuyOrders       uint64          `json:"buyOrders"`
+	SellOrders      uint64          `json:"sellOrders"`
+	FilledOrders    uint64          `json:"filledOrders"`
+	CancelledOrders uint64          `json:"cancelledOrders"`
+	RejectedOrders  uint64          `json:"rejectedOrders"`
+	BaseVolume      decimal.Decimal `json:"baseVolume" swaggertype:"number"`
+	QuoteVolume     decimal.Decimal `json:"quoteVolume" swaggertype:"number"`
+	Commission      decimal.Decimal `json:"commission" swaggertype:"number"`
+}
+
+/*
+AlgorithmStats is the performance of an algorithm over a time window of order placement.
+BuySellRatio is the number of buy orders per sell order and is omitted if there are no sell orders.
+PnL sums the PnL of every client, exchange, label and pair the algorithm traded, slippage and fee drag
+are in bps weighted by notional over the orders that could be evaluated against the book at placement.
+*/
+type AlgorithmStats struct {
+	Algorithm string     `json:"algorithm"`
+	From      *time.Time `json:"from,omitempty"`
+	To        *time.Time `json:"to,omitempty"`
+	AlgorithmVolume
+	BuySellRatio      *float64        `json:"buySellRatio,omitempty"`
+	RealizedPnL       decimal.Decimal `json:"realizedPnl" swaggertype:"number"`
+	UnrealizedPnL     decimal.Decimal `json:"unrealizedPnl" swaggertype:"number"`
+	NetPnL            decimal.Decimal `json:"netPnl" swaggertype:"number"`
+	EvaluatedOrders   int             `json:"evaluatedOrders"`
+	SlippageBps       float64         `json:"slippageBps"`
+	CrossedSpreadRate float64         `json:"crossedSpreadRate"`
+	FeeDragBps        float64         `json:"feeDragBps"`
+}
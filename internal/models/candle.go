@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Length of the candle intervals that can be requested, by name
+var CandleIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+/*
+Candle is an OHLCV bar of the fills of a pair on an exchange starting at Time.
+Volume is in the base asset, QuoteVolume is the sum of quantity times price.
+Only fills are aggregated, FILLED orders saved without fills are left out, unlike in volumes and PnL.
+*/
+type Candle struct {
+	Time        time.Time       `json:"time"`
+	Open        decimal.Decimal `json:"open" swaggertype:"number"`
+	High        decimal.Decimal `json:"high" swaggertype:"number"`
+	Low         decimal.Decimal `json:"low" swaggertype:"number"`
+	Close       decimal.Decimal `json:"close" swaggertype:"number"`
+	Volume      decimal.Decimal `json:"volume" swaggertype:"number"`
+	QuoteVolume decimal.Decimal `json:"quoteVolume" swaggertype:"number"`
+	Trades      uint64          `json:"trades"`
+}
+
+// MidCandle is an OHLC bar of the mid price of the order book snapshots of a pair on an exchange starting at Time.
+type MidCandle struct {
+	Time      time.Time       `json:"time"`
+	Open      decimal.Decimal `json:"open" swaggertype:"number"`
+	High      decimal.Decimal `json:"high" swaggertype:"number"`
+	Low       decimal.Decimal `json:"low" swaggertype:"number"`
+	Close     decimal.Decimal `json:"close" swaggertype:"number"`
+	Snapshots uint64          `json:"snapshots"`
+}
+
+// Candles holds the execution candles of a pair on an exchange and, if requested, its mid price candles.
+type Candles struct {
+	Exchange string       `json:"exchange"`
+	Pair     string       `json:"pair"`
+	Interval string       `json:"interval"`
+	Trades   []*Candle    `json:"trades"`
+	Mids     []*MidCandle `json:"mids,omitempty"`
+}
@@ -1,6 +1,8 @@
 package models
 
+import "github.com/shopspring/decimal"
+
 type DepthOrder struct {
-	Price   float64
-	BaseQty float64
+	Price   decimal.Decimal `swaggertype:"number"`
+	BaseQty decimal.Decimal `swaggertype:"number"`
 }
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Periods commissions can be grouped by in a fee report
+const (
+	FeePeriodDay   = "day"
+	FeePeriodMonth = "month"
+)
+
+/*
+FeeReportFilter selects the orders a fee report is built from.
+Empty fields do not filter, Label and Pair may contain * wildcards.
+Orders placed at or after From and before To are grouped by the UTC day or month they were placed in.
+*/
+type FeeReportFilter struct {
+	ClientName   string
+	ExchangeName string
+	Label        string
+	Pair         string
+	From         time.Time
+	To           time.Time
+	Period       string
+}
+
+/*
+FeeReportRow holds the commissions of a client on a pair of an exchange, with a label, in a period.
+Notional is the executed notional in the quote asset: the fills of orders, or the quantity times price
+of FILLED orders without fills. Commission is the share of the order commissions for that execution,
+see HistoryOrder.CommissionFor. FeeRateBps is the commission relative to the notional.
+*/
+type FeeReportRow struct {
+	PeriodStart  time.Time       `json:"periodStart"`
+	ClientName   string          `json:"clientName"`
+	ExchangeName string          `json:"exchangeName"`
+	Pair         string          `json:"pair"`
+	Label        string          `json:"label"`
+	Orders       uint64          `json:"orders"`
+	Notional     decimal.Decimal `json:"notional" swaggertype:"number"`
+	Commission   decimal.Decimal `json:"commission" swaggertype:"number"`
+	FeeRateBps   float64         `json:"feeRateBps" gorm:"-"`
+}
+
+// FeeReport holds the rows of a fee report, ordered by period, client, exchange, pair and label, and their totals.
+type FeeReport struct {
+	Period     string          `json:"period"`
+	Rows       []*FeeReportRow `json:"rows"`
+	Orders     uint64          `json:"orders"`
+	Notional   decimal.Decimal `json:"notional" swaggertype:"number"`
+	Commission decimal.Decimal `json:"commission" swaggertype:"number"`
+	FeeRateBps float64         `json:"feeRateBps"`
+}
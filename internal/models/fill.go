@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Liquidity a fill took or provided
+const (
+	LiquidityMaker = "MAKER"
+	LiquidityTaker = "TAKER"
+)
+
+/*
+Fill is a single execution of an order.
+ExchangeName and Pair are copied from the order when the fill is saved, for aggregating fills into candles.
+*/
+type Fill struct {
+	FillID       string          `json:"fillId" gorm:"primaryKey"`
+	OrderID      string          `json:"orderId"`
+	ExchangeName string          `json:"-"`
+	Pair         string          `json:"-"`
+	BaseQty      decimal.Decimal `json:"baseQty" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	Price        decimal.Decimal `json:"price" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	Fee          decimal.Decimal `json:"fee" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	FeeAsset     string          `json:"feeAsset"`
+	Liquidity    string          `json:"liquidity"`
+	ExecutedAt   time.Time       `json:"executedAt" gorm:"type:DateTime64(6, 'UTC')"`
+}
+
+/*
+FillSummary aggregates the fills of an order.
+Fees are totalled per fee asset, AvgFillPrice is weighted by quantity.
+*/
+type FillSummary struct {
+	FillCount    int                        `json:"fillCount"`
+	FilledQty    decimal.Decimal            `json:"filledQty" swaggertype:"number"`
+	QuoteQty     decimal.Decimal            `json:"quoteQty" swaggertype:"number"`
+	AvgFillPrice decimal.Decimal            `json:"avgFillPrice" swaggertype:"number"`
+	TotalFees    map[string]decimal.Decimal `json:"totalFees" swaggertype:"object,number"`
+	LastFillAt   time.Time                  `json:"lastFillAt"`
+}
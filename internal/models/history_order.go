@@ -1,24 +1,72 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"github.com/shopspring/decimal"
+)
+
+// Lifecycle statuses of an order
+const (
+	OrderStatusNew             = "NEW"
+	OrderStatusPartiallyFilled = "PARTIALLY_FILLED"
+	OrderStatusFilled          = "FILLED"
+	OrderStatusCancelled       = "CANCELLED"
+	OrderStatusRejected        = "REJECTED"
+)
+
+/*
+HistoryOrder is a single state of an order.
+Every status transition stores a new row with a later UpdatedAt,
+the current state of an order is the row with the latest UpdatedAt for its OrderID.
+CommissionQuoteQty is the commission of the whole order quantity in the quote asset,
+an execution of part of BaseQty is charged the same share of it, see CommissionFor.
+IngestedAt is assigned by the service when the state is saved and orders the order history stream.
+Fills is computed from the fills of the order and not stored.
+*/
 type HistoryOrder struct {
-	ClientName          string    `json:"clientName" gorm:"primaryKey"`
-	ExchangeName        string    `json:"exchangeName" gorm:"primaryKey"`
-	Label               string    `json:"label"`
-	Pair                string    `json:"pair"`
-	Side                string    `json:"side"`
-	Type                string    `json:"type"`
-	BaseQty             float64   `json:"baseQty"`
-	Price               float64   `json:"price"`
-	AlgorithmNamePlaced string    `json:"algorithmNamePlaced"`
-	LowestSellPrc       float64   `json:"lowestSellPrc"`
-	HighestBuyPrc       float64   `json:"highestBuyPrc"`
-	CommissionQuoteQty  float64   `json:"commissionQuoteQty"`
-	TimePlaced          time.Time `json:"timePlaced"`
+	OrderID             string          `json:"orderId" gorm:"primaryKey"`
+	ClientOrderID       string          `json:"clientOrderId"`
+	ClientName          string          `json:"clientName"`
+	ExchangeName        string          `json:"exchangeName"`
+	Label               string          `json:"label"`
+	Pair                string          `json:"pair"`
+	Side                string          `json:"side"`
+	Type                string          `json:"type"`
+	BaseQty             decimal.Decimal `json:"baseQty" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	Price               decimal.Decimal `json:"price" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	AlgorithmNamePlaced string          `json:"algorithmNamePlaced"`
+	LowestSellPrc       decimal.Decimal `json:"lowestSellPrc" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	HighestBuyPrc       decimal.Decimal `json:"highestBuyPrc" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	CommissionQuoteQty  decimal.Decimal `json:"commissionQuoteQty" gorm:"type:Decimal(38, 18)" swaggertype:"number"`
+	TimePlaced          time.Time       `json:"timePlaced"`
+	Status              string          `json:"status"`
+	UpdatedAt           time.Time       `json:"updatedAt" gorm:"type:DateTime64(6, 'UTC')"`
+	IngestedAt          time.Time       `json:"-" gorm:"type:DateTime64(6, 'UTC')"`
+	Fills               *FillSummary    `json:"fills,omitempty" gorm:"-"`
+}
+
+/*
+CommissionFor returns the share of the order commission charged for an executed quantity,
+the whole commission once BaseQty or more is executed and none if nothing is.
+*/
+func (o *HistoryOrder) CommissionFor(executedQty decimal.Decimal) decimal.Decimal {
+	if !executedQty.IsPositive() {
+		return decimal.Zero
+	}
+	if !o.BaseQty.IsPositive() || executedQty.GreaterThanOrEqual(o.BaseQty) {
+		return o.CommissionQuoteQty
+	}
+	return o.CommissionQuoteQty.Mul(executedQty).Div(o.BaseQty)
 }
 
 type HistoryOrderPayload struct {
 	Client  Client
 	History HistoryOrder
 }
+
+// OrderStatusUpdate records a status transition of an order, UpdatedAt defaults to the current time.
+type OrderStatusUpdate struct {
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -4,30 +4,81 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/shopspring/decimal"
 )
 
 type OrderBookDTO struct {
-	ID       int64
-	Exchange string
-	Pair     string
-	Asks     []*DepthOrder
-	Bids     []*DepthOrder
+	ID           int64
+	Exchange     string
+	Pair         string
+	Asks         []*DepthOrder
+	Bids         []*DepthOrder
+	ExchangeTime time.Time
+	ReceivedTime time.Time
+	Sequence     int64
 }
 
-type Tuple [2]float64
+type Tuple [2]decimal.Decimal
 
 /*
 Type for easier integration with ClickHouse to store array of tuples
-Implements Scanner and Valuer interfaces for auto marshal and unmarshal
+Implements Scanner and Valuer interfaces to map levels to a native
+Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18))) column
 */
 type Tuples []Tuple
 
 func (t Tuples) Value() (driver.Value, error) {
-	return json.Marshal(t)
+	levels := make(clickhouse.ArraySet, len(t))
+	for i, tuple := range t {
+		levels[i] = clickhouse.GroupSet{Value: []any{tuple[0], tuple[1]}}
+	}
+	return levels, nil
 }
 
+/*
+Scan accepts named tuples (map per level) and unnamed tuples (slice per level)
+as returned by the ClickHouse driver. JSON encoded levels from the legacy
+String columns are still accepted.
+*/
 func (t *Tuples) Scan(value interface{}) error {
 	switch v := value.(type) {
+	case []map[string]any:
+		tuples := make(Tuples, len(v))
+		for i, level := range v {
+			price, err := toDecimal(level["price"])
+			if err != nil {
+				return err
+			}
+			qty, err := toDecimal(level["qty"])
+			if err != nil {
+				return err
+			}
+			tuples[i] = Tuple{price, qty}
+		}
+		*t = tuples
+		return nil
+	case [][]any:
+		tuples := make(Tuples, len(v))
+		for i, level := range v {
+			if len(level) != 2 {
+				return fmt.Errorf("invalid level size: expected 2, got %d", len(level))
+			}
+			price, err := toDecimal(level[0])
+			if err != nil {
+				return err
+			}
+			qty, err := toDecimal(level[1])
+			if err != nil {
+				return err
+			}
+			tuples[i] = Tuple{price, qty}
+		}
+		*t = tuples
+		return nil
 	case []byte:
 		return json.Unmarshal(v, t)
 	case string:
@@ -37,31 +88,58 @@ func (t *Tuples) Scan(value interface{}) error {
 	}
 }
 
+func toDecimal(value any) (decimal.Decimal, error) {
+	switch v := value.(type) {
+	case decimal.Decimal:
+		return v, nil
+	case *decimal.Decimal:
+		if v == nil {
+			return decimal.Zero, errors.New("unexpected nil level value")
+		}
+		return *v, nil
+	case float64:
+		return decimal.NewFromFloat(v), nil
+	case string:
+		return decimal.NewFromString(v)
+	default:
+		return decimal.Zero, fmt.Errorf("unsupported level value type %T", value)
+	}
+}
+
 type OrderBook struct {
-	ID       int64
-	Exchange string `json:"exchange"`
-	Pair     string `json:"pair"`
-	Asks     Tuples `json:"asks"`
-	Bids     Tuples `json:"bids"`
+	ID           int64
+	Exchange     string    `json:"exchange"`
+	Pair         string    `json:"pair"`
+	Asks         Tuples    `json:"asks" gorm:"type:Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18)))"`
+	Bids         Tuples    `json:"bids" gorm:"type:Array(Tuple(price Decimal(38, 18), qty Decimal(38, 18)))"`
+	ExchangeTime time.Time `json:"exchangeTime" gorm:"type:DateTime64(6, 'UTC')"`
+	ReceivedTime time.Time `json:"receivedTime" gorm:"type:DateTime64(6, 'UTC')"`
+	Sequence     int64     `json:"sequence"`
 }
 
 func (dto *OrderBookDTO) ToOrderBook() OrderBook {
 	return OrderBook{
-		ID:       dto.ID,
-		Exchange: dto.Exchange,
-		Pair:     dto.Pair,
-		Asks:     depthOrdersToTuples(dto.Asks),
-		Bids:     depthOrdersToTuples(dto.Bids),
+		ID:           dto.ID,
+		Exchange:     dto.Exchange,
+		Pair:         dto.Pair,
+		Asks:         depthOrdersToTuples(dto.Asks),
+		Bids:         depthOrdersToTuples(dto.Bids),
+		ExchangeTime: dto.ExchangeTime,
+		ReceivedTime: dto.ReceivedTime,
+		Sequence:     dto.Sequence,
 	}
 }
 
 func (o *OrderBook) ToDTO() OrderBookDTO {
 	return OrderBookDTO{
-		ID:       o.ID,
-		Exchange: o.Exchange,
-		Pair:     o.Pair,
-		Asks:     tuplesToDepthOrders(o.Asks),
-		Bids:     tuplesToDepthOrders(o.Bids),
+		ID:           o.ID,
+		Exchange:     o.Exchange,
+		Pair:         o.Pair,
+		Asks:         tuplesToDepthOrders(o.Asks),
+		Bids:         tuplesToDepthOrders(o.Bids),
+		ExchangeTime: o.ExchangeTime,
+		ReceivedTime: o.ReceivedTime,
+		Sequence:     o.Sequence,
 	}
 }
 
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+/*
+Incremental update of an order book published by an exchange
+Levels with zero BaseQty remove the price level from the book
+*/
+type OrderBookDelta struct {
+	Exchange     string
+	Pair         string
+	Sequence     int64
+	ExchangeTime time.Time
+	Asks         []*DepthOrder
+	Bids         []*DepthOrder
+}
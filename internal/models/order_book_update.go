@@ -0,0 +1,19 @@
+package models
+
+// Kinds of order book updates published to subscribers
+const (
+	OrderBookUpdateSnapshot = "snapshot"
+	OrderBookUpdateDelta    = "delta"
+)
+
+/*
+OrderBookUpdate is a saved order book snapshot or an applied delta of an exchange and pair.
+Book is set for snapshots and Delta for deltas, both carry the exchange sequence number.
+*/
+type OrderBookUpdate struct {
+	Type     string          `json:"type"`
+	Exchange string          `json:"exchange"`
+	Pair     string          `json:"pair"`
+	Book     *OrderBookDTO   `json:"book,omitempty"`
+	Delta    *OrderBookDelta `json:"delta,omitempty"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// OrderStreamFilter selects the orders delivered on the order history stream, empty fields match any order.
+type OrderStreamFilter struct {
+	ClientName   string
+	ExchangeName string
+	Label        string
+	Pair         string
+}
+
+// Matches reports whether the order belongs to the client, exchange, label and pair of the filter.
+func (f *OrderStreamFilter) Matches(order *HistoryOrder) bool {
+	return (f.ClientName == "" || f.ClientName == order.ClientName) &&
+		(f.ExchangeName == "" || f.ExchangeName == order.ExchangeName) &&
+		(f.Label == "" || f.Label == order.Label) &&
+		(f.Pair == "" || f.Pair == order.Pair)
+}
+
+/*
+OrderEventPosition is the position of a saved order state on the order history stream.
+Events are ordered by the time the state was ingested, then by order ID.
+*/
+type OrderEventPosition struct {
+	IngestedAt time.Time
+	OrderID    string
+}
+
+// EventPosition returns the position of a saved order state on the order history stream.
+func EventPosition(order *HistoryOrder) OrderEventPosition {
+	return OrderEventPosition{IngestedAt: order.IngestedAt, OrderID: order.OrderID}
+}
+
+// Before reports whether the position comes before other on the order history stream.
+func (p OrderEventPosition) Before(other OrderEventPosition) bool {
+	if !p.IngestedAt.Equal(other.IngestedAt) {
+		return p.IngestedAt.Before(other.IngestedAt)
+	}
+	return p.OrderID < other.OrderID
+}
+
+// OrderEvent is an order history record delivered on the order history stream, ID resumes the stream after it.
+type OrderEvent struct {
+	ID    string
+	Order *HistoryOrder
+}
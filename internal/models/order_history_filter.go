@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Sort directions of order history, by the time orders were placed
+const (
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+/*
+OrderHistoryFilter selects the orders of a client.
+Empty fields do not filter, Label and Pair may contain * wildcards.
+Orders placed at or after From and before To are returned, sorted by Sort, Limit at a time.
+Cursor is the nextCursor of the previous page.
+*/
+type OrderHistoryFilter struct {
+	ClientName          string    `json:"clientName"`
+	ExchangeName        string    `json:"exchangeName"`
+	Label               string    `json:"label"`
+	Pair                string    `json:"pair"`
+	From                time.Time `json:"from"`
+	To                  time.Time `json:"to"`
+	Side                string    `json:"side"`
+	Type                string    `json:"type"`
+	AlgorithmNamePlaced string    `json:"algorithmNamePlaced"`
+	Sort                string    `json:"sort"`
+	Limit               int       `json:"limit"`
+	Cursor              string    `json:"cursor"`
+}
+
+// OrderHistoryCursor is the position of the last order of a page.
+type OrderHistoryCursor struct {
+	TimePlaced time.Time `json:"timePlaced"`
+	OrderID    string    `json:"orderId"`
+	Sort       string    `json:"sort"`
+}
+
+// OrderHistoryPage is a page of order history, NextCursor is empty on the last page.
+type OrderHistoryPage struct {
+	Orders     []*HistoryOrder `json:"orders"`
+	NextCursor string          `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+/*
+Number of decimal places prices and quantities of a pair are quoted with on an exchange
+Used to reject values that cannot be represented exactly on the exchange
+*/
+type PairPrecision struct {
+	Exchange   string    `json:"exchange"`
+	Pair       string    `json:"pair"`
+	PriceScale int32     `json:"priceScale"`
+	QtyScale   int32     `json:"qtyScale"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
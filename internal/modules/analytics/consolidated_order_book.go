@@ -0,0 +1,201 @@
+package analytics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// VenueQty is the quantity an exchange contributes to a consolidated price level.
+type VenueQty struct {
+	Exchange string          `json:"exchange"`
+	BaseQty  decimal.Decimal `json:"baseQty" swaggertype:"number"`
+}
+
+// ConsolidatedLevel is a price level aggregated across exchanges.
+type ConsolidatedLevel struct {
+	Price   decimal.Decimal `json:"price" swaggertype:"number"`
+	BaseQty decimal.Decimal `json:"baseQty" swaggertype:"number"`
+	Venues  []VenueQty      `json:"venues"`
+}
+
+// Quote is the best price and its quantity on one side of an exchange's book.
+type Quote struct {
+	Exchange string          `json:"exchange"`
+	Price    decimal.Decimal `json:"price" swaggertype:"number"`
+	BaseQty  decimal.Decimal `json:"baseQty" swaggertype:"number"`
+}
+
+// VenueQuote is the best bid and offer of a single exchange, nil for an empty side.
+type VenueQuote struct {
+	Exchange string `json:"exchange"`
+	Bid      *Quote `json:"bid"`
+	Ask      *Quote `json:"ask"`
+}
+
+/*
+Arbitrage is a cross-venue opportunity where the bid on SellExchange is above the ask on BuyExchange.
+BaseQty is the quantity available at both top of book prices.
+*/
+type Arbitrage struct {
+	BuyExchange  string          `json:"buyExchange"`
+	SellExchange string          `json:"sellExchange"`
+	AskPrice     decimal.Decimal `json:"askPrice" swaggertype:"number"`
+	BidPrice     decimal.Decimal `json:"bidPrice" swaggertype:"number"`
+	BaseQty      decimal.Decimal `json:"baseQty" swaggertype:"number"`
+	SpreadBps    float64         `json:"spreadBps"`
+}
+
+// StaleVenue is an exchange left out of a consolidated book because its latest snapshot is too old.
+type StaleVenue struct {
+	Exchange     string    `json:"exchange"`
+	ExchangeTime time.Time `json:"exchangeTime"`
+	AgeMillis    int64     `json:"ageMillis"`
+}
+
+// ConsolidatedOrderBook is the aggregated book of a pair over the latest snapshot of every exchange.
+type ConsolidatedOrderBook struct {
+	Pair      string               `json:"pair"`
+	Exchanges []string             `json:"exchanges"`
+	Excluded  []StaleVenue         `json:"excluded"`
+	Asks      []*ConsolidatedLevel `json:"asks"`
+	Bids      []*ConsolidatedLevel `json:"bids"`
+	BestBid   *Quote               `json:"bestBid"`
+	BestAsk   *Quote               `json:"bestAsk"`
+	Venues    []VenueQuote         `json:"venues"`
+	Crossed   bool                 `json:"crossed"`
+	Arbitrage []Arbitrage          `json:"arbitrage"`
+}
+
+/*
+Consolidate merges order books of the same pair from several exchanges.
+Levels at the same price are summed keeping the quantity of every venue,
+the best bid and offer of each venue are compared to find cross-venue arbitrage.
+*/
+func Consolidate(pair string, books []*models.OrderBookDTO) *ConsolidatedOrderBook {
+	sorted := make([]*models.OrderBookDTO, len(books))
+	copy(sorted, books)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Exchange < sorted[j].Exchange
+	})
+
+	consolidated := &ConsolidatedOrderBook{
+		Pair:      pair,
+		Exchanges: make([]string, 0, len(sorted)),
+		Excluded:  []StaleVenue{},
+		Venues:    make([]VenueQuote, 0, len(sorted)),
+		Arbitrage: []Arbitrage{},
+	}
+
+	asks := make(map[string]*ConsolidatedLevel)
+	bids := make(map[string]*ConsolidatedLevel)
+	for _, book := range sorted {
+		consolidated.Exchanges = append(consolidated.Exchanges, book.Exchange)
+		addLevels(asks, book.Exchange, book.Asks)
+		addLevels(bids, book.Exchange, book.Bids)
+
+		venue := VenueQuote{Exchange: book.Exchange}
+		if levels := SortedLevels(book.Bids, true); len(levels) > 0 {
+			venue.Bid = &Quote{Exchange: book.Exchange, Price: levels[0].Price, BaseQty: levels[0].BaseQty}
+		}
+		if levels := SortedLevels(book.Asks, false); len(levels) > 0 {
+			venue.Ask = &Quote{Exchange: book.Exchange, Price: levels[0].Price, BaseQty: levels[0].BaseQty}
+		}
+		consolidated.Venues = append(consolidated.Venues, venue)
+
+		if venue.Bid != nil && (consolidated.BestBid == nil || venue.Bid.Price.GreaterThan(consolidated.BestBid.Price)) {
+			consolidated.BestBid = venue.Bid
+		}
+		if venue.Ask != nil && (consolidated.BestAsk == nil || venue.Ask.Price.LessThan(consolidated.BestAsk.Price)) {
+			consolidated.BestAsk = venue.Ask
+		}
+	}
+
+	consolidated.Asks = sortedConsolidatedLevels(asks, false)
+	consolidated.Bids = sortedConsolidatedLevels(bids, true)
+
+	for _, seller := range consolidated.Venues {
+		for _, buyer := range consolidated.Venues {
+			if seller.Exchange == buyer.Exchange || seller.Bid == nil || buyer.Ask == nil {
+				continue
+			}
+			if seller.Bid.Price.LessThanOrEqual(buyer.Ask.Price) {
+				continue
+			}
+
+			consolidated.Arbitrage = append(consolidated.Arbitrage, Arbitrage{
+				BuyExchange:  buyer.Exchange,
+				SellExchange: seller.Exchange,
+				AskPrice:     buyer.Ask.Price,
+				BidPrice:     seller.Bid.Price,
+				BaseQty:      decimal.Min(buyer.Ask.BaseQty, seller.Bid.BaseQty),
+				SpreadBps:    Bps(seller.Bid.Price.Sub(buyer.Ask.Price), buyer.Ask.Price),
+			})
+		}
+	}
+	consolidated.Crossed = len(consolidated.Arbitrage) > 0
+
+	return consolidated
+}
+
+/*
+SplitStale separates the books whose snapshot is older than maxAge at now from the fresh ones.
+The age of a snapshot is taken from its exchange time, or its received time if the exchange time is not set.
+Stale venues are sorted by exchange.
+*/
+func SplitStale(books []*models.OrderBookDTO, now time.Time, maxAge time.Duration) ([]*models.OrderBookDTO, []StaleVenue) {
+	fresh := make([]*models.OrderBookDTO, 0, len(books))
+	stale := []StaleVenue{}
+	for _, book := range books {
+		snapshotTime := book.ExchangeTime
+		if snapshotTime.IsZero() {
+			snapshotTime = book.ReceivedTime
+		}
+
+		age := now.Sub(snapshotTime)
+		if age > maxAge {
+			stale = append(stale, StaleVenue{Exchange: book.Exchange, ExchangeTime: snapshotTime, AgeMillis: age.Milliseconds()})
+			continue
+		}
+		fresh = append(fresh, book)
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].Exchange < stale[j].Exchange
+	})
+	return fresh, stale
+}
+
+func addLevels(levels map[string]*ConsolidatedLevel, exchange string, orders []*models.DepthOrder) {
+	for _, order := range orders {
+		if order == nil {
+			continue
+		}
+
+		key := order.Price.String()
+		level, ok := levels[key]
+		if !ok {
+			level = &ConsolidatedLevel{Price: order.Price, BaseQty: decimal.Zero}
+			levels[key] = level
+		}
+		level.BaseQty = level.BaseQty.Add(order.BaseQty)
+		level.Venues = append(level.Venues, VenueQty{Exchange: exchange, BaseQty: order.BaseQty})
+	}
+}
+
+func sortedConsolidatedLevels(levels map[string]*ConsolidatedLevel, descending bool) []*ConsolidatedLevel {
+	sorted := make([]*ConsolidatedLevel, 0, len(levels))
+	for _, level := range levels {
+		sorted = append(sorted, level)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Price.GreaterThan(sorted[j].Price)
+		}
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+	return sorted
+}
@@ -0,0 +1,98 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsolidate(t *testing.T) {
+	books := []*models.OrderBookDTO{
+		{
+			Exchange: "venue_b",
+			Pair:     "BTC/USD",
+			Asks:     []*models.DepthOrder{level("100.5", "1"), level("101", "2")},
+			Bids:     []*models.DepthOrder{level("100", "3")},
+		},
+		{
+			Exchange: "venue_a",
+			Pair:     "BTC/USD",
+			Asks:     []*models.DepthOrder{level("101.0", "1")},
+			Bids:     []*models.DepthOrder{level("99", "1")},
+		},
+	}
+
+	book := Consolidate("BTC/USD", books)
+
+	assert.Equal(t, []string{"venue_a", "venue_b"}, book.Exchanges)
+	assert.Len(t, book.Asks, 2)
+	assert.Equal(t, "100.5", book.Asks[0].Price.String())
+	assert.Equal(t, "101", book.Asks[1].Price.String())
+	assert.Equal(t, "3", book.Asks[1].BaseQty.String())
+	assert.Len(t, book.Asks[1].Venues, 2)
+	assert.Equal(t, "venue_a", book.Asks[1].Venues[0].Exchange)
+	assert.Equal(t, "venue_b", book.Asks[1].Venues[1].Exchange)
+	assert.Equal(t, "100", book.Bids[0].Price.String())
+
+	assert.Equal(t, "venue_b", book.BestBid.Exchange)
+	assert.Equal(t, "venue_b", book.BestAsk.Exchange)
+	assert.False(t, book.Crossed)
+	assert.Empty(t, book.Arbitrage)
+}
+
+func TestConsolidate_Arbitrage(t *testing.T) {
+	books := []*models.OrderBookDTO{
+		{
+			Exchange: "venue_a",
+			Asks:     []*models.DepthOrder{level("100", "2")},
+			Bids:     []*models.DepthOrder{level("99", "1")},
+		},
+		{
+			Exchange: "venue_b",
+			Asks:     []*models.DepthOrder{level("102", "1")},
+			Bids:     []*models.DepthOrder{level("101", "0.5")},
+		},
+		{
+			Exchange: "venue_c",
+			Bids:     []*models.DepthOrder{level("98", "1")},
+		},
+	}
+
+	book := Consolidate("BTC/USD", books)
+
+	assert.True(t, book.Crossed)
+	assert.Len(t, book.Arbitrage, 1)
+	arbitrage := book.Arbitrage[0]
+	assert.Equal(t, "venue_a", arbitrage.BuyExchange)
+	assert.Equal(t, "venue_b", arbitrage.SellExchange)
+	assert.Equal(t, "100", arbitrage.AskPrice.String())
+	assert.Equal(t, "101", arbitrage.BidPrice.String())
+	assert.Equal(t, "0.5", arbitrage.BaseQty.String())
+	assert.InDelta(t, 100.0, arbitrage.SpreadBps, 1e-9)
+	assert.Nil(t, book.Venues[2].Ask)
+}
+
+func TestSplitStale(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	books := []*models.OrderBookDTO{
+		{Exchange: "venue_c", ExchangeTime: now.Add(-time.Minute)},
+		{Exchange: "venue_a", ExchangeTime: now.Add(-time.Second)},
+		// Snapshots without an exchange time are aged by their received time
+		{Exchange: "venue_b", ReceivedTime: now.Add(-2 * time.Minute)},
+	}
+
+	fresh, stale := SplitStale(books, now, 30*time.Second)
+
+	if assert.Len(t, fresh, 1) {
+		assert.Equal(t, "venue_a", fresh[0].Exchange)
+	}
+	if assert.Len(t, stale, 2) {
+		assert.Equal(t, "venue_b", stale[0].Exchange)
+		assert.Equal(t, int64(120000), stale[0].AgeMillis)
+		assert.Equal(t, "venue_c", stale[1].Exchange)
+		assert.Equal(t, now.Add(-time.Minute), stale[1].ExchangeTime)
+	}
+}
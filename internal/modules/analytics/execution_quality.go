@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+OrderExecution is the execution quality of a single order against the book at placement.
+SlippageBps is the distance of the execution price from the arrival mid, positive when worse than the mid
+and negative for price improvement. Commission is the share of the order commission for the executed quantity,
+FeeDragBps is that commission relative to the executed notional.
+*/
+type OrderExecution struct {
+	OrderID        string          `json:"orderId"`
+	Algorithm      string          `json:"algorithm"`
+	Exchange       string          `json:"exchange"`
+	Pair           string          `json:"pair"`
+	Side           string          `json:"side"`
+	ExecutedQty    decimal.Decimal `json:"executedQty" swaggertype:"number"`
+	ExecutionPrice decimal.Decimal `json:"executionPrice" swaggertype:"number"`
+	ArrivalMid     decimal.Decimal `json:"arrivalMid" swaggertype:"number"`
+	Notional       decimal.Decimal `json:"notional" swaggertype:"number"`
+	SlippageBps    float64         `json:"slippageBps"`
+	CrossedSpread  bool            `json:"crossedSpread"`
+	Commission     decimal.Decimal `json:"commission" swaggertype:"number"`
+	FeeDragBps     float64         `json:"feeDragBps"`
+}
+
+/*
+AlgorithmExecution aggregates the execution quality of the orders placed by an algorithm.
+Slippage and fee drag are weighted by notional, CrossedSpreadRate is the share of orders that crossed the spread.
+*/
+type AlgorithmExecution struct {
+	Algorithm         string          `json:"algorithm"`
+	Orders            int             `json:"orders"`
+	Notional          decimal.Decimal `json:"notional" swaggertype:"number"`
+	SlippageBps       float64         `json:"slippageBps"`
+	CrossedSpreadRate float64         `json:"crossedSpreadRate"`
+	FeeDragBps        float64         `json:"feeDragBps"`
+}
+
+// ExecutionQualityReport holds the execution quality of orders, Skipped counts orders that could not be evaluated.
+type ExecutionQualityReport struct {
+	Orders     []*OrderExecution     `json:"orders"`
+	Algorithms []*AlgorithmExecution `json:"algorithms"`
+	Skipped    int                   `json:"skipped"`
+}
+
+// algorithmTotals accumulates the sums AlgorithmExecution is computed from.
+type algorithmTotals struct {
+	orders          int
+	crossed         int
+	notional        decimal.Decimal
+	arrivalNotional decimal.Decimal
+	slippageCost    decimal.Decimal
+	commission      decimal.Decimal
+}
+
+/*
+ExecutionQuality evaluates orders against the best bid and ask captured at placement.
+Orders with fills are evaluated at their average fill price and filled quantity, FILLED orders without fills
+at their price and quantity, as when replaying PnL. Other orders without fills, orders without a valid book
+at placement or with a side other than buy or sell are skipped.
+*/
+func ExecutionQuality(orders []*models.HistoryOrder) *ExecutionQualityReport {
+	report := &ExecutionQualityReport{Orders: []*OrderExecution{}, Algorithms: []*AlgorithmExecution{}}
+	totals := make(map[string]*algorithmTotals)
+
+	for _, order := range orders {
+		execution, ok := evaluateOrder(order)
+		if !ok {
+			report.Skipped++
+			continue
+		}
+		report.Orders = append(report.Orders, execution)
+
+		total, ok := totals[execution.Algorithm]
+		if !ok {
+			total = &algorithmTotals{}
+			totals[execution.Algorithm] = total
+		}
+
+		arrivalNotional := execution.ArrivalMid.Mul(execution.ExecutedQty)
+		total.orders++
+		total.notional = total.notional.Add(execution.Notional)
+		total.arrivalNotional = total.arrivalNotional.Add(arrivalNotional)
+		total.slippageCost = total.slippageCost.Add(execution.Notional.Sub(arrivalNotional).Mul(sideSign(execution.Side)))
+		total.commission = total.commission.Add(execution.Commission)
+		if execution.CrossedSpread {
+			total.crossed++
+		}
+	}
+
+	for algorithm, total := range totals {
+		report.Algorithms = append(report.Algorithms, &AlgorithmExecution{
+			Algorithm:         algorithm,
+			Orders:            total.orders,
+			Notional:          total.notional,
+			SlippageBps:       Bps(total.slippageCost, total.arrivalNotional),
+			CrossedSpreadRate: float64(total.crossed) / float64(total.orders),
+			FeeDragBps:        Bps(total.commission, total.notional),
+		})
+	}
+	sort.Slice(report.Algorithms, func(i, j int) bool {
+		return report.Algorithms[i].Algorithm < report.Algorithms[j].Algorithm
+	})
+
+	return report
+}
+
+/*
+evaluateOrder evaluates a single order, charged the share of the order commission for its executed quantity.
+Returns false if the order cannot be evaluated.
+*/
+func evaluateOrder(order *models.HistoryOrder) (*OrderExecution, bool) {
+	side := strings.ToLower(order.Side)
+	if side != SideBuy && side != SideSell {
+		return nil, false
+	}
+
+	bid, ask := order.HighestBuyPrc, order.LowestSellPrc
+	if !bid.IsPositive() || !ask.IsPositive() || bid.GreaterThan(ask) {
+		return nil, false
+	}
+
+	qty, price := order.BaseQty, order.Price
+	if order.Fills != nil && order.Fills.FilledQty.IsPositive() {
+		qty, price = order.Fills.FilledQty, order.Fills.AvgFillPrice
+	} else if order.Status != models.OrderStatusFilled {
+		return nil, false
+	}
+	if !qty.IsPositive() || !price.IsPositive() {
+		return nil, false
+	}
+
+	mid := bid.Add(ask).Mul(half)
+	execution := &OrderExecution{
+		OrderID:        order.OrderID,
+		Algorithm:      order.AlgorithmNamePlaced,
+		Exchange:       order.ExchangeName,
+		Pair:           order.Pair,
+		Side:           side,
+		ExecutedQty:    qty,
+		ExecutionPrice: price,
+		ArrivalMid:     mid,
+		Notional:       qty.Mul(price),
+	}
+
+	execution.SlippageBps = Bps(price.Sub(mid).Mul(sideSign(side)), mid)
+	if side == SideBuy {
+		execution.CrossedSpread = price.GreaterThanOrEqual(ask)
+	} else {
+		execution.CrossedSpread = price.LessThanOrEqual(bid)
+	}
+	execution.Commission = order.CommissionFor(qty)
+	execution.FeeDragBps = Bps(execution.Commission, execution.Notional)
+
+	return execution, true
+}
+
+// sideSign is 1 for buys and -1 for sells, so that paying more than the mid is a cost on either side.
+func sideSign(side string) decimal.Decimal {
+	if side == SideSell {
+		return decimal.NewFromInt(-1)
+	}
+	return decimal.NewFromInt(1)
+}
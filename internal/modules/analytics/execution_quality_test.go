@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func placedOrder(id, algorithm, side, qty, price, bid, ask, commission string) *models.HistoryOrder {
+	return &models.HistoryOrder{
+		OrderID:             id,
+		AlgorithmNamePlaced: algorithm,
+		Side:                side,
+		BaseQty:             decimal.RequireFromString(qty),
+		Price:               decimal.RequireFromString(price),
+		HighestBuyPrc:       decimal.RequireFromString(bid),
+		LowestSellPrc:       decimal.RequireFromString(ask),
+		CommissionQuoteQty:  decimal.RequireFromString(commission),
+	}
+}
+
+func TestExecutionQuality(t *testing.T) {
+	filled := placedOrder("order-3", "twap", "sell", "5", "0", "99", "101", "0")
+	filled.Fills = &models.FillSummary{FilledQty: decimal.NewFromInt(2), AvgFillPrice: decimal.NewFromInt(99)}
+
+	unfilled := placedOrder("order-4", "twap", "buy", "1", "100", "99", "101", "0")
+	unfilled.Fills = &models.FillSummary{FilledQty: decimal.Zero}
+
+	orders := []*models.HistoryOrder{
+		placedOrder("order-1", "twap", "buy", "1", "101", "99", "101", "0.101"),
+		placedOrder("order-2", "passive", "buy", "2", "99.5", "99", "101", "0"),
+		filled,
+		unfilled,
+		placedOrder("order-5", "twap", "buy", "1", "100", "0", "101", "0"),
+		placedOrder("order-6", "twap", "hold", "1", "100", "99", "101", "0"),
+	}
+	orders[0].Status = models.OrderStatusFilled
+	orders[1].Status = models.OrderStatusFilled
+
+	report := ExecutionQuality(orders)
+
+	assert.Equal(t, 3, report.Skipped)
+	assert.Len(t, report.Orders, 3)
+
+	aggressive := report.Orders[0]
+	assert.Equal(t, "100", aggressive.ArrivalMid.String())
+	assert.InDelta(t, 100.0, aggressive.SlippageBps, 1e-9)
+	assert.True(t, aggressive.CrossedSpread)
+	assert.InDelta(t, 10.0, aggressive.FeeDragBps, 1e-9)
+
+	passive := report.Orders[1]
+	assert.InDelta(t, -50.0, passive.SlippageBps, 1e-9)
+	assert.False(t, passive.CrossedSpread)
+
+	sell := report.Orders[2]
+	assert.Equal(t, "2", sell.ExecutedQty.String())
+	assert.InDelta(t, 100.0, sell.SlippageBps, 1e-9)
+	assert.True(t, sell.CrossedSpread)
+
+	assert.Len(t, report.Algorithms, 2)
+	assert.Equal(t, "passive", report.Algorithms[0].Algorithm)
+
+	twap := report.Algorithms[1]
+	assert.Equal(t, 2, twap.Orders)
+	assert.Equal(t, "299", twap.Notional.String())
+	// 1 paid on the buy and 2 given up on the sell against 300 of arrival notional
+	assert.InDelta(t, 100.0, twap.SlippageBps, 1e-9)
+	assert.Equal(t, 1.0, twap.CrossedSpreadRate)
+	assert.InDelta(t, 0.101*10000/299, twap.FeeDragBps, 1e-9)
+}
+
+func TestExecutionQuality_PartialFill(t *testing.T) {
+	partial := placedOrder("order-1", "twap", "buy", "4", "100", "99", "101", "2")
+	partial.Status = models.OrderStatusCancelled
+	partial.Fills = &models.FillSummary{FilledQty: decimal.NewFromInt(1), AvgFillPrice: decimal.NewFromInt(100)}
+
+	open := placedOrder("order-2", "twap", "buy", "1", "100", "99", "101", "0")
+	open.Status = models.OrderStatusNew
+
+	report := ExecutionQuality([]*models.HistoryOrder{partial, open})
+
+	assert.Equal(t, 1, report.Skipped)
+	assert.Len(t, report.Orders, 1)
+
+	// a quarter of the order was filled, so a quarter of its commission is charged
+	execution := report.Orders[0]
+	assert.Equal(t, "1", execution.ExecutedQty.String())
+	assert.Equal(t, "0.5", execution.Commission.String())
+	assert.InDelta(t, 50.0, execution.FeeDragBps, 1e-9)
+	assert.InDelta(t, 50.0, report.Algorithms[0].FeeDragBps, 1e-9)
+}
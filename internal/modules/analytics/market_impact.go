@@ -0,0 +1,94 @@
+package analytics
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Order sides accepted by the impact estimator.
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// ErrInvalidSide is returned for a side other than buy or sell.
+var ErrInvalidSide = errors.New("side must be buy or sell")
+
+// MarketImpact is the estimated execution of a market order against a snapshot of the book.
+type MarketImpact struct {
+	Exchange              string          `json:"exchange"`
+	Pair                  string          `json:"pair"`
+	Side                  string          `json:"side"`
+	BaseQty               decimal.Decimal `json:"baseQty" swaggertype:"number"`
+	FilledQty             decimal.Decimal `json:"filledQty" swaggertype:"number"`
+	QuoteQty              decimal.Decimal `json:"quoteQty" swaggertype:"number"`
+	VwapPrice             decimal.Decimal `json:"vwapPrice" swaggertype:"number"`
+	WorstPrice            decimal.Decimal `json:"worstPrice" swaggertype:"number"`
+	Mid                   decimal.Decimal `json:"mid" swaggertype:"number"`
+	SlippageBps           float64         `json:"slippageBps"`
+	LevelsConsumed        int             `json:"levelsConsumed"`
+	InsufficientLiquidity bool            `json:"insufficientLiquidity"`
+}
+
+/*
+EstimateImpact walks the book to fill baseQty on the given side, buys consume asks and sells consume bids.
+SlippageBps is the distance of the fill VWAP from the mid, positive when the fill is worse than the mid.
+If the book cannot fill the whole quantity, the estimate covers the available depth
+and InsufficientLiquidity is set.
+*/
+func EstimateImpact(book *models.OrderBookDTO, side string, baseQty decimal.Decimal) (*MarketImpact, error) {
+	side = strings.ToLower(side)
+	if side != SideBuy && side != SideSell {
+		return nil, ErrInvalidSide
+	}
+
+	asks := SortedLevels(book.Asks, false)
+	bids := SortedLevels(book.Bids, true)
+	if len(asks) == 0 || len(bids) == 0 {
+		return nil, ErrOneSidedBook
+	}
+
+	levels := asks
+	if side == SideSell {
+		levels = bids
+	}
+
+	impact := &MarketImpact{
+		Exchange:  book.Exchange,
+		Pair:      book.Pair,
+		Side:      side,
+		BaseQty:   baseQty,
+		FilledQty: decimal.Zero,
+		QuoteQty:  decimal.Zero,
+		Mid:       Mid(bids[0], asks[0]),
+	}
+
+	remaining := baseQty
+	for _, level := range levels {
+		if !remaining.IsPositive() {
+			break
+		}
+
+		qty := decimal.Min(level.BaseQty, remaining)
+		impact.FilledQty = impact.FilledQty.Add(qty)
+		impact.QuoteQty = impact.QuoteQty.Add(qty.Mul(level.Price))
+		impact.WorstPrice = level.Price
+		impact.LevelsConsumed++
+		remaining = remaining.Sub(qty)
+	}
+
+	impact.InsufficientLiquidity = remaining.IsPositive()
+	if impact.FilledQty.IsPositive() {
+		impact.VwapPrice = impact.QuoteQty.Div(impact.FilledQty)
+		impact.SlippageBps = Bps(impact.VwapPrice.Sub(impact.Mid), impact.Mid)
+		if side == SideSell {
+			impact.SlippageBps = -impact.SlippageBps
+		}
+	}
+
+	return impact, nil
+}
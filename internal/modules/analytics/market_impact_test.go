@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func impactBook() *models.OrderBookDTO {
+	return &models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     []*models.DepthOrder{level("101", "1"), level("102", "2")},
+		Bids:     []*models.DepthOrder{level("99", "2"), level("98", "1")},
+	}
+}
+
+func TestEstimateImpact_Buy(t *testing.T) {
+	impact, err := EstimateImpact(impactBook(), "BUY", decimal.NewFromInt(2))
+	assert.NoError(t, err)
+
+	assert.Equal(t, SideBuy, impact.Side)
+	assert.Equal(t, "2", impact.FilledQty.String())
+	assert.Equal(t, "203", impact.QuoteQty.String())
+	assert.Equal(t, "101.5", impact.VwapPrice.String())
+	assert.Equal(t, "102", impact.WorstPrice.String())
+	assert.Equal(t, 2, impact.LevelsConsumed)
+	assert.InDelta(t, 150.0, impact.SlippageBps, 1e-9)
+	assert.False(t, impact.InsufficientLiquidity)
+}
+
+func TestEstimateImpact_Sell(t *testing.T) {
+	impact, err := EstimateImpact(impactBook(), "sell", decimal.NewFromInt(1))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "99", impact.VwapPrice.String())
+	assert.Equal(t, "99", impact.WorstPrice.String())
+	assert.InDelta(t, 100.0, impact.SlippageBps, 1e-9)
+}
+
+func TestEstimateImpact_InsufficientLiquidity(t *testing.T) {
+	impact, err := EstimateImpact(impactBook(), "sell", decimal.NewFromInt(5))
+	assert.NoError(t, err)
+
+	assert.True(t, impact.InsufficientLiquidity)
+	assert.Equal(t, "3", impact.FilledQty.String())
+	assert.Equal(t, "98", impact.WorstPrice.String())
+}
+
+func TestEstimateImpact_InvalidSide(t *testing.T) {
+	_, err := EstimateImpact(impactBook(), "hold", decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, ErrInvalidSide)
+}
@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrOneSidedBook is returned when metrics need both sides of the book but one of them is empty.
+var ErrOneSidedBook = errors.New("order book has no bids or no asks")
+
+var (
+	half       = decimal.RequireFromString("0.5")
+	bpsPerUnit = decimal.NewFromInt(10000)
+)
+
+// DepthBand is the cumulative quantity resting within Bps basis points of the mid price.
+type DepthBand struct {
+	Bps         float64         `json:"bps"`
+	BidQty      decimal.Decimal `json:"bidQty" swaggertype:"number"`
+	AskQty      decimal.Decimal `json:"askQty" swaggertype:"number"`
+	BidQuoteQty decimal.Decimal `json:"bidQuoteQty" swaggertype:"number"`
+	AskQuoteQty decimal.Decimal `json:"askQuoteQty" swaggertype:"number"`
+}
+
+// OrderBookMetrics are the top of book and depth statistics of an order book snapshot.
+type OrderBookMetrics struct {
+	Exchange     string          `json:"exchange"`
+	Pair         string          `json:"pair"`
+	ExchangeTime time.Time       `json:"exchangeTime"`
+	Sequence     int64           `json:"sequence"`
+	BestBid      decimal.Decimal `json:"bestBid" swaggertype:"number"`
+	BestBidQty   decimal.Decimal `json:"bestBidQty" swaggertype:"number"`
+	BestAsk      decimal.Decimal `json:"bestAsk" swaggertype:"number"`
+	BestAskQty   decimal.Decimal `json:"bestAskQty" swaggertype:"number"`
+	Mid          decimal.Decimal `json:"mid" swaggertype:"number"`
+	Spread       decimal.Decimal `json:"spread" swaggertype:"number"`
+	SpreadBps    float64         `json:"spreadBps"`
+	Microprice   decimal.Decimal `json:"microprice" swaggertype:"number"`
+	Levels       int             `json:"levels"`
+	Imbalance    float64         `json:"imbalance"`
+	Depth        []DepthBand     `json:"depth"`
+}
+
+/*
+ComputeMetrics calculates top of book metrics for an order book snapshot.
+Imbalance is (bid qty - ask qty) / (bid qty + ask qty) over the top levels of each side,
+Depth holds the cumulative quantity within each of the bands given in basis points from the mid.
+Returns ErrOneSidedBook if the book has no bids or no asks.
+*/
+func ComputeMetrics(book *models.OrderBookDTO, levels int, bands []float64) (*OrderBookMetrics, error) {
+	asks := SortedLevels(book.Asks, false)
+	bids := SortedLevels(book.Bids, true)
+	if len(asks) == 0 || len(bids) == 0 {
+		return nil, ErrOneSidedBook
+	}
+
+	bestBid, bestAsk := bids[0], asks[0]
+	mid := Mid(bestBid, bestAsk)
+	spread := bestAsk.Price.Sub(bestBid.Price)
+
+	metrics := &OrderBookMetrics{
+		Exchange:     book.Exchange,
+		Pair:         book.Pair,
+		ExchangeTime: book.ExchangeTime,
+		Sequence:     book.Sequence,
+		BestBid:      bestBid.Price,
+		BestBidQty:   bestBid.BaseQty,
+		BestAsk:      bestAsk.Price,
+		BestAskQty:   bestAsk.BaseQty,
+		Mid:          mid,
+		Spread:       spread,
+		SpreadBps:    Bps(spread, mid),
+		Microprice:   Microprice(bestBid, bestAsk),
+		Levels:       levels,
+		Imbalance:    Imbalance(bids, asks, levels),
+		Depth:        make([]DepthBand, 0, len(bands)),
+	}
+
+	for _, bps := range bands {
+		offset := mid.Mul(decimal.NewFromFloat(bps)).Div(bpsPerUnit)
+		band := DepthBand{Bps: bps}
+		band.BidQty, band.BidQuoteQty = depthWithin(bids, mid.Sub(offset), true)
+		band.AskQty, band.AskQuoteQty = depthWithin(asks, mid.Add(offset), false)
+		metrics.Depth = append(metrics.Depth, band)
+	}
+
+	return metrics, nil
+}
+
+// Mid is the price halfway between the best bid and the best ask.
+func Mid(bestBid, bestAsk *models.DepthOrder) decimal.Decimal {
+	return bestBid.Price.Add(bestAsk.Price).Mul(half)
+}
+
+// Bps expresses value in basis points of base.
+func Bps(value, base decimal.Decimal) float64 {
+	if base.IsZero() {
+		return 0
+	}
+	return value.Mul(bpsPerUnit).Div(base).InexactFloat64()
+}
+
+/*
+Microprice is the mid price weighted by the opposite side quantity at the top of book,
+leaning towards the side with less resting quantity.
+*/
+func Microprice(bestBid, bestAsk *models.DepthOrder) decimal.Decimal {
+	total := bestBid.BaseQty.Add(bestAsk.BaseQty)
+	if total.IsZero() {
+		return Mid(bestBid, bestAsk)
+	}
+	return bestBid.Price.Mul(bestAsk.BaseQty).Add(bestAsk.Price.Mul(bestBid.BaseQty)).Div(total)
+}
+
+/*
+Imbalance compares resting quantity over the top levels of sorted bids and asks.
+Returns a value between -1 (only asks) and 1 (only bids), all levels are used if levels is not positive.
+*/
+func Imbalance(bids, asks []*models.DepthOrder, levels int) float64 {
+	bidQty := sumQty(bids, levels)
+	askQty := sumQty(asks, levels)
+	total := bidQty.Add(askQty)
+	if total.IsZero() {
+		return 0
+	}
+	return bidQty.Sub(askQty).Div(total).InexactFloat64()
+}
+
+// SortedLevels returns a copy of levels sorted by price, best price first, skipping missing levels.
+func SortedLevels(levels []*models.DepthOrder, descending bool) []*models.DepthOrder {
+	sorted := make([]*models.DepthOrder, 0, len(levels))
+	for _, level := range levels {
+		if level != nil {
+			sorted = append(sorted, level)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].Price.GreaterThan(sorted[j].Price)
+		}
+		return sorted[i].Price.LessThan(sorted[j].Price)
+	})
+	return sorted
+}
+
+func sumQty(levels []*models.DepthOrder, n int) decimal.Decimal {
+	if n <= 0 || n > len(levels) {
+		n = len(levels)
+	}
+
+	qty := decimal.Zero
+	for _, level := range levels[:n] {
+		qty = qty.Add(level.BaseQty)
+	}
+	return qty
+}
+
+// depthWithin sums base and quote quantity of sorted levels up to the limit price.
+func depthWithin(levels []*models.DepthOrder, limit decimal.Decimal, descending bool) (decimal.Decimal, decimal.Decimal) {
+	qty, quoteQty := decimal.Zero, decimal.Zero
+	for _, level := range levels {
+		if (descending && level.Price.LessThan(limit)) || (!descending && level.Price.GreaterThan(limit)) {
+			break
+		}
+		qty = qty.Add(level.BaseQty)
+		quoteQty = quoteQty.Add(level.BaseQty.Mul(level.Price))
+	}
+	return qty, quoteQty
+}
@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func level(price, qty string) *models.DepthOrder {
+	return &models.DepthOrder{Price: decimal.RequireFromString(price), BaseQty: decimal.RequireFromString(qty)}
+}
+
+func TestComputeMetrics(t *testing.T) {
+	book := &models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     []*models.DepthOrder{level("100.5", "1"), level("100.2", "3"), level("101", "5")},
+		Bids:     []*models.DepthOrder{level("99.8", "1"), level("99.5", "2"), level("98", "10")},
+	}
+
+	metrics, err := ComputeMetrics(book, 2, []float64{60, 1000})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "99.8", metrics.BestBid.String())
+	assert.Equal(t, "100.2", metrics.BestAsk.String())
+	assert.Equal(t, "100", metrics.Mid.String())
+	assert.Equal(t, "0.4", metrics.Spread.String())
+	assert.InDelta(t, 40.0, metrics.SpreadBps, 1e-9)
+	// More resting on the ask pushes the microprice towards the bid
+	assert.Equal(t, "99.9", metrics.Microprice.String())
+	// Top 2 levels: bids 3, asks 4
+	assert.InDelta(t, -1.0/7, metrics.Imbalance, 1e-9)
+
+	assert.Len(t, metrics.Depth, 2)
+	assert.Equal(t, "3", metrics.Depth[0].BidQty.String())
+	assert.Equal(t, "4", metrics.Depth[0].AskQty.String())
+	assert.Equal(t, "298.8", metrics.Depth[0].BidQuoteQty.String())
+	assert.Equal(t, "13", metrics.Depth[1].BidQty.String())
+	assert.Equal(t, "9", metrics.Depth[1].AskQty.String())
+}
+
+func TestComputeMetrics_OneSidedBook(t *testing.T) {
+	book := &models.OrderBookDTO{
+		Asks: []*models.DepthOrder{level("100", "1")},
+	}
+
+	_, err := ComputeMetrics(book, 5, nil)
+	assert.ErrorIs(t, err, ErrOneSidedBook)
+}
+
+func TestImbalance_AllLevels(t *testing.T) {
+	bids := []*models.DepthOrder{level("99", "1"), level("98", "1")}
+	asks := []*models.DepthOrder{level("101", "2")}
+
+	assert.Equal(t, 0.0, Imbalance(bids, asks, 0))
+}
@@ -0,0 +1,136 @@
+package analytics
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Methods of matching closing trades to the trades that opened a position.
+const (
+	PnLMethodFIFO        = "fifo"
+	PnLMethodAverageCost = "average"
+)
+
+// ErrInvalidPnLMethod is returned for a PnL method other than fifo or average.
+var ErrInvalidPnLMethod = errors.New("pnl method must be fifo or average")
+
+// Trade is an execution replayed by the PnL engine, Commission is in the quote asset.
+type Trade struct {
+	Side       string
+	BaseQty    decimal.Decimal
+	Price      decimal.Decimal
+	Commission decimal.Decimal
+}
+
+/*
+PnL is the profit and loss of a position built from a sequence of trades, in the quote asset.
+Position is positive for a long and negative for a short position.
+MarkPrice is only set once the open position was valued, NetPnL is realized plus unrealized PnL minus commission.
+SkippedOrders lists the orders left out of the replay because their side is neither buy nor sell.
+*/
+type PnL struct {
+	Exchange      string           `json:"exchange"`
+	Label         string           `json:"label"`
+	Pair          string           `json:"pair"`
+	Method        string           `json:"method"`
+	Trades        int              `json:"trades"`
+	Position      decimal.Decimal  `json:"position" swaggertype:"number"`
+	AvgEntryPrice decimal.Decimal  `json:"avgEntryPrice" swaggertype:"number"`
+	RealizedPnL   decimal.Decimal  `json:"realizedPnl" swaggertype:"number"`
+	Commission    decimal.Decimal  `json:"commission" swaggertype:"number"`
+	MarkPrice     *decimal.Decimal `json:"markPrice,omitempty" swaggertype:"number"`
+	UnrealizedPnL decimal.Decimal  `json:"unrealizedPnl" swaggertype:"number"`
+	NetPnL        decimal.Decimal  `json:"netPnl" swaggertype:"number"`
+	SkippedOrders []string         `json:"skippedOrders,omitempty"`
+}
+
+// lot is an open part of the position, qty is negative for a short lot.
+type lot struct {
+	qty   decimal.Decimal
+	price decimal.Decimal
+}
+
+/*
+ComputePnL replays trades in order and returns the realized PnL and the open position.
+With fifo, closing trades are matched against the oldest open lots first,
+with average, the open position is a single lot at the average price of the trades that built it.
+A trade larger than the open position closes it and opens a position on the other side.
+Returns ErrInvalidPnLMethod for an unknown method and ErrInvalidSide for a trade that is not a buy or sell.
+*/
+func ComputePnL(trades []Trade, method string) (*PnL, error) {
+	method = strings.ToLower(method)
+	if method != PnLMethodFIFO && method != PnLMethodAverageCost {
+		return nil, ErrInvalidPnLMethod
+	}
+
+	pnl := &PnL{Method: method}
+	var lots []lot
+
+	for _, trade := range trades {
+		var qty decimal.Decimal
+		switch strings.ToLower(trade.Side) {
+		case SideBuy:
+			qty = trade.BaseQty
+		case SideSell:
+			qty = trade.BaseQty.Neg()
+		default:
+			return nil, ErrInvalidSide
+		}
+
+		pnl.Trades++
+		pnl.Commission = pnl.Commission.Add(trade.Commission)
+		lots = pnl.apply(lots, qty, trade.Price, method == PnLMethodAverageCost)
+	}
+
+	for _, l := range lots {
+		pnl.Position = pnl.Position.Add(l.qty)
+		pnl.AvgEntryPrice = pnl.AvgEntryPrice.Add(l.qty.Mul(l.price))
+	}
+	if !pnl.Position.IsZero() {
+		pnl.AvgEntryPrice = pnl.AvgEntryPrice.Div(pnl.Position)
+	}
+	pnl.NetPnL = pnl.RealizedPnL.Sub(pnl.Commission)
+
+	return pnl, nil
+}
+
+// Mark values the open position at price, setting unrealized and net PnL.
+func (p *PnL) Mark(price decimal.Decimal) {
+	p.MarkPrice = &price
+	p.UnrealizedPnL = price.Sub(p.AvgEntryPrice).Mul(p.Position)
+	p.NetPnL = p.RealizedPnL.Add(p.UnrealizedPnL).Sub(p.Commission)
+}
+
+/*
+apply closes open lots on the other side of a trade of signed qty, oldest first, realizing their PnL.
+What remains of the trade opens a new lot, or is merged into the open lot at the average price if average is set.
+*/
+func (p *PnL) apply(lots []lot, qty, price decimal.Decimal, average bool) []lot {
+	remaining := qty
+	for len(lots) > 0 && !remaining.IsZero() && lots[0].qty.Sign() != remaining.Sign() {
+		direction := decimal.NewFromInt(int64(lots[0].qty.Sign()))
+		closed := decimal.Min(lots[0].qty.Abs(), remaining.Abs()).Mul(direction)
+
+		p.RealizedPnL = p.RealizedPnL.Add(price.Sub(lots[0].price).Mul(closed))
+		lots[0].qty = lots[0].qty.Sub(closed)
+		remaining = remaining.Add(closed)
+		if lots[0].qty.IsZero() {
+			lots = lots[1:]
+		}
+	}
+
+	if remaining.IsZero() {
+		return lots
+	}
+
+	if average && len(lots) == 1 {
+		total := lots[0].qty.Add(remaining)
+		lots[0].price = lots[0].qty.Mul(lots[0].price).Add(remaining.Mul(price)).Div(total)
+		lots[0].qty = total
+		return lots
+	}
+
+	return append(lots, lot{qty: remaining, price: price})
+}
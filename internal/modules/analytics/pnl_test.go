@@ -0,0 +1,86 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func trade(side, qty, price, commission string) Trade {
+	return Trade{
+		Side:       side,
+		BaseQty:    decimal.RequireFromString(qty),
+		Price:      decimal.RequireFromString(price),
+		Commission: decimal.RequireFromString(commission),
+	}
+}
+
+func pnlTrades() []Trade {
+	return []Trade{
+		trade("buy", "1", "100", "0.1"),
+		trade("buy", "1", "110", "0.1"),
+		trade("sell", "1", "120", "0.1"),
+	}
+}
+
+func TestComputePnL_FIFO(t *testing.T) {
+	pnl, err := ComputePnL(pnlTrades(), "FIFO")
+	assert.NoError(t, err)
+
+	assert.Equal(t, PnLMethodFIFO, pnl.Method)
+	assert.Equal(t, 3, pnl.Trades)
+	// The sale closes the lot bought at 100
+	assert.Equal(t, "20", pnl.RealizedPnL.String())
+	assert.Equal(t, "1", pnl.Position.String())
+	assert.Equal(t, "110", pnl.AvgEntryPrice.String())
+	assert.Equal(t, "0.3", pnl.Commission.String())
+	assert.Equal(t, "19.7", pnl.NetPnL.String())
+	assert.Nil(t, pnl.MarkPrice)
+
+	pnl.Mark(decimal.NewFromInt(105))
+	assert.Equal(t, "-5", pnl.UnrealizedPnL.String())
+	assert.Equal(t, "14.7", pnl.NetPnL.String())
+}
+
+func TestComputePnL_AverageCost(t *testing.T) {
+	pnl, err := ComputePnL(pnlTrades(), PnLMethodAverageCost)
+	assert.NoError(t, err)
+
+	// The sale closes against the average entry of 105
+	assert.Equal(t, "15", pnl.RealizedPnL.String())
+	assert.Equal(t, "1", pnl.Position.String())
+	assert.Equal(t, "105", pnl.AvgEntryPrice.String())
+
+	pnl.Mark(decimal.NewFromInt(105))
+	assert.Equal(t, "0", pnl.UnrealizedPnL.String())
+}
+
+func TestComputePnL_FlipToShort(t *testing.T) {
+	trades := []Trade{
+		trade("buy", "1", "100", "0"),
+		trade("sell", "3", "110", "0"),
+		trade("buy", "1", "90", "0"),
+	}
+
+	for _, method := range []string{PnLMethodFIFO, PnLMethodAverageCost} {
+		pnl, err := ComputePnL(trades, method)
+		assert.NoError(t, err)
+
+		// 10 on the long, 20 covering one unit of the short opened at 110
+		assert.Equal(t, "30", pnl.RealizedPnL.String(), method)
+		assert.Equal(t, "-1", pnl.Position.String(), method)
+		assert.Equal(t, "110", pnl.AvgEntryPrice.String(), method)
+
+		pnl.Mark(decimal.NewFromInt(100))
+		assert.Equal(t, "10", pnl.UnrealizedPnL.String(), method)
+	}
+}
+
+func TestComputePnL_Errors(t *testing.T) {
+	_, err := ComputePnL(pnlTrades(), "lifo")
+	assert.ErrorIs(t, err, ErrInvalidPnLMethod)
+
+	_, err = ComputePnL([]Trade{trade("hold", "1", "100", "0")}, PnLMethodFIFO)
+	assert.ErrorIs(t, err, ErrInvalidSide)
+}
@@ -0,0 +1,427 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Formats of the format parameter, json is the regular response of an endpoint and the others stream an export
+const (
+	formatJSON    = "json"
+	formatCSV     = "csv"
+	formatNDJSON  = "ndjson"
+	formatParquet = "parquet"
+)
+
+var exportContentTypes = map[string]string{
+	formatCSV:     "text/csv",
+	formatNDJSON:  "application/x-ndjson",
+	formatParquet: "application/vnd.apache.parquet",
+}
+
+// Number of rows of the Parquet row groups buffered before they are written, bounds the memory an export uses
+const parquetRowGroupRows = 10000
+
+// exportWriter encodes the rows of an export to the response in one of the export formats.
+type exportWriter[T any] interface {
+	Write(row T) error
+	Close() error
+}
+
+type ndjsonExportWriter[T any] struct {
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonExportWriter[T]) Write(row T) error {
+	return nw.encoder.Encode(row)
+}
+
+func (nw *ndjsonExportWriter[T]) Close() error {
+	return nil
+}
+
+type csvExportWriter[T any] struct {
+	writer *csv.Writer
+	record func(T) []string
+}
+
+func newCSVExportWriter[T any](w io.Writer, header []string, record func(T) []string) (*csvExportWriter[T], error) {
+	cw := &csvExportWriter[T]{writer: csv.NewWriter(w), record: record}
+	return cw, cw.writer.Write(header)
+}
+
+func (cw *csvExportWriter[T]) Write(row T) error {
+	return cw.writer.Write(cw.record(row))
+}
+
+func (cw *csvExportWriter[T]) Close() error {
+	cw.writer.Flush()
+	return cw.writer.Error()
+}
+
+/*
+parquetExportWriter encodes rows as Parquet. The file is only started with the first row or on Close,
+so that errors before any row is exported can still be replied with a status.
+*/
+type parquetExportWriter[T, R any] struct {
+	w      io.Writer
+	writer *parquet.GenericWriter[R]
+	row    func(T) *R
+}
+
+func newParquetExportWriter[T, R any](w io.Writer, row func(T) *R) (*parquetExportWriter[T, R], error) {
+	return &parquetExportWriter[T, R]{w: w, row: row}, nil
+}
+
+func (pw *parquetExportWriter[T, R]) start() {
+	if pw.writer == nil {
+		pw.writer = parquet.NewGenericWriter[R](pw.w,
+			parquet.MaxRowsPerRowGroup(parquetRowGroupRows),
+			parquet.Compression(&parquet.Snappy))
+	}
+}
+
+func (pw *parquetExportWriter[T, R]) Write(row T) error {
+	pw.start()
+	_, err := pw.writer.Write([]R{*pw.row(row)})
+	return err
+}
+
+func (pw *parquetExportWriter[T, R]) Close() error {
+	pw.start()
+	return pw.writer.Close()
+}
+
+// historyCSVHeader lists the columns of order history CSV exports.
+var historyCSVHeader = []string{
+	"order_id", "client_order_id", "client_name", "exchange_name", "label", "pair", "side", "type",
+	"base_qty", "price", "algorithm_name_placed", "lowest_sell_prc", "highest_buy_prc", "commission_quote_qty",
+	"time_placed", "status", "updated_at",
+}
+
+func historyCSVRecord(order *models.HistoryOrder) []string {
+	return []string{
+		order.OrderID, order.ClientOrderID, order.ClientName, order.ExchangeName, order.Label, order.Pair, order.Side, order.Type,
+		order.BaseQty.String(), order.Price.String(), order.AlgorithmNamePlaced, order.LowestSellPrc.String(),
+		order.HighestBuyPrc.String(), order.CommissionQuoteQty.String(),
+		formatCSVTime(order.TimePlaced), order.Status, formatCSVTime(order.UpdatedAt),
+	}
+}
+
+// historyParquetRow is an order history row of Parquet exports, decimals are strings to keep full precision.
+type historyParquetRow struct {
+	OrderID             string `parquet:"order_id"`
+	ClientOrderID       string `parquet:"client_order_id"`
+	ClientName          string `parquet:"client_name"`
+	ExchangeName        string `parquet:"exchange_name"`
+	Label               string `parquet:"label"`
+	Pair                string `parquet:"pair"`
+	Side                string `parquet:"side"`
+	Type                string `parquet:"type"`
+	BaseQty             string `parquet:"base_qty"`
+	Price               string `parquet:"price"`
+	AlgorithmNamePlaced string `parquet:"algorithm_name_placed"`
+	LowestSellPrc       string `parquet:"lowest_sell_prc"`
+	HighestBuyPrc       string `parquet:"highest_buy_prc"`
+	CommissionQuoteQty  string `parquet:"commission_quote_qty"`
+	TimePlaced          int64  `parquet:"time_placed,timestamp(microsecond)"`
+	Status              string `parquet:"status"`
+	UpdatedAt           int64  `parquet:"updated_at,timestamp(microsecond)"`
+}
+
+func newHistoryParquetRow(order *models.HistoryOrder) *historyParquetRow {
+	return &historyParquetRow{
+		OrderID:             order.OrderID,
+		ClientOrderID:       order.ClientOrderID,
+		ClientName:          order.ClientName,
+		ExchangeName:        order.ExchangeName,
+		Label:               order.Label,
+		Pair:                order.Pair,
+		Side:                order.Side,
+		Type:                order.Type,
+		BaseQty:             order.BaseQty.String(),
+		Price:               order.Price.String(),
+		AlgorithmNamePlaced: order.AlgorithmNamePlaced,
+		LowestSellPrc:       order.LowestSellPrc.String(),
+		HighestBuyPrc:       order.HighestBuyPrc.String(),
+		CommissionQuoteQty:  order.CommissionQuoteQty.String(),
+		TimePlaced:          order.TimePlaced.UnixMicro(),
+		Status:              order.Status,
+		UpdatedAt:           order.UpdatedAt.UnixMicro(),
+	}
+}
+
+// newHistoryExportWriter creates the writer of an order history export in the format.
+func newHistoryExportWriter(format string, w io.Writer) (exportWriter[*models.HistoryOrder], error) {
+	switch format {
+	case formatCSV:
+		return newCSVExportWriter(w, historyCSVHeader, historyCSVRecord)
+	case formatParquet:
+		return newParquetExportWriter(w, newHistoryParquetRow)
+	default:
+		return &ndjsonExportWriter[*models.HistoryOrder]{encoder: json.NewEncoder(w)}, nil
+	}
+}
+
+// bookCSVHeader lists the columns of order book CSV exports, each ask and bid level flattened into a price and a quantity column.
+func bookCSVHeader(levels int) []string {
+	header := []string{"id", "exchange", "pair", "exchange_time", "received_time", "sequence"}
+	for _, side := range []string{"ask", "bid"} {
+		for i := 1; i <= levels; i++ {
+			header = append(header, fmt.Sprintf("%s_%d_price", side, i), fmt.Sprintf("%s_%d_qty", side, i))
+		}
+	}
+	return header
+}
+
+// bookCSVRecord flattens an order book into a CSV record of the first levels of each side, missing levels are empty.
+func bookCSVRecord(levels int) func(*models.OrderBookDTO) []string {
+	return func(order *models.OrderBookDTO) []string {
+		record := []string{
+			strconv.FormatInt(order.ID, 10), order.Exchange, order.Pair,
+			formatCSVTime(order.ExchangeTime), formatCSVTime(order.ReceivedTime), strconv.FormatInt(order.Sequence, 10),
+		}
+		for _, side := range [][]*models.DepthOrder{order.Asks, order.Bids} {
+			for i := 0; i < levels; i++ {
+				if i < len(side) {
+					record = append(record, side[i].Price.String(), side[i].BaseQty.String())
+				} else {
+					record = append(record, "", "")
+				}
+			}
+		}
+		return record
+	}
+}
+
+type levelParquetRow struct {
+	Price   string `parquet:"price"`
+	BaseQty string `parquet:"base_qty"`
+}
+
+// bookParquetRow is an order book row of Parquet exports with its levels as lists.
+type bookParquetRow struct {
+	ID           int64             `parquet:"id"`
+	Exchange     string            `parquet:"exchange"`
+	Pair         string            `parquet:"pair"`
+	Asks         []levelParquetRow `parquet:"asks,list"`
+	Bids         []levelParquetRow `parquet:"bids,list"`
+	ExchangeTime int64             `parquet:"exchange_time,timestamp(microsecond)"`
+	ReceivedTime int64             `parquet:"received_time,timestamp(microsecond)"`
+	Sequence     int64             `parquet:"sequence"`
+}
+
+func newBookParquetRow(order *models.OrderBookDTO) *bookParquetRow {
+	levels := func(orders []*models.DepthOrder) []levelParquetRow {
+		rows := make([]levelParquetRow, len(orders))
+		for i, order := range orders {
+			rows[i] = levelParquetRow{Price: order.Price.String(), BaseQty: order.BaseQty.String()}
+		}
+		return rows
+	}
+
+	return &bookParquetRow{
+		ID:           order.ID,
+		Exchange:     order.Exchange,
+		Pair:         order.Pair,
+		Asks:         levels(order.Asks),
+		Bids:         levels(order.Bids),
+		ExchangeTime: order.ExchangeTime.UnixMicro(),
+		ReceivedTime: order.ReceivedTime.UnixMicro(),
+		Sequence:     order.Sequence,
+	}
+}
+
+// newBookExportWriter creates the writer of an order book export in the format, CSV exports hold levels per side.
+func newBookExportWriter(format string, w io.Writer, levels int) (exportWriter[*models.OrderBookDTO], error) {
+	switch format {
+	case formatCSV:
+		return newCSVExportWriter(w, bookCSVHeader(levels), bookCSVRecord(levels))
+	case formatParquet:
+		return newParquetExportWriter(w, newBookParquetRow)
+	default:
+		return &ndjsonExportWriter[*models.OrderBookDTO]{encoder: json.NewEncoder(w)}, nil
+	}
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// exportResponse records whether any of an export has been written, after which its status can no longer change.
+type exportResponse struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (er *exportResponse) Write(b []byte) (int, error) {
+	er.written = true
+	return er.ResponseWriter.Write(b)
+}
+
+/*
+writeExport streams the rows passed by export to the response as the file name in the format, without buffering them.
+Errors before anything is written are replied with 500. Later errors abort the response,
+so that clients see an incomplete transfer instead of a truncated file.
+*/
+func writeExport[T any](w http.ResponseWriter, name, format string,
+	newWriter func(io.Writer) (exportWriter[T], error), export func(fn func(T) error) error) {
+	response := &exportResponse{ResponseWriter: w}
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	ew, err := newWriter(response)
+	if err == nil {
+		err = export(ew.Write)
+	}
+	if err == nil {
+		err = ew.Close()
+	}
+
+	if err != nil {
+		if response.written {
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Del("Content-Type")
+		w.Header().Del("Content-Disposition")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if !response.written {
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// exportOrderHistory streams the orders matching the filter in an export format.
+func (oci *orderControllerImpl) exportOrderHistory(w http.ResponseWriter, filter *models.OrderHistoryFilter, format string) {
+	if !validHistoryFilter(filter) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	newWriter := func(w io.Writer) (exportWriter[*models.HistoryOrder], error) {
+		return newHistoryExportWriter(format, w)
+	}
+	writeExport(w, "order-history", format, newWriter, func(fn func(*models.HistoryOrder) error) error {
+		return oci.service.ExportOrderHistory(filter, fn)
+	})
+}
+
+// exportOrderBooks streams the order books of an exchange and pair received between the from and to parameters in an export format.
+func (oci *orderControllerImpl) exportOrderBooks(w http.ResponseWriter, r *http.Request, format string) {
+	query := r.URL.Query()
+	exchangeName := query.Get("exchangeName")
+	pair := query.Get("pair")
+	if exchangeName == "" || pair == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	depth, tick, err := parseAggregation(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	from, fromErr := parseTime(query.Get("from"))
+	to, toErr := parseTime(query.Get("to"))
+	if errors.Join(fromErr, toErr) != nil || (!from.IsZero() && !to.IsZero() && !from.Before(to)) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// CSV exports have a column per level, depth bounds them so that no level is left out
+	if format == formatCSV && depth == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	newWriter := func(w io.Writer) (exportWriter[*models.OrderBookDTO], error) {
+		return newBookExportWriter(format, w, depth)
+	}
+	writeExport(w, "order-books", format, newWriter, func(fn func(*models.OrderBookDTO) error) error {
+		return oci.service.ExportOrderBooks(exchangeName, pair, from, to, depth, tick, fn)
+	})
+}
+
+// parseExportFormat reads the format parameter, which is empty for the regular JSON response and false if unknown.
+func parseExportFormat(query url.Values) (string, bool) {
+	format := query.Get("format")
+	if format == "" || format == formatJSON {
+		return "", true
+	}
+
+	_, ok := exportContentTypes[format]
+	return format, ok
+}
+
+// ExportHandler streams order history or order books as CSV, NDJSON or Parquet.
+//
+//	@Summary		Export order history or order books
+//	@Description	Streams every order of a client matching the order history filters (dataset history),
+//	@Description	or every order book of an exchange and pair received between from and to (dataset books),
+//	@Description	read from ClickHouse as the response is written so that large exports are not held in memory.
+//	@Description	Order history is not paged, limit and cursor are ignored.
+//	@Description	Order book CSV exports flatten the levels into ask_N_price, ask_N_qty, bid_N_price and bid_N_qty columns
+//	@Description	for the top depth levels of each side, depth is required. Parquet exports keep the levels as lists.
+//	@Description	Decimals are exported as strings in Parquet to keep full precision.
+//	@Tags			export
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		application/vnd.apache.parquet
+//	@Param			dataset				query		string	true	"Dataset"	Enums(history, books)
+//	@Param			format				query		string	true	"Export format"	Enums(csv, ndjson, parquet)
+//	@Param			clientName			query		string	false	"Client Name, required for history"
+//	@Param			exchangeName		query		string	false	"Exchange Name, required for books"
+//	@Param			pair				query		string	false	"Trading Pair, required for books"
+//	@Param			label				query		string	false	"Label"
+//	@Param			side				query		string	false	"Side"
+//	@Param			type				query		string	false	"Order Type"
+//	@Param			algorithmNamePlaced	query		string	false	"Algorithm"
+//	@Param			sort				query		string	false	"Sort order by placement time"	Enums(asc, desc)
+//	@Param			from				query		string	false	"Start of the time range (RFC 3339 or Unix milliseconds)"
+//	@Param			to					query		string	false	"End of the time range (RFC 3339 or Unix milliseconds)"
+//	@Param			depth				query		int		false	"Number of levels per side, required for books in CSV"
+//	@Param			tick				query		number	false	"Price bucket size"
+//	@Success		200					{file}		file
+//	@Failure		400					{string}	string	"Bad Request"
+//	@Failure		500					{string}	string	"Internal Server Error"
+//	@Router			/export [get]
+func (oci *orderControllerImpl) ExportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format, ok := parseExportFormat(query)
+	if !ok || format == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	switch query.Get("dataset") {
+	case "history":
+		filter, err := parseHistoryFilter(query)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		oci.exportOrderHistory(w, filter, format)
+	case "books":
+		oci.exportOrderBooks(w, r, format)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
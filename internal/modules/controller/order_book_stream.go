@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/modules/service"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Number of updates buffered per connection before it is closed as a slow consumer
+	streamBufferSize = 256
+	streamWriteWait  = 10 * time.Second
+	streamPongWait   = 60 * time.Second
+	streamPingPeriod = streamPongWait * 9 / 10
+)
+
+// Subscription message actions and reply types of the order book stream
+const (
+	streamActionSubscribe   = "subscribe"
+	streamActionUnsubscribe = "unsubscribe"
+	streamReplySubscribed   = "subscribed"
+	streamReplyUnsubscribed = "unsubscribed"
+	streamReplyError        = "error"
+)
+
+/*
+newStreamUpgrader creates the upgrader of order book streams accepting browser connections from allowedOrigins
+and from the server's own origin. Requests without an Origin header are not sent by browsers and are accepted.
+*/
+func newStreamUpgrader(allowedOrigins []string) *websocket.Upgrader {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return true
+			}
+
+			u, err := url.Parse(origin)
+			if err != nil || u.Host == "" {
+				return false
+			}
+			if strings.EqualFold(u.Host, r.Host) {
+				return true
+			}
+
+			_, ok := allowed[strings.ToLower(u.Scheme+"://"+u.Host)]
+			return ok
+		},
+	}
+}
+
+// streamRequest is a message sent by a client to subscribe to or unsubscribe from an exchange and pair.
+type streamRequest struct {
+	Action   string `json:"action"`
+	Exchange string `json:"exchange"`
+	Pair     string `json:"pair"`
+}
+
+// streamReply acknowledges a streamRequest or reports why it failed.
+type streamReply struct {
+	Type     string `json:"type"`
+	Exchange string `json:"exchange,omitempty"`
+	Pair     string `json:"pair,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// StreamOrderBookHandler streams order book updates over a WebSocket connection.
+//
+//	@Summary		Stream order book updates
+//	@Description	Upgrades to a WebSocket connection streaming the snapshots and deltas of subscribed exchanges and pairs.
+//	@Description	Subscribe with {"action":"subscribe","exchange":"...","pair":"..."} and unsubscribe with action unsubscribe,
+//	@Description	or pass subscribe=exchange:pair query parameters to resubscribe when reconnecting.
+//	@Description	Each subscription starts with the current snapshot of the book, followed by updates of type snapshot or delta.
+//	@Description	Connections that do not keep up with updates are closed with status 1008, and with status 1001 when the server shuts down.
+//	@Description	Browser connections are only accepted from the server's origin and the origins allowed by WS_ALLOWED_ORIGINS.
+//	@Tags			orders
+//	@Param			subscribe	query		[]string	false	"Exchange and pair to subscribe to as exchange:pair"	collectionFormat(multi)
+//	@Success		101			{object}	models.OrderBookUpdate
+//	@Failure		400			{string}	string	"Bad Request"
+//	@Failure		403			{string}	string	"Forbidden"
+//	@Router			/ws/order/book [get]
+func (oci *orderControllerImpl) StreamOrderBookHandler(w http.ResponseWriter, r *http.Request) {
+	var initial []streamRequest
+	for _, value := range r.URL.Query()["subscribe"] {
+		exchangeName, pair, ok := strings.Cut(value, ":")
+		if !ok || exchangeName == "" || pair == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		initial = append(initial, streamRequest{Action: streamActionSubscribe, Exchange: exchangeName, Pair: pair})
+	}
+
+	conn, err := oci.streamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an error
+		return
+	}
+	defer conn.Close()
+
+	subscription := oci.service.SubscribeOrderBooks(streamBufferSize)
+	defer subscription.Close()
+
+	replies := make(chan *streamReply, len(initial)+1)
+	done := make(chan struct{})
+	defer close(done)
+
+	for _, request := range initial {
+		replies <- oci.handleStreamRequest(subscription, &request)
+	}
+
+	// Reads subscription messages until the connection fails, only this goroutine reads
+	readErr := make(chan error, 1)
+	go func() {
+		conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(streamPongWait))
+		})
+
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			reply := &streamReply{Type: streamReplyError, Error: "invalid message"}
+			var request streamRequest
+			if json.Unmarshal(message, &request) == nil {
+				reply = oci.handleStreamRequest(subscription, &request)
+			}
+
+			select {
+			case replies <- reply:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(streamPingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case update, ok := <-subscription.Updates():
+			if !ok {
+				if errors.Is(subscription.Err(), service.ErrSlowConsumer) {
+					message := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "slow consumer")
+					conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(streamWriteWait))
+				}
+				return
+			}
+			if writeStreamJSON(conn, update) != nil {
+				return
+			}
+		case reply := <-replies:
+			if writeStreamJSON(conn, reply) != nil {
+				return
+			}
+		case <-ticker.C:
+			if conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteWait)) != nil {
+				return
+			}
+		case <-readErr:
+			return
+		case <-oci.streams.Done():
+			message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+			conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(streamWriteWait))
+			return
+		}
+	}
+}
+
+// handleStreamRequest applies a subscription message and returns the reply to send.
+func (oci *orderControllerImpl) handleStreamRequest(subscription *service.OrderBookSubscription, request *streamRequest) *streamReply {
+	if request.Exchange == "" || request.Pair == "" {
+		return &streamReply{Type: streamReplyError, Error: "exchange and pair are required"}
+	}
+
+	switch request.Action {
+	case streamActionSubscribe:
+		if err := oci.service.JoinOrderBook(subscription, request.Exchange, request.Pair); err != nil {
+			return &streamReply{Type: streamReplyError, Exchange: request.Exchange, Pair: request.Pair, Error: "failed to load order book"}
+		}
+		return &streamReply{Type: streamReplySubscribed, Exchange: request.Exchange, Pair: request.Pair}
+	case streamActionUnsubscribe:
+		subscription.Leave(request.Exchange, request.Pair)
+		return &streamReply{Type: streamReplyUnsubscribed, Exchange: request.Exchange, Pair: request.Pair}
+	default:
+		return &streamReply{Type: streamReplyError, Exchange: request.Exchange, Pair: request.Pair, Error: "unknown action"}
+	}
+}
+
+func writeStreamJSON(conn *websocket.Conn, v any) error {
+	conn.SetWriteDeadline(time.Now().Add(streamWriteWait))
+	return conn.WriteJSON(v)
+}
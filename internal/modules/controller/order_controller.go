@@ -1,45 +1,166 @@
 package controller
 
 import (
+	"context"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
 	"github.com/kymaka/vortex-test/internal/modules/service"
 
+	"github.com/go-chi/chi"
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
 type OrderController interface {
 	GetOrderBookHandler(w http.ResponseWriter, r *http.Request)
+	GetLatestOrderBookHandler(w http.ResponseWriter, r *http.Request)
+	GetOrderBookMetricsHandler(w http.ResponseWriter, r *http.Request)
+	GetMarketImpactHandler(w http.ResponseWriter, r *http.Request)
+	GetConsolidatedOrderBookHandler(w http.ResponseWriter, r *http.Request)
 	SaveOrderBookHandler(w http.ResponseWriter, r *http.Request)
+	SaveOrderBookDeltaHandler(w http.ResponseWriter, r *http.Request)
+	SaveOrderBookBatchHandler(w http.ResponseWriter, r *http.Request)
+	StreamOrderBookHandler(w http.ResponseWriter, r *http.Request)
 	GetOrderHistoryHandler(w http.ResponseWriter, r *http.Request)
+	SearchOrderHistoryHandler(w http.ResponseWriter, r *http.Request)
+	StreamOrderHistoryHandler(w http.ResponseWriter, r *http.Request)
 	SaveOrderHandler(w http.ResponseWriter, r *http.Request)
+	SaveOrderBatchHandler(w http.ResponseWriter, r *http.Request)
+	UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Request)
+	SaveFillsHandler(w http.ResponseWriter, r *http.Request)
+	GetClientPnLHandler(w http.ResponseWriter, r *http.Request)
+	GetExecutionQualityHandler(w http.ResponseWriter, r *http.Request)
+	GetAlgorithmsHandler(w http.ResponseWriter, r *http.Request)
+	GetAlgorithmStatsHandler(w http.ResponseWriter, r *http.Request)
+	GetCandlesHandler(w http.ResponseWriter, r *http.Request)
+	GetFeeReportHandler(w http.ResponseWriter, r *http.Request)
+	GetPairPrecisionHandler(w http.ResponseWriter, r *http.Request)
+	SavePairPrecisionHandler(w http.ResponseWriter, r *http.Request)
+	ExportHandler(w http.ResponseWriter, r *http.Request)
+	CloseStreams()
 }
 
 type orderControllerImpl struct {
-	service service.OrderService
+	service        service.OrderService
+	streamUpgrader *websocket.Upgrader
+	streams        context.Context
+	closeStreams   context.CancelFunc
 }
 
-func NewOrderController(s service.OrderService) OrderController {
-	return &orderControllerImpl{service: s}
+// NewOrderController creates the controller, order book streams also accept browser connections from allowedOrigins.
+func NewOrderController(s service.OrderService, allowedOrigins ...string) OrderController {
+	streams, closeStreams := context.WithCancel(context.Background())
+	return &orderControllerImpl{
+		service:        s,
+		streamUpgrader: newStreamUpgrader(allowedOrigins),
+		streams:        streams,
+		closeStreams:   closeStreams,
+	}
+}
+
+/*
+CloseStreams ends the open order book and order history streams, and the ones opened after it, so that a server
+shutting down does not wait for them. WebSocket connections are closed with status 1001.
+*/
+func (oci *orderControllerImpl) CloseStreams() {
+	oci.closeStreams()
 }
 
 // GetOrderBookHandler retrieves the order books for a specific exchange and pair.
 //
 //	@Summary		Get order books
 //	@Description	Returns the order books for a given exchange and pair.
+//	@Description	Levels can be grouped into price buckets of size tick and truncated to the top depth levels.
+//	@Description	With format csv, ndjson or parquet, the order books received between from and to are streamed
+//	@Description	as an attachment, the same as GET /export with dataset books.
 //	@Tags			orders
 //	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		application/vnd.apache.parquet
 //	@Param			exchangeName	query		string	true	"Exchange Name"
 //	@Param			pair			query		string	true	"Trading Pair"
+//	@Param			depth			query		int		false	"Number of levels per side to return"
+//	@Param			tick			query		number	false	"Price increment to group levels by"
+//	@Param			format			query		string	false	"Response format"	Enums(json, csv, ndjson, parquet)	default(json)
+//	@Param			from			query		string	false	"Start of the export, RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to				query		string	false	"End of the export, RFC 3339 timestamp or Unix milliseconds"
 //	@Success		200				{array}		models.OrderBook
 //	@Failure		400				{string}	string	"Bad Request"
 //	@Failure		404				{string}	string	"Not Found"
 //	@Failure		500				{string}	string	"Internal Server Error"
 //	@Router			/order/book [get]
 func (oci *orderControllerImpl) GetOrderBookHandler(w http.ResponseWriter, r *http.Request) {
+	format, ok := parseExportFormat(r.URL.Query())
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if format != "" {
+		oci.exportOrderBooks(w, r, format)
+		return
+	}
+
+	exchangeName := r.URL.Query().Get("exchangeName")
+	pair := r.URL.Query().Get("pair")
+
+	if exchangeName == "" || pair == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	depth, tick, err := parseAggregation(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := oci.service.GetOrderBook(exchangeName, pair, depth, tick)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(order)
+	w.Write(bytes)
+
+}
+
+// GetLatestOrderBookHandler retrieves the order book in effect at a given time.
+//
+//	@Summary		Get latest order book
+//	@Description	Returns the most recent order book for a given exchange and pair.
+//	@Description	If asOf is set, returns the order book in effect at that instant.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			exchangeName	query		string	true	"Exchange Name"
+//	@Param			pair			query		string	true	"Trading Pair"
+//	@Param			asOf			query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Success		200				{object}	models.OrderBookDTO
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		404				{string}	string	"Not Found"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/order/book/latest [get]
+func (oci *orderControllerImpl) GetLatestOrderBookHandler(w http.ResponseWriter, r *http.Request) {
 	exchangeName := r.URL.Query().Get("exchangeName")
 	pair := r.URL.Query().Get("pair")
 
@@ -48,7 +169,13 @@ func (oci *orderControllerImpl) GetOrderBookHandler(w http.ResponseWriter, r *ht
 		return
 	}
 
-	order, err := oci.service.GetOrderBook(exchangeName, pair)
+	asOf, err := parseTime(r.URL.Query().Get("asOf"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := oci.service.GetLatestOrderBook(exchangeName, pair, asOf)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			w.WriteHeader(http.StatusNotFound)
@@ -63,19 +190,212 @@ func (oci *orderControllerImpl) GetOrderBookHandler(w http.ResponseWriter, r *ht
 	w.WriteHeader(http.StatusOK)
 	bytes, _ := json.Marshal(order)
 	w.Write(bytes)
+}
+
+// Default parameters of order book metrics.
+const (
+	defaultMetricsLevels = 5
+	defaultDepthBands    = "10,25,50,100"
+)
+
+// GetOrderBookMetricsHandler computes metrics of the order book in effect at a given time.
+//
+//	@Summary		Get order book metrics
+//	@Description	Returns best bid and ask, mid, spread, microprice, top levels imbalance
+//	@Description	and cumulative depth within bands around the mid for the latest order book,
+//	@Description	or the order book in effect at asOf.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			exchangeName	query		string	true	"Exchange Name"
+//	@Param			pair			query		string	true	"Trading Pair"
+//	@Param			asOf			query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			levels			query		int		false	"Number of top levels used for imbalance"	default(5)
+//	@Param			bands			query		string	false	"Comma separated depth bands in bps"		default(10,25,50,100)
+//	@Success		200				{object}	analytics.OrderBookMetrics
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		404				{string}	string	"Not Found"
+//	@Failure		422				{string}	string	"Unprocessable Entity"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/order/book/metrics [get]
+func (oci *orderControllerImpl) GetOrderBookMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	exchangeName := query.Get("exchangeName")
+	pair := query.Get("pair")
+
+	if exchangeName == "" || pair == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	asOf, err := parseTime(query.Get("asOf"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	levels := defaultMetricsLevels
+	if value := query.Get("levels"); value != "" {
+		levels, err = strconv.Atoi(value)
+		if err != nil || levels <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	bandsValue := query.Get("bands")
+	if bandsValue == "" {
+		bandsValue = defaultDepthBands
+	}
+	bands, err := parseFloats(bandsValue)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	metrics, err := oci.service.GetOrderBookMetrics(exchangeName, pair, asOf, levels, bands)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, analytics.ErrOneSidedBook) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(metrics)
+	w.Write(bytes)
+}
+
+// GetMarketImpactHandler estimates the cost of a market order against the latest order book.
+//
+//	@Summary		Estimate market impact
+//	@Description	Walks the latest order book to fill baseQty and returns the VWAP fill price, worst price,
+//	@Description	slippage versus mid in bps and whether the book has insufficient liquidity for the size.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			exchangeName	query		string	true	"Exchange Name"
+//	@Param			pair			query		string	true	"Trading Pair"
+//	@Param			side			query		string	true	"Order side"	Enums(buy, sell)
+//	@Param			baseQty			query		number	true	"Order size in base asset"
+//	@Success		200				{object}	analytics.MarketImpact
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		404				{string}	string	"Not Found"
+//	@Failure		422				{string}	string	"Unprocessable Entity"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/order/book/impact [get]
+func (oci *orderControllerImpl) GetMarketImpactHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	exchangeName := query.Get("exchangeName")
+	pair := query.Get("pair")
+	side := strings.ToLower(query.Get("side"))
+
+	if exchangeName == "" || pair == "" || (side != analytics.SideBuy && side != analytics.SideSell) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	baseQty, err := decimal.NewFromString(query.Get("baseQty"))
+	if err != nil || !baseQty.IsPositive() {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	impact, err := oci.service.EstimateMarketImpact(exchangeName, pair, side, baseQty)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, analytics.ErrOneSidedBook) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(impact)
+	w.Write(bytes)
+}
+
+// GetConsolidatedOrderBookHandler retrieves the order book of a pair aggregated across exchanges.
+//
+//	@Summary		Get consolidated order book
+//	@Description	Merges the latest order book of every exchange trading the pair, keeping per-level venue quantities.
+//	@Description	Includes the best bid and offer of each venue and cross-venue arbitrage
+//	@Description	where one venue's bid is above another venue's ask.
+//	@Description	Exchanges whose latest snapshot is older than maxAge are left out and listed as excluded.
+//	@Tags			orders
+//	@Produce		json
+//	@Param			pair	query		string	true	"Trading Pair"
+//	@Param			maxAge	query		string	false	"Maximum snapshot age as a Go duration"	default(30s)
+//	@Success		200		{object}	analytics.ConsolidatedOrderBook
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		404		{string}	string	"Not Found"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/order/book/consolidated [get]
+func (oci *orderControllerImpl) GetConsolidatedOrderBookHandler(w http.ResponseWriter, r *http.Request) {
+	pair := r.URL.Query().Get("pair")
+	if pair == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var maxAge time.Duration
+	if value := r.URL.Query().Get("maxAge"); value != "" {
+		var err error
+		maxAge, err = time.ParseDuration(value)
+		if err != nil || maxAge <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	book, err := oci.service.GetConsolidatedOrderBook(pair, maxAge)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(book)
+	w.Write(bytes)
 }
 
 // SaveOrderBookHandler saves the order book details.
 //
 //	@Summary		Save order book
-//	@Description	Saves the order book details for a given exchange and pair.
+//	@Description	Saves an order book snapshot for a given exchange and pair.
+//	@Description	ExchangeTime and Sequence identify the exchange update, ReceivedTime defaults to the time of saving.
+//	@Description	Asks must be sorted ascending and bids descending, with unique finite positive prices and quantities
+//	@Description	and the best bid below the best ask.
 //	@Tags			orders
 //	@Accept			json
-//	@Param			order	body		models.OrderBookDTO	true	"Order Book DTO"
-//	@Success		200		{string}	string				"OK"
-//	@Failure		400		{string}	string				"Bad Request"
-//	@Failure		500		{string}	string				"Internal Server Error"
+//	@Produce		json
+//	@Param			order	body		models.OrderBookDTO			true	"Order Book DTO"
+//	@Success		200		{string}	string						"OK"
+//	@Failure		400		{string}	string						"Bad Request"
+//	@Failure		422		{object}	service.ValidationError	"Unprocessable Entity"
+//	@Failure		500		{string}	string						"Internal Server Error"
+//	@Failure		503		{string}	string						"Service Unavailable"
 //	@Router			/order/book [post]
 func (oci *orderControllerImpl) SaveOrderBookHandler(w http.ResponseWriter, r *http.Request) {
 	var order models.OrderBookDTO
@@ -85,8 +405,23 @@ func (oci *orderControllerImpl) SaveOrderBookHandler(w http.ResponseWriter, r *h
 		return
 	}
 
-	err = oci.service.SaveOrderBook(order.ID, order.Exchange, order.Pair, order.Asks, order.Bids)
+	err = oci.service.SaveOrderBook(&order)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			bytes, _ := json.Marshal(validationErr)
+			w.Write(bytes)
+			return
+		}
+
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -94,30 +429,35 @@ func (oci *orderControllerImpl) SaveOrderBookHandler(w http.ResponseWriter, r *h
 	w.WriteHeader(http.StatusOK)
 }
 
-// GetOrderHistoryHandler retrieves the order history for a client.
+// SaveOrderBookBatchHandler saves many order books in one request.
 //
-//	@Summary		Get order history
-//	@Description	Returns the order history for a given client.
+//	@Summary		Save order books in bulk
+//	@Description	Saves a JSON array of order book snapshots, or an NDJSON stream of them with Content-Type application/x-ndjson,
+//	@Description	in a single insert. Every order book is validated as by POST /order/book,
+//	@Description	invalid ones are rejected with their violations and the others are saved.
 //	@Tags			orders
+//	@Accept			json
+//	@Accept			application/x-ndjson
 //	@Produce		json
-//	@Param			client	body		models.Client	true	"Client"
-//	@Success		200		{array}		models.HistoryOrder
+//	@Param			orders	body		[]models.OrderBookDTO	true	"Order Book DTOs"
+//	@Success		200		{object}	service.BatchResult
 //	@Failure		400		{string}	string	"Bad Request"
-//	@Failure		404		{string}	string	"Not Found"
+//	@Failure		413		{string}	string	"Request Entity Too Large"
 //	@Failure		500		{string}	string	"Internal Server Error"
-//	@Router			/order/history [get]
-func (oci *orderControllerImpl) GetOrderHistoryHandler(w http.ResponseWriter, r *http.Request) {
-	var client models.Client
-	err := json.NewDecoder(r.Body).Decode(&client)
+//	@Failure		503		{string}	string	"Service Unavailable"
+//	@Router			/order/book/batch [post]
+func (oci *orderControllerImpl) SaveOrderBookBatchHandler(w http.ResponseWriter, r *http.Request) {
+	orders, err := decodeBatch[models.OrderBookDTO](r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		w.WriteHeader(batchErrorStatus(err))
 		return
 	}
 
-	orders, err := oci.service.GetOrderHistory(&client)
+	result, err := oci.service.SaveOrderBooks(orders)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			w.WriteHeader(http.StatusNotFound)
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
 			return
 		}
 
@@ -127,34 +467,1017 @@ func (oci *orderControllerImpl) GetOrderHistoryHandler(w http.ResponseWriter, r
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	bytes, _ := json.Marshal(orders)
+	bytes, _ := json.Marshal(result)
 	w.Write(bytes)
 }
 
-// SaveOrderHandler saves an order for a client.
+// SaveOrderBookDeltaHandler applies an incremental order book update.
 //
-//	@Summary		Save order
-//	@Description	Saves an order for a given client.
+//	@Summary		Save order book delta
+//	@Description	Applies price level updates to the order book of a given exchange and pair.
+//	@Description	Levels with zero BaseQty are removed. Sequence must directly follow the last applied update,
+//	@Description	after a gap a new snapshot has to be saved before further deltas are accepted.
+//	@Description	Deltas leaving the book crossed, with non-positive levels or more precise than the pair are rejected.
 //	@Tags			orders
 //	@Accept			json
-//	@Param			payload	body		models.HistoryOrderPayload	true	"History Order Payload"
-//	@Success		200		{string}	string						"OK"
-//	@Failure		400		{string}	string						"Bad Request"
-//	@Failure		500		{string}	string						"Internal Server Error"
-//	@Router			/order/history [post]
-func (oci *orderControllerImpl) SaveOrderHandler(w http.ResponseWriter, r *http.Request) {
-	var payload models.HistoryOrderPayload
-	err := json.NewDecoder(r.Body).Decode(&payload)
-	if err != nil || payload.Client.ClientName == "" || payload.History.Type == "" {
+//	@Produce		json
+//	@Param			delta	body		models.OrderBookDelta	true	"Order Book Delta"
+//	@Success		200		{string}	string					"OK"
+//	@Failure		400		{string}	string					"Bad Request"
+//	@Failure		404		{string}	string					"Not Found"
+//	@Failure		409		{string}	string					"Conflict"
+//	@Failure		422		{object}	service.ValidationError	"Unprocessable Entity"
+//	@Failure		500		{string}	string					"Internal Server Error"
+//	@Failure		503		{string}	string					"Service Unavailable"
+//	@Router			/order/book/delta [post]
+func (oci *orderControllerImpl) SaveOrderBookDeltaHandler(w http.ResponseWriter, r *http.Request) {
+	var delta models.OrderBookDelta
+	err := json.NewDecoder(r.Body).Decode(&delta)
+	if err != nil || delta.Exchange == "" || delta.Pair == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	err = oci.service.SaveOrder(&payload.Client, &payload.History)
+	err = oci.service.SaveOrderBookDelta(&delta)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			bytes, _ := json.Marshal(validationErr)
+			w.Write(bytes)
+			return
+		}
+
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, service.ErrSequenceGap) || errors.Is(err, service.ErrOrderBookOutOfSync) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// GetOrderHistoryHandler retrieves the order history for a client.
+//
+//	@Summary		Get order history
+//	@Description	Returns the order history for a given client, with a summary of the fills of every order.
+//	@Description	Empty filters match every order, label and pair may contain * wildcards.
+//	@Description	Orders placed at or after from and before to are sorted by placement time, asc (default) or desc.
+//	@Description	Without limit and cursor, every matching order is returned as a JSON array.
+//	@Description	With limit or cursor, a models.OrderHistoryPage is returned instead,
+//	@Description	pass nextCursor of a page as cursor to get the next one, the last page has no nextCursor.
+//	@Description	A JSON filter in the request body is still accepted without query parameters, but deprecated
+//	@Description	in favour of POST /order/history/search and answered with a Deprecation header.
+//	@Description	With format csv, ndjson or parquet, every matching order is streamed as an attachment without paging,
+//	@Description	the same as GET /export with dataset history.
+//	@Tags			orders
+//	@Produce		json
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Produce		application/vnd.apache.parquet
+//	@Param			clientName			query		string	true	"Client Name"
+//	@Param			exchangeName		query		string	false	"Exchange Name"
+//	@Param			label				query		string	false	"Label, may contain * wildcards"
+//	@Param			pair				query		string	false	"Trading Pair, may contain * wildcards"
+//	@Param			from				query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to					query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			side				query		string	false	"Side"
+//	@Param			type				query		string	false	"Order Type"
+//	@Param			algorithmNamePlaced	query		string	false	"Algorithm Name"
+//	@Param			sort				query		string	false	"Sort order"	Enums(asc, desc)	default(asc)
+//	@Param			limit				query		int		false	"Orders per page, at most 1000"	default(100)
+//	@Param			cursor				query		string	false	"nextCursor of the previous page"
+//	@Param			format				query		string	false	"Response format"	Enums(json, csv, ndjson, parquet)	default(json)
+//	@Success		200					{array}		models.HistoryOrder
+//	@Header			200					{string}	Deprecation	"true if the filter was read from the request body"
+//	@Failure		400					{string}	string	"Bad Request"
+//	@Failure		404					{string}	string	"Not Found"
+//	@Failure		500					{string}	string	"Internal Server Error"
+//	@Router			/order/history [get]
+func (oci *orderControllerImpl) GetOrderHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var filter *models.OrderHistoryFilter
+	var err error
+
+	if len(r.URL.Query()) == 0 && r.ContentLength != 0 {
+		filter = &models.OrderHistoryFilter{}
+		err = json.NewDecoder(r.Body).Decode(filter)
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", `</order/history/search>; rel="successor-version"`)
+	} else {
+		filter, err = parseHistoryFilter(r.URL.Query())
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	format, ok := parseExportFormat(r.URL.Query())
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if format != "" {
+		oci.exportOrderHistory(w, filter, format)
+		return
+	}
+
+	if filter.Limit == 0 && filter.Cursor == "" {
+		oci.writeAllOrderHistory(w, filter)
+		return
+	}
+
+	oci.writeOrderHistory(w, filter)
+}
+
+// SearchOrderHistoryHandler retrieves the order history for a client matching a filter document.
+//
+//	@Summary		Search order history
+//	@Description	Returns a page of the order history matching the filter, with a summary of the fills of every order.
+//	@Description	Empty filter fields match every order, label and pair may contain * wildcards.
+//	@Description	Orders placed at or after from and before to are sorted by placement time, asc (default) or desc.
+//	@Description	Pass nextCursor of a page as cursor to get the next one, the last page has no nextCursor.
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			filter	body		models.OrderHistoryFilter	true	"Order History Filter"
+//	@Success		200		{object}	models.OrderHistoryPage
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		404		{string}	string	"Not Found"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/order/history/search [post]
+func (oci *orderControllerImpl) SearchOrderHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	var filter models.OrderHistoryFilter
+	err := json.NewDecoder(r.Body).Decode(&filter)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	oci.writeOrderHistory(w, &filter)
+}
+
+// writeOrderHistory writes the page of order history matching the filter.
+func (oci *orderControllerImpl) writeOrderHistory(w http.ResponseWriter, filter *models.OrderHistoryFilter) {
+	if !validHistoryFilter(filter) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	page, err := oci.service.GetOrderHistory(filter)
+	if err != nil {
+		writeOrderHistoryError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(page)
+	w.Write(bytes)
+}
+
+// writeAllOrderHistory writes every order matching the filter as an array,
+// the response of GET /order/history before it was paged.
+func (oci *orderControllerImpl) writeAllOrderHistory(w http.ResponseWriter, filter *models.OrderHistoryFilter) {
+	if !validHistoryFilter(filter) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	query := *filter
+	query.Limit = service.MaxHistoryLimit
+	orders := []*models.HistoryOrder{}
+	for {
+		page, err := oci.service.GetOrderHistory(&query)
+		if err != nil {
+			writeOrderHistoryError(w, err)
+			return
+		}
+
+		orders = append(orders, page.Orders...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(orders)
+	w.Write(bytes)
+}
+
+// writeOrderHistoryError writes the status of an error getting the order history.
+func writeOrderHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, service.ErrInvalidCursor) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// SaveOrderHandler saves an order for a client.
+//
+//	@Summary		Save order
+//	@Description	Saves an order for a given client and returns it.
+//	@Description	Orders without an orderId get a generated one, orders without a status are NEW.
+//	@Description	An orderId that already exists is rejected, the status of stored orders is changed through /order/history/{id}/status.
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			payload	body		models.HistoryOrderPayload	true	"History Order Payload"
+//	@Success		200		{object}	models.HistoryOrder
+//	@Failure		400		{string}	string					"Bad Request"
+//	@Failure		422		{object}	service.ValidationError	"Unprocessable Entity"
+//	@Failure		500		{string}	string					"Internal Server Error"
+//	@Failure		503		{string}	string					"Service Unavailable"
+//	@Router			/order/history [post]
+func (oci *orderControllerImpl) SaveOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var payload models.HistoryOrderPayload
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.Client.ClientName == "" || payload.History.Type == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := oci.service.SaveOrder(&payload.Client, &payload.History)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			bytes, _ := json.Marshal(validationErr)
+			w.Write(bytes)
+			return
+		}
+
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(order)
+	w.Write(bytes)
+}
+
+// SaveOrderBatchHandler saves many order history records in one request.
+//
+//	@Summary		Save order history in bulk
+//	@Description	Saves a JSON array of order history payloads, or an NDJSON stream of them with Content-Type application/x-ndjson,
+//	@Description	in a single insert. Every order is validated as by POST /order/history,
+//	@Description	invalid ones are rejected with their violations and the others are saved with their order ID reported.
+//	@Tags			history
+//	@Accept			json
+//	@Accept			application/x-ndjson
+//	@Produce		json
+//	@Param			orders	body		[]models.HistoryOrderPayload	true	"History Order Payloads"
+//	@Success		200		{object}	service.BatchResult
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		413		{string}	string	"Request Entity Too Large"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Failure		503		{string}	string	"Service Unavailable"
+//	@Router			/order/history/batch [post]
+func (oci *orderControllerImpl) SaveOrderBatchHandler(w http.ResponseWriter, r *http.Request) {
+	payloads, err := decodeBatch[models.HistoryOrderPayload](r)
+	if err != nil {
+		w.WriteHeader(batchErrorStatus(err))
+		return
+	}
+
+	result, err := oci.service.SaveOrders(payloads)
+	if err != nil {
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(result)
+	w.Write(bytes)
+}
+
+// UpdateOrderStatusHandler records a status transition of an order.
+//
+//	@Summary		Update order status
+//	@Description	Records a status transition of an order and returns its new state.
+//	@Description	NEW orders may become PARTIALLY_FILLED, FILLED, CANCELLED or REJECTED,
+//	@Description	PARTIALLY_FILLED orders may become PARTIALLY_FILLED, FILLED or CANCELLED. Other statuses are final.
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Order ID"
+//	@Param			update	body		models.OrderStatusUpdate	true	"Status Update"
+//	@Success		200		{object}	models.HistoryOrder
+//	@Failure		400		{string}	string					"Bad Request"
+//	@Failure		404		{string}	string					"Not Found"
+//	@Failure		409		{string}	string					"Conflict"
+//	@Failure		422		{object}	service.ValidationError	"Unprocessable Entity"
+//	@Failure		500		{string}	string					"Internal Server Error"
+//	@Failure		503		{string}	string					"Service Unavailable"
+//	@Router			/order/history/{id}/status [post]
+func (oci *orderControllerImpl) UpdateOrderStatusHandler(w http.ResponseWriter, r *http.Request) {
+	orderID := chi.URLParam(r, "id")
+
+	var update models.OrderStatusUpdate
+	err := json.NewDecoder(r.Body).Decode(&update)
+	if err != nil || orderID == "" || update.Status == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	order, err := oci.service.UpdateOrderStatus(orderID, &update)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, service.ErrInvalidStatusTransition) {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			bytes, _ := json.Marshal(validationErr)
+			w.Write(bytes)
+			return
+		}
+
+		if errors.Is(err, repository.ErrBufferFull) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(order)
+	w.Write(bytes)
+}
+
+// SaveFillsHandler saves executions of orders.
+//
+//	@Summary		Save fills
+//	@Description	Saves a batch of fills, each referencing a stored order.
+//	@Description	Fills already stored under the same fillId and orderId are ignored, so a batch can be sent again.
+//	@Tags			orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			fills	body		[]models.Fill			true	"Fills"
+//	@Success		200		{string}	string					"OK"
+//	@Failure		400		{string}	string					"Bad Request"
+//	@Failure		422		{object}	service.ValidationError	"Unprocessable Entity"
+//	@Failure		500		{string}	string					"Internal Server Error"
+//	@Router			/order/fills [post]
+func (oci *orderControllerImpl) SaveFillsHandler(w http.ResponseWriter, r *http.Request) {
+	var fills []*models.Fill
+	err := json.NewDecoder(r.Body).Decode(&fills)
+	if err != nil || len(fills) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = oci.service.SaveFills(fills)
+	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			bytes, _ := json.Marshal(validationErr)
+			w.Write(bytes)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetClientPnLHandler computes the profit and loss of a client.
+//
+//	@Summary		Get client PnL
+//	@Description	Replays the orders of a client and returns realized PnL, position, average entry price,
+//	@Description	commission and net PnL per exchange, label and pair, with fifo (default) or average cost matching.
+//	@Description	Orders with fills are replayed fill by fill, FILLED orders without fills as executed in full at their price.
+//	@Description	Orders with a side other than buy or sell are skipped and listed in skippedOrders.
+//	@Description	Open positions are marked to the mid of the latest order book for unrealized PnL.
+//	@Tags			pnl
+//	@Produce		json
+//	@Param			clientName		query		string	true	"Client Name"
+//	@Param			exchangeName	query		string	false	"Exchange Name"
+//	@Param			label			query		string	false	"Label, may contain * wildcards"
+//	@Param			pair			query		string	false	"Trading Pair, may contain * wildcards"
+//	@Param			from			query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to				query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			method			query		string	false	"Matching method"	Enums(fifo, average)	default(fifo)
+//	@Success		200				{array}		analytics.PnL
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		404				{string}	string	"Not Found"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/client/pnl [get]
+func (oci *orderControllerImpl) GetClientPnLHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHistoryFilter(r.URL.Query())
+	if err != nil || !validHistoryFilter(filter) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	method := r.URL.Query().Get("method")
+	if method == "" {
+		method = analytics.PnLMethodFIFO
+	}
+
+	pnl, err := oci.service.GetClientPnL(filter, method)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if errors.Is(err, analytics.ErrInvalidPnLMethod) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(pnl)
+	w.Write(bytes)
+}
+
+// GetExecutionQualityHandler reports the execution quality of the orders of a client.
+//
+//	@Summary		Get execution quality
+//	@Description	Compares the orders of a client with the best bid and ask captured at their placement.
+//	@Description	Returns per order the arrival mid, slippage in bps (negative is price improvement), whether the order crossed the spread
+//	@Description	and fee drag in bps, aggregated by the algorithm that placed them weighted by notional.
+//	@Description	Orders with fills are evaluated at their average fill price with the commission prorated by the filled quantity,
+//	@Description	FILLED orders without fills at their price, other orders without fills are skipped.
+//	@Tags			analytics
+//	@Produce		json
+//	@Param			clientName			query		string	true	"Client Name"
+//	@Param			exchangeName		query		string	false	"Exchange Name"
+//	@Param			label				query		string	false	"Label, may contain * wildcards"
+//	@Param			pair				query		string	false	"Trading Pair, may contain * wildcards"
+//	@Param			from				query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to					query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			side				query		string	false	"Side"
+//	@Param			type				query		string	false	"Order Type"
+//	@Param			algorithmNamePlaced	query		string	false	"Algorithm Name"
+//	@Success		200					{object}	analytics.ExecutionQualityReport
+//	@Failure		400					{string}	string	"Bad Request"
+//	@Failure		404					{string}	string	"Not Found"
+//	@Failure		500					{string}	string	"Internal Server Error"
+//	@Router			/order/execution-quality [get]
+func (oci *orderControllerImpl) GetExecutionQualityHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseHistoryFilter(r.URL.Query())
+	if err != nil || !validHistoryFilter(filter) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	report, err := oci.service.GetExecutionQuality(filter)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(report)
+	w.Write(bytes)
+}
+
+// GetAlgorithmsHandler lists the algorithms that placed orders.
+//
+//	@Summary		List algorithms
+//	@Description	Returns every algorithm that placed orders, with the number of orders it placed and when, ordered by name.
+//	@Tags			algorithms
+//	@Produce		json
+//	@Success		200	{array}		models.Algorithm
+//	@Failure		500	{string}	string	"Internal Server Error"
+//	@Router			/algorithms [get]
+func (oci *orderControllerImpl) GetAlgorithmsHandler(w http.ResponseWriter, r *http.Request) {
+	algorithms, err := oci.service.GetAlgorithms()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(algorithms)
+	w.Write(bytes)
+}
+
+// GetAlgorithmStatsHandler computes the performance of an algorithm.
+//
+//	@Summary		Get algorithm stats
+//	@Description	Returns order counts, base and quote volume, buy/sell ratio, commission, FIFO PnL and slippage
+//	@Description	of the orders an algorithm placed across all clients, optionally within a time window.
+//	@Tags			algorithms
+//	@Produce		json
+//	@Param			name	path		string	true	"Algorithm Name"
+//	@Param			from	query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to		query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Success		200		{object}	models.AlgorithmStats
+//	@Failure		400		{string}	string	"Bad Request"
+//	@Failure		404		{string}	string	"Not Found"
+//	@Failure		500		{string}	string	"Internal Server Error"
+//	@Router			/algorithms/{name}/stats [get]
+func (oci *orderControllerImpl) GetAlgorithmStatsHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseTime(r.URL.Query().Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(r.URL.Query().Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	stats, err := oci.service.GetAlgorithmStats(name, from, to)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(stats)
+	w.Write(bytes)
+}
+
+// GetCandlesHandler retrieves OHLCV candles of a pair.
+//
+//	@Summary		Get candles
+//	@Description	Returns OHLCV candles of the executions of a pair on an exchange, oldest first.
+//	@Description	Executions are the fills of orders, aggregated into minute candles as they are saved.
+//	@Description	Orders without fills are left out, even if FILLED.
+//	@Description	With mids set, OHLC candles of the mid price of the stored order books are returned as well.
+//	@Tags			candles
+//	@Produce		json
+//	@Param			exchangeName	query		string	true	"Exchange Name"
+//	@Param			pair			query		string	true	"Trading Pair"
+//	@Param			interval		query		string	true	"Candle interval"	Enums(1m, 5m, 1h, 1d)
+//	@Param			from			query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to				query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			mids			query		bool	false	"Include mid price candles"
+//	@Success		200				{object}	models.Candles
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/candles [get]
+func (oci *orderControllerImpl) GetCandlesHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	exchangeName := query.Get("exchangeName")
+	pair := query.Get("pair")
+	interval := query.Get("interval")
+
+	if _, ok := models.CandleIntervals[interval]; exchangeName == "" || pair == "" || !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	from, err := parseTime(query.Get("from"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	to, err := parseTime(query.Get("to"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	mids := false
+	if value := query.Get("mids"); value != "" {
+		if mids, err = strconv.ParseBool(value); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	candles, err := oci.service.GetCandles(exchangeName, pair, interval, from, to, mids)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(candles)
+	w.Write(bytes)
+}
+
+// GetFeeReportHandler reports commissions for reconciliation.
+//
+//	@Summary		Get fee report
+//	@Description	Groups the commissions of orders by UTC month (default) or day, client, exchange, pair and label,
+//	@Description	with the executed notional and effective fee rate in bps per row and in total.
+//	@Description	commissionQuoteQty of an order is its commission for the whole quantity, the executed share of it is charged:
+//	@Description	the quantity of its fills, or the whole order if it is FILLED without fills.
+//	@Description	With format csv, or an Accept header of text/csv, the rows are returned as a CSV attachment.
+//	@Tags			fees
+//	@Produce		json
+//	@Produce		text/csv
+//	@Param			clientName		query		string	false	"Client Name"
+//	@Param			exchangeName	query		string	false	"Exchange Name"
+//	@Param			label			query		string	false	"Label, may contain * wildcards"
+//	@Param			pair			query		string	false	"Trading Pair, may contain * wildcards"
+//	@Param			from			query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			to				query		string	false	"RFC 3339 timestamp or Unix milliseconds"
+//	@Param			period			query		string	false	"Period to group by"	Enums(day, month)	default(month)
+//	@Param			format			query		string	false	"Response format"		Enums(json, csv)	default(json)
+//	@Success		200				{object}	models.FeeReport
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/fees [get]
+func (oci *orderControllerImpl) GetFeeReportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.FeeReportFilter{
+		ClientName:   query.Get("clientName"),
+		ExchangeName: query.Get("exchangeName"),
+		Label:        query.Get("label"),
+		Pair:         query.Get("pair"),
+		Period:       query.Get("period"),
+	}
+
+	var err error
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if filter.Period != "" && filter.Period != models.FeePeriodDay && filter.Period != models.FeePeriodMonth {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" && strings.Contains(r.Header.Get("Accept"), "text/csv") {
+		format = "csv"
+	}
+	if format != "" && format != "json" && format != "csv" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	report, err := oci.service.GetFeeReport(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="fees.csv"`)
+		w.WriteHeader(http.StatusOK)
+		writeFeeReportCSV(w, report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(report)
+	w.Write(bytes)
+}
+
+// Highest number of decimal places of the Decimal(38, 18) columns prices and quantities are stored in.
+const maxDecimalScale = 18
+
+// GetPairPrecisionHandler retrieves the precision of a trading pair.
+//
+//	@Summary		Get pair precision
+//	@Description	Returns the number of decimal places prices and quantities of a pair are quoted with on an exchange.
+//	@Tags			pairs
+//	@Produce		json
+//	@Param			exchangeName	query		string	true	"Exchange Name"
+//	@Param			pair			query		string	true	"Trading Pair"
+//	@Success		200				{object}	models.PairPrecision
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		404				{string}	string	"Not Found"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/pair/precision [get]
+func (oci *orderControllerImpl) GetPairPrecisionHandler(w http.ResponseWriter, r *http.Request) {
+	exchangeName := r.URL.Query().Get("exchangeName")
+	pair := r.URL.Query().Get("pair")
+
+	if exchangeName == "" || pair == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	precision, err := oci.service.GetPairPrecision(exchangeName, pair)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	bytes, _ := json.Marshal(precision)
+	w.Write(bytes)
+}
+
+// SavePairPrecisionHandler saves the precision of a trading pair.
+//
+//	@Summary		Save pair precision
+//	@Description	Saves the number of decimal places prices and quantities of a pair are quoted with on an exchange.
+//	@Description	Order books and history orders with more decimal places are rejected. Scales range from 0 to 18.
+//	@Tags			pairs
+//	@Accept			json
+//	@Param			precision	body		models.PairPrecision	true	"Pair Precision"
+//	@Success		200			{string}	string					"OK"
+//	@Failure		400			{string}	string					"Bad Request"
+//	@Failure		500			{string}	string					"Internal Server Error"
+//	@Router			/pair/precision [post]
+func (oci *orderControllerImpl) SavePairPrecisionHandler(w http.ResponseWriter, r *http.Request) {
+	var precision models.PairPrecision
+	err := json.NewDecoder(r.Body).Decode(&precision)
+	if err != nil || precision.Exchange == "" || precision.Pair == "" ||
+		precision.PriceScale < 0 || precision.PriceScale > maxDecimalScale ||
+		precision.QtyScale < 0 || precision.QtyScale > maxDecimalScale {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = oci.service.SavePairPrecision(&precision)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// parseHistoryFilter reads an order history filter from query parameters.
+func parseHistoryFilter(query url.Values) (*models.OrderHistoryFilter, error) {
+	filter := &models.OrderHistoryFilter{
+		ClientName:          query.Get("clientName"),
+		ExchangeName:        query.Get("exchangeName"),
+		Label:               query.Get("label"),
+		Pair:                query.Get("pair"),
+		Side:                query.Get("side"),
+		Type:                query.Get("type"),
+		AlgorithmNamePlaced: query.Get("algorithmNamePlaced"),
+		Sort:                query.Get("sort"),
+		Cursor:              query.Get("cursor"),
+	}
+
+	var err error
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		return nil, err
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		return nil, err
+	}
+
+	if value := query.Get("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return filter, nil
+}
+
+// validHistoryFilter checks that an order history filter names a client and has a valid sort, limit and time range.
+func validHistoryFilter(filter *models.OrderHistoryFilter) bool {
+	if filter.ClientName == "" {
+		return false
+	}
+	if filter.Sort != "" && filter.Sort != models.SortAsc && filter.Sort != models.SortDesc {
+		return false
+	}
+	if filter.Limit < 0 || filter.Limit > service.MaxHistoryLimit {
+		return false
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return false
+	}
+	return true
+}
+
+/*
+parseTime parses a query parameter timestamp given either in RFC 3339 format or as Unix milliseconds.
+Returns zero time for an empty value.
+*/
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// errBatchTooLarge is returned by decodeBatch for batches of more than service.MaxBatchSize items.
+var errBatchTooLarge = errors.New("batch too large")
+
+/*
+decodeBatch decodes the items of a batch request body, an NDJSON stream if the Content-Type is application/x-ndjson
+and a JSON array otherwise. Returns an error for an empty or malformed body, or errBatchTooLarge.
+*/
+func decodeBatch[T any](r *http.Request) ([]*T, error) {
+	var items []*T
+	decoder := json.NewDecoder(r.Body)
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-ndjson") {
+		for decoder.More() {
+			if len(items) == service.MaxBatchSize {
+				return nil, errBatchTooLarge
+			}
+
+			var item T
+			if err := decoder.Decode(&item); err != nil {
+				return nil, err
+			}
+			items = append(items, &item)
+		}
+	} else {
+		if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+			return nil, errors.New("batch must be a JSON array")
+		}
+		for decoder.More() {
+			if len(items) == service.MaxBatchSize {
+				return nil, errBatchTooLarge
+			}
+
+			var item *T
+			if err := decoder.Decode(&item); err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("batch is empty")
+	}
+	return items, nil
+}
+
+// batchErrorStatus is the status code a batch that could not be decoded is answered with.
+func batchErrorStatus(err error) int {
+	if errors.Is(err, errBatchTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// writeFeeReportCSV writes the rows of a fee report as CSV with a header line, periods as dates or months.
+func writeFeeReportCSV(w http.ResponseWriter, report *models.FeeReport) {
+	layout := "2006-01"
+	if report.Period == models.FeePeriodDay {
+		layout = "2006-01-02"
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"period", "client_name", "exchange_name", "pair", "label", "orders", "notional", "commission", "fee_rate_bps"})
+	for _, row := range report.Rows {
+		writer.Write([]string{
+			row.PeriodStart.UTC().Format(layout),
+			row.ClientName,
+			row.ExchangeName,
+			row.Pair,
+			row.Label,
+			strconv.FormatUint(row.Orders, 10),
+			row.Notional.String(),
+			row.Commission.String(),
+			strconv.FormatFloat(row.FeeRateBps, 'f', 4, 64),
+		})
+	}
+	writer.Flush()
+}
+
+// parseFloats parses a comma separated list of finite non-negative numbers.
+func parseFloats(value string) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	floats := make([]float64, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) || f < 0 {
+			return nil, errors.New("value must be a finite non-negative number")
+		}
+		floats = append(floats, f)
+	}
+	return floats, nil
+}
+
+// parseAggregation reads the optional depth and tick query parameters of order book requests.
+func parseAggregation(r *http.Request) (int, decimal.Decimal, error) {
+	query := r.URL.Query()
+
+	var depth int
+	if value := query.Get("depth"); value != "" {
+		d, err := strconv.Atoi(value)
+		if err != nil || d < 0 {
+			return 0, decimal.Zero, errors.New("depth must be a non-negative integer")
+		}
+		depth = d
+	}
+
+	tick := decimal.Zero
+	if value := query.Get("tick"); value != "" {
+		t, err := decimal.NewFromString(value)
+		if err != nil || t.IsNegative() {
+			return 0, decimal.Zero, errors.New("tick must be a non-negative number")
+		}
+		tick = t
+	}
+
+	return depth, tick, nil
+}
@@ -1,22 +1,34 @@
 package controller
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/kymaka/vortex-test/internal/models"
-
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
+	"github.com/kymaka/vortex-test/internal/modules/service"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/websocket"
+	"github.com/parquet-go/parquet-go"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/gorm"
 )
 
 type MockOrderService struct{}
 
-func (m *MockOrderService) GetOrderBook(exchangeName, pair string) ([]*models.OrderBookDTO, error) {
+func (m *MockOrderService) GetOrderBook(exchangeName, pair string, depth int, tick decimal.Decimal) ([]*models.OrderBookDTO, error) {
 	if exchangeName == "invalid" || pair == "invalid" {
 		return nil, gorm.ErrRecordNotFound
 	}
@@ -25,38 +37,402 @@ func (m *MockOrderService) GetOrderBook(exchangeName, pair string) ([]*models.Or
 		return nil, gorm.ErrInvalidValue
 	}
 
+	asks := []*models.DepthOrder{}
+	if depth > 0 || tick.IsPositive() {
+		asks = append(asks, &models.DepthOrder{Price: tick, BaseQty: decimal.NewFromInt(int64(depth))})
+	}
+
 	return []*models.OrderBookDTO{
 		{
 			Exchange: exchangeName,
 			Pair:     pair,
-			Asks:     []*models.DepthOrder{},
+			Asks:     asks,
 			Bids:     []*models.DepthOrder{},
 		},
 	}, nil
 }
 
-func (m *MockOrderService) SaveOrderBook(id int64, exchange, pair string, asks, bids []*models.DepthOrder) error {
-	if exchange == "error" || pair == "error" {
+func (m *MockOrderService) GetLatestOrderBook(exchangeName, pair string, asOf time.Time) (*models.OrderBookDTO, error) {
+	if exchangeName == "invalid" || pair == "invalid" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if exchangeName == "error" {
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &models.OrderBookDTO{
+		Exchange:     exchangeName,
+		Pair:         pair,
+		Asks:         []*models.DepthOrder{},
+		Bids:         []*models.DepthOrder{},
+		ExchangeTime: asOf,
+	}, nil
+}
+
+func (m *MockOrderService) GetOrderBookMetrics(exchangeName, pair string, asOf time.Time, levels int, bands []float64) (*analytics.OrderBookMetrics, error) {
+	switch exchangeName {
+	case "invalid":
+		return nil, gorm.ErrRecordNotFound
+	case "empty":
+		return nil, analytics.ErrOneSidedBook
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	depth := make([]analytics.DepthBand, len(bands))
+	for i, bps := range bands {
+		depth[i] = analytics.DepthBand{Bps: bps}
+	}
+	return &analytics.OrderBookMetrics{Exchange: exchangeName, Pair: pair, Levels: levels, Depth: depth}, nil
+}
+
+func (m *MockOrderService) EstimateMarketImpact(exchangeName, pair, side string, baseQty decimal.Decimal) (*analytics.MarketImpact, error) {
+	switch exchangeName {
+	case "invalid":
+		return nil, gorm.ErrRecordNotFound
+	case "empty":
+		return nil, analytics.ErrOneSidedBook
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &analytics.MarketImpact{Exchange: exchangeName, Pair: pair, Side: side, BaseQty: baseQty}, nil
+}
+
+func (m *MockOrderService) GetConsolidatedOrderBook(pair string, maxAge time.Duration) (*analytics.ConsolidatedOrderBook, error) {
+	switch pair {
+	case "invalid":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &analytics.ConsolidatedOrderBook{Pair: pair, Exchanges: []string{"exchange_a", "exchange_b"}}, nil
+}
+
+func (m *MockOrderService) SaveOrderBook(order *models.OrderBookDTO) error {
+	if order.Exchange == "unprocessable" {
+		return &service.ValidationError{Violations: []service.Violation{{Field: "asks[0].price", Message: "invalid"}}}
+	}
+	if order.Exchange == "error" || order.Pair == "error" {
 		return errors.New("error saving order book")
 	}
 	return nil
 }
 
-func (m *MockOrderService) GetOrderHistory(client *models.Client) ([]*models.HistoryOrder, error) {
-	if client.ClientName == "notfound" {
-		return nil, gorm.ErrRecordNotFound
+func (m *MockOrderService) SaveOrderBookDelta(delta *models.OrderBookDelta) error {
+	switch delta.Exchange {
+	case "invalid":
+		return gorm.ErrRecordNotFound
+	case "gap":
+		return service.ErrSequenceGap
+	case "crossed":
+		return &service.ValidationError{Violations: []service.Violation{{Field: "bids[0].price", Message: "book is crossed"}}}
+	case "error":
+		return errors.New("error saving order book delta")
 	}
+	return nil
+}
 
-	if client.ClientName == "error" {
+func (m *MockOrderService) GetOrderHistory(filter *models.OrderHistoryFilter) (*models.OrderHistoryPage, error) {
+	switch filter.ClientName {
+	case "notfound":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
 		return nil, gorm.ErrInvalidValue
 	}
 
-	return []*models.HistoryOrder{}, nil
+	if filter.Cursor == "invalid" {
+		return nil, service.ErrInvalidCursor
+	}
+
+	page := &models.OrderHistoryPage{Orders: []*models.HistoryOrder{}}
+	if filter.Limit == 1 {
+		page.Orders = append(page.Orders, &models.HistoryOrder{OrderID: "order-1", Side: filter.Side})
+		page.NextCursor = "next"
+	}
+	if filter.ClientName == "paged" {
+		switch filter.Cursor {
+		case "":
+			page.Orders = append(page.Orders, &models.HistoryOrder{OrderID: "order-1"})
+			page.NextCursor = "page-2"
+		case "page-2":
+			page.Orders = append(page.Orders, &models.HistoryOrder{OrderID: "order-2"})
+		}
+	}
+	return page, nil
 }
 
-func (m *MockOrderService) SaveOrder(client *models.Client, history *models.HistoryOrder) error {
+func (m *MockOrderService) SaveOrder(client *models.Client, history *models.HistoryOrder) (*models.HistoryOrder, error) {
+	if history.Type == "unprocessable" {
+		return nil, &service.ValidationError{Violations: []service.Violation{{Field: "price", Message: "invalid"}}}
+	}
 	if client.ClientName == "error" || history.Type == "error" {
-		return errors.New("error saving order")
+		return nil, errors.New("error saving order")
+	}
+	if history.Type == "busy" {
+		return nil, repository.ErrBufferFull
+	}
+
+	order := *history
+	order.ClientName = client.ClientName
+	order.OrderID = "generated"
+	order.Status = models.OrderStatusNew
+	return &order, nil
+}
+
+func (m *MockOrderService) SaveOrderBooks(orders []*models.OrderBookDTO) (*service.BatchResult, error) {
+	result := &service.BatchResult{}
+	for i, order := range orders {
+		if order.Exchange == "error" {
+			return nil, errors.New("error saving order books")
+		}
+		if order.Exchange == "busy" {
+			return nil, repository.ErrBufferFull
+		}
+		result.Accepted++
+		result.Items = append(result.Items, service.BatchItemResult{Index: i, Accepted: true})
+	}
+	return result, nil
+}
+
+func (m *MockOrderService) SaveOrders(payloads []*models.HistoryOrderPayload) (*service.BatchResult, error) {
+	result := &service.BatchResult{}
+	for i, payload := range payloads {
+		if payload.Client.ClientName == "error" {
+			return nil, errors.New("error saving orders")
+		}
+		if payload.Client.ClientName == "busy" {
+			return nil, repository.ErrBufferFull
+		}
+		result.Accepted++
+		result.Items = append(result.Items, service.BatchItemResult{Index: i, Accepted: true, OrderID: payload.History.OrderID})
+	}
+	return result, nil
+}
+
+/*
+streamRepository backs the order book and order subscriptions of MockOrderService with a real service,
+test_exchange BTC/USD has a stored snapshot and other pairs have none, one order is missed after any event.
+*/
+type streamRepository struct {
+	repository.OrderRepository
+}
+
+func (r *streamRepository) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	if exchangeName == "test_exchange" && pair == "BTC/USD" {
+		return &models.OrderBook{Exchange: exchangeName, Pair: pair, Sequence: 10}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *streamRepository) FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *streamRepository) SaveOrder(order models.OrderBook) error {
+	return nil
+}
+
+func (r *streamRepository) SaveOrderHistory(order models.HistoryOrder) error {
+	return nil
+}
+
+func (r *streamRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *streamRepository) FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error) {
+	return []*models.HistoryOrder{{OrderID: "missed", ClientName: filter.ClientName, IngestedAt: after.IngestedAt.Add(time.Microsecond)}}, nil
+}
+
+var streamService = service.NewOrderService(&streamRepository{})
+
+func (m *MockOrderService) SubscribeOrderBooks(bufferSize int) *service.OrderBookSubscription {
+	return streamService.SubscribeOrderBooks(bufferSize)
+}
+
+func (m *MockOrderService) JoinOrderBook(subscription *service.OrderBookSubscription, exchangeName, pair string) error {
+	if exchangeName == "error" {
+		return errors.New("error loading order book")
+	}
+	return streamService.JoinOrderBook(subscription, exchangeName, pair)
+}
+
+func (m *MockOrderService) SubscribeOrders(filter *models.OrderStreamFilter, lastEventID string, bufferSize int) (*service.OrderSubscription, error) {
+	switch {
+	case lastEventID == "invalid":
+		return nil, service.ErrInvalidEventID
+	case filter.ClientName == "error":
+		return nil, errors.New("error subscribing to orders")
+	}
+	return streamService.SubscribeOrders(filter, lastEventID, bufferSize)
+}
+
+func (m *MockOrderService) UpdateOrderStatus(orderID string, update *models.OrderStatusUpdate) (*models.HistoryOrder, error) {
+	switch orderID {
+	case "invalid":
+		return nil, gorm.ErrRecordNotFound
+	case "final":
+		return nil, service.ErrInvalidStatusTransition
+	case "unprocessable":
+		return nil, &service.ValidationError{Violations: []service.Violation{{Field: "status", Message: "invalid"}}}
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &models.HistoryOrder{OrderID: orderID, Status: update.Status}, nil
+}
+
+func (m *MockOrderService) SaveFills(fills []*models.Fill) error {
+	switch fills[0].OrderID {
+	case "unprocessable":
+		return &service.ValidationError{Violations: []service.Violation{{Field: "fills[0].orderId", Message: "invalid"}}}
+	case "error":
+		return errors.New("error saving fills")
+	}
+	return nil
+}
+
+func (m *MockOrderService) GetClientPnL(filter *models.OrderHistoryFilter, method string) ([]*analytics.PnL, error) {
+	switch filter.ClientName {
+	case "notfound":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	if method != analytics.PnLMethodFIFO && method != analytics.PnLMethodAverageCost {
+		return nil, analytics.ErrInvalidPnLMethod
+	}
+
+	return []*analytics.PnL{{Pair: filter.Pair, Method: method}}, nil
+}
+
+func (m *MockOrderService) GetExecutionQuality(filter *models.OrderHistoryFilter) (*analytics.ExecutionQualityReport, error) {
+	switch filter.ClientName {
+	case "notfound":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &analytics.ExecutionQualityReport{
+		Orders:     []*analytics.OrderExecution{},
+		Algorithms: []*analytics.AlgorithmExecution{{Algorithm: filter.AlgorithmNamePlaced}},
+	}, nil
+}
+
+func (m *MockOrderService) GetAlgorithms() ([]*models.Algorithm, error) {
+	return []*models.Algorithm{{Name: "twap", Orders: 2}}, nil
+}
+
+func (m *MockOrderService) GetAlgorithmStats(name string, from, to time.Time) (*models.AlgorithmStats, error) {
+	switch name {
+	case "notfound":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &models.AlgorithmStats{Algorithm: name, AlgorithmVolume: models.AlgorithmVolume{Orders: 2}}, nil
+}
+
+func (m *MockOrderService) GetCandles(exchangeName, pair, interval string, from, to time.Time, mids bool) (*models.Candles, error) {
+	if exchangeName == "error" {
+		return nil, gorm.ErrInvalidValue
+	}
+
+	candles := &models.Candles{Exchange: exchangeName, Pair: pair, Interval: interval, Trades: []*models.Candle{{Trades: 1}}}
+	if mids {
+		candles.Mids = []*models.MidCandle{{Snapshots: 1}}
+	}
+	return candles, nil
+}
+
+func (m *MockOrderService) GetFeeReport(filter *models.FeeReportFilter) (*models.FeeReport, error) {
+	if filter.ClientName == "error" {
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &models.FeeReport{
+		Period: filter.Period,
+		Rows: []*models.FeeReportRow{{
+			PeriodStart:  time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			ClientName:   filter.ClientName,
+			ExchangeName: "test_exchange",
+			Pair:         "BTC/USD",
+			Label:        "label,with comma",
+			Orders:       2,
+			Notional:     decimal.NewFromInt(1000),
+			Commission:   decimal.NewFromInt(1),
+			FeeRateBps:   10,
+		}},
+	}, nil
+}
+
+func (m *MockOrderService) GetPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	switch exchangeName {
+	case "invalid":
+		return nil, gorm.ErrRecordNotFound
+	case "error":
+		return nil, gorm.ErrInvalidValue
+	}
+
+	return &models.PairPrecision{Exchange: exchangeName, Pair: pair, PriceScale: 2, QtyScale: 8}, nil
+}
+
+func (m *MockOrderService) Close() error {
+	return nil
+}
+
+func (m *MockOrderService) SavePairPrecision(precision *models.PairPrecision) error {
+	if precision.Exchange == "error" {
+		return errors.New("error saving pair precision")
+	}
+	return nil
+}
+
+func (m *MockOrderService) ExportOrderBooks(exchangeName, pair string, from, to time.Time, depth int, tick decimal.Decimal, fn func(*models.OrderBookDTO) error) error {
+	if exchangeName == "error" {
+		return errors.New("error exporting order books")
+	}
+
+	for i := int64(1); i <= 2; i++ {
+		err := fn(&models.OrderBookDTO{
+			ID:           i,
+			Exchange:     exchangeName,
+			Pair:         pair,
+			Asks:         []*models.DepthOrder{{Price: decimal.NewFromInt(100 + i), BaseQty: decimal.RequireFromString("0.5")}},
+			Bids:         []*models.DepthOrder{{Price: decimal.NewFromInt(99), BaseQty: decimal.NewFromInt(i)}, {Price: decimal.NewFromInt(98), BaseQty: decimal.NewFromInt(3)}},
+			ExchangeTime: time.Date(2024, 1, 1, 0, 0, int(i), 0, time.UTC),
+			Sequence:     i,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockOrderService) ExportOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error {
+	if filter.ClientName == "error" {
+		return errors.New("error exporting order history")
+	}
+
+	for _, id := range []string{"order-1", "order-2"} {
+		err := fn(&models.HistoryOrder{
+			OrderID:    id,
+			ClientName: filter.ClientName,
+			Side:       "buy",
+			Price:      decimal.RequireFromString("100.25"),
+			BaseQty:    decimal.NewFromInt(1),
+			TimePlaced: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -79,6 +455,41 @@ func TestGetOrderBookHandler(t *testing.T) {
 	assert.Equal(t, "ETH-BTC", orders[0].Pair)
 }
 
+func TestGetOrderBookHandler_Aggregation(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book?exchangeName=test&pair=ETH-BTC&depth=10&tick=0.5", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var orders []*models.OrderBookDTO
+	err := json.NewDecoder(rr.Body).Decode(&orders)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.5", orders[0].Asks[0].Price.String())
+	assert.Equal(t, "10", orders[0].Asks[0].BaseQty.String())
+}
+
+func TestGetOrderBookHandler_InvalidAggregation(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	for _, url := range []string{
+		"/order/book?exchangeName=test&pair=ETH-BTC&depth=-1",
+		"/order/book?exchangeName=test&pair=ETH-BTC&depth=ten",
+		"/order/book?exchangeName=test&pair=ETH-BTC&tick=-0.1",
+		"/order/book?exchangeName=test&pair=ETH-BTC&tick=0.1,0.2",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetOrderBookHandler(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
 func TestSaveOrderBookHandler(t *testing.T) {
 	controller := NewOrderController(&MockOrderService{})
 
@@ -98,9 +509,68 @@ func TestSaveOrderBookHandler(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
 
+func TestSaveOrderBookHandler_Unprocessable(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	order := models.OrderBookDTO{
+		Exchange: "unprocessable",
+		Pair:     "ETH-BTC",
+	}
+	body, _ := json.Marshal(order)
+
+	req := httptest.NewRequest("POST", "/order/book", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var validationErr service.ValidationError
+	err := json.NewDecoder(rr.Body).Decode(&validationErr)
+	assert.NoError(t, err)
+	assert.Equal(t, "asks[0].price", validationErr.Violations[0].Field)
+}
+
 func TestGetOrderHistoryHandler(t *testing.T) {
 	controller := NewOrderController(&MockOrderService{})
 
+	req := httptest.NewRequest("GET", "/order/history?clientName=testclient&exchangeName=test&label=main&pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderHistoryHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Empty(t, rr.Header().Get("Deprecation"))
+
+	var orders []models.HistoryOrder
+	err := json.NewDecoder(rr.Body).Decode(&orders)
+	assert.NoError(t, err)
+	assert.Empty(t, orders)
+}
+
+func TestGetOrderHistoryHandler_AllPages(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/history?clientName=paged", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderHistoryHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var orders []models.HistoryOrder
+	err := json.NewDecoder(rr.Body).Decode(&orders)
+	assert.NoError(t, err)
+	assert.Len(t, orders, 2)
+	assert.Equal(t, "order-1", orders[0].OrderID)
+	assert.Equal(t, "order-2", orders[1].OrderID)
+}
+
+func TestGetOrderHistoryHandler_DeprecatedBody(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
 	client := models.Client{
 		ClientName: "testclient",
 	}
@@ -112,7 +582,8 @@ func TestGetOrderHistoryHandler(t *testing.T) {
 	controller.GetOrderHistoryHandler(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
-	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "true", rr.Header().Get("Deprecation"))
+	assert.Contains(t, rr.Header().Get("Link"), "/order/history/search")
 
 	var orders []models.HistoryOrder
 	err := json.NewDecoder(rr.Body).Decode(&orders)
@@ -120,6 +591,89 @@ func TestGetOrderHistoryHandler(t *testing.T) {
 	assert.Empty(t, orders)
 }
 
+func TestGetOrderHistoryHandler_Filters(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/history?clientName=testclient&pair=BTC/*&side=buy&from=2024-05-01T00:00:00Z&to=1714608000000&sort=desc&limit=1", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderHistoryHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var page models.OrderHistoryPage
+	err := json.NewDecoder(rr.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Len(t, page.Orders, 1)
+	assert.Equal(t, "buy", page.Orders[0].Side)
+	assert.Equal(t, "next", page.NextCursor)
+}
+
+func TestGetOrderHistoryHandler_InvalidFilters(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	urls := []string{
+		"/order/history?exchangeName=test",
+		"/order/history?clientName=testclient&sort=sideways",
+		"/order/history?clientName=testclient&limit=-1",
+		"/order/history?clientName=testclient&limit=many",
+		"/order/history?clientName=testclient&limit=100000",
+		"/order/history?clientName=testclient&from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z",
+		"/order/history?clientName=testclient&from=yesterday",
+		"/order/history?clientName=testclient&cursor=invalid",
+	}
+
+	for _, url := range urls {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetOrderHistoryHandler(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, url)
+	}
+}
+
+func TestSearchOrderHistoryHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := []byte(`{"clientName":"testclient","pair":"BTC/*","side":"buy","from":"2024-05-01T00:00:00Z","to":"2024-05-02T00:00:00Z","sort":"desc","limit":1}`)
+	req := httptest.NewRequest("POST", "/order/history/search", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SearchOrderHistoryHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var page models.OrderHistoryPage
+	err := json.NewDecoder(rr.Body).Decode(&page)
+	assert.NoError(t, err)
+	assert.Len(t, page.Orders, 1)
+	assert.Equal(t, "next", page.NextCursor)
+}
+
+func TestSearchOrderHistoryHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		`invalid json`: http.StatusBadRequest,
+		`{}`:           http.StatusBadRequest,
+		`{"clientName":"testclient","sort":"sideways"}`:  http.StatusBadRequest,
+		`{"clientName":"testclient","from":"yesterday"}`: http.StatusBadRequest,
+		`{"clientName":"testclient","cursor":"invalid"}`: http.StatusBadRequest,
+		`{"clientName":"notfound"}`:                      http.StatusNotFound,
+		`{"clientName":"error"}`:                         http.StatusInternalServerError,
+	}
+
+	for body, status := range cases {
+		req := httptest.NewRequest("POST", "/order/history/search", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+
+		controller.SearchOrderHistoryHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, body)
+	}
+}
+
 func TestGetOrderHistoryHandler_RecordNotFound(t *testing.T) {
 	controller := NewOrderController(&MockOrderService{})
 
@@ -166,6 +720,12 @@ func TestSaveOrderHandler(t *testing.T) {
 	controller.SaveOrderHandler(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var order models.HistoryOrder
+	err := json.NewDecoder(rr.Body).Decode(&order)
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", order.OrderID)
+	assert.Equal(t, models.OrderStatusNew, order.Status)
 }
 
 func TestSaveOrderBookHandler_MissingRequiredFields(t *testing.T) {
@@ -289,3 +849,1114 @@ func TestGetOrderHandler_Error(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, rr.Code)
 }
+
+func TestGetLatestOrderBookHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/latest?exchangeName=test&pair=ETH-BTC&asOf=2024-05-01T12:00:00Z", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetLatestOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var order models.OrderBookDTO
+	err := json.NewDecoder(rr.Body).Decode(&order)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", order.Exchange)
+	assert.Equal(t, "ETH-BTC", order.Pair)
+	assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), order.ExchangeTime)
+}
+
+func TestGetLatestOrderBookHandler_UnixMillis(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/latest?exchangeName=test&pair=ETH-BTC&asOf=1714564800000", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetLatestOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var order models.OrderBookDTO
+	err := json.NewDecoder(rr.Body).Decode(&order)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).Equal(order.ExchangeTime))
+}
+
+func TestGetLatestOrderBookHandler_BadRequest(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/latest?exchangeName=test&pair=ETH-BTC&asOf=yesterday", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetLatestOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestGetLatestOrderBookHandler_NotFound(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/latest?exchangeName=invalid&pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetLatestOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestSaveOrderBookDeltaHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	delta := models.OrderBookDelta{
+		Exchange: "test",
+		Pair:     "ETH-BTC",
+		Sequence: 2,
+		Asks:     []*models.DepthOrder{{Price: decimal.RequireFromString("0.05"), BaseQty: decimal.Zero}},
+	}
+	body, _ := json.Marshal(delta)
+
+	req := httptest.NewRequest("POST", "/order/book/delta", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderBookDeltaHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestSaveOrderBookDeltaHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := map[string]int{
+		"":        http.StatusBadRequest,
+		"invalid": http.StatusNotFound,
+		"gap":     http.StatusConflict,
+		"crossed": http.StatusUnprocessableEntity,
+		"error":   http.StatusInternalServerError,
+	}
+	for exchange, code := range tests {
+		body, _ := json.Marshal(models.OrderBookDelta{Exchange: exchange, Pair: "ETH-BTC", Sequence: 2})
+
+		req := httptest.NewRequest("POST", "/order/book/delta", bytes.NewReader(body))
+		rr := httptest.NewRecorder()
+
+		controller.SaveOrderBookDeltaHandler(rr, req)
+
+		assert.Equal(t, code, rr.Code, exchange)
+	}
+}
+
+func TestGetOrderBookMetricsHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/metrics?exchangeName=test&pair=ETH-BTC&levels=3&bands=5,20", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderBookMetricsHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var metrics analytics.OrderBookMetrics
+	err := json.NewDecoder(rr.Body).Decode(&metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, metrics.Levels)
+	assert.Len(t, metrics.Depth, 2)
+	assert.Equal(t, 20.0, metrics.Depth[1].Bps)
+}
+
+func TestGetOrderBookMetricsHandler_Defaults(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/metrics?exchangeName=test&pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetOrderBookMetricsHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var metrics analytics.OrderBookMetrics
+	err := json.NewDecoder(rr.Body).Decode(&metrics)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, metrics.Levels)
+	assert.Len(t, metrics.Depth, 4)
+}
+
+func TestGetOrderBookMetricsHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := map[string]int{
+		"/order/book/metrics?exchangeName=test&pair=ETH-BTC&levels=0":     http.StatusBadRequest,
+		"/order/book/metrics?exchangeName=test&pair=ETH-BTC&bands=10,abc": http.StatusBadRequest,
+		"/order/book/metrics?exchangeName=test&pair=ETH-BTC&bands=NaN":    http.StatusBadRequest,
+		"/order/book/metrics?exchangeName=invalid&pair=ETH-BTC":           http.StatusNotFound,
+		"/order/book/metrics?exchangeName=empty&pair=ETH-BTC":             http.StatusUnprocessableEntity,
+		"/order/book/metrics?exchangeName=error&pair=ETH-BTC":             http.StatusInternalServerError,
+	}
+	for url, code := range tests {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetOrderBookMetricsHandler(rr, req)
+
+		assert.Equal(t, code, rr.Code, url)
+	}
+}
+
+func TestGetMarketImpactHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/impact?exchangeName=test&pair=ETH-BTC&side=Buy&baseQty=1.5", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetMarketImpactHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var impact analytics.MarketImpact
+	err := json.NewDecoder(rr.Body).Decode(&impact)
+	assert.NoError(t, err)
+	assert.Equal(t, "buy", impact.Side)
+	assert.Equal(t, "1.5", impact.BaseQty.String())
+}
+
+func TestGetMarketImpactHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := map[string]int{
+		"/order/book/impact?exchangeName=test&pair=ETH-BTC&side=hold&baseQty=1":   http.StatusBadRequest,
+		"/order/book/impact?exchangeName=test&pair=ETH-BTC&side=buy&baseQty=-1":   http.StatusBadRequest,
+		"/order/book/impact?exchangeName=test&pair=ETH-BTC&side=buy":              http.StatusBadRequest,
+		"/order/book/impact?exchangeName=invalid&pair=ETH-BTC&side=buy&baseQty=1": http.StatusNotFound,
+		"/order/book/impact?exchangeName=empty&pair=ETH-BTC&side=buy&baseQty=1":   http.StatusUnprocessableEntity,
+		"/order/book/impact?exchangeName=error&pair=ETH-BTC&side=buy&baseQty=1":   http.StatusInternalServerError,
+	}
+	for url, code := range tests {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetMarketImpactHandler(rr, req)
+
+		assert.Equal(t, code, rr.Code, url)
+	}
+}
+
+func TestGetConsolidatedOrderBookHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/book/consolidated?pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetConsolidatedOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var book analytics.ConsolidatedOrderBook
+	err := json.NewDecoder(rr.Body).Decode(&book)
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH-BTC", book.Pair)
+	assert.Len(t, book.Exchanges, 2)
+}
+
+func TestGetConsolidatedOrderBookHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := map[string]int{
+		"/order/book/consolidated":                          http.StatusBadRequest,
+		"/order/book/consolidated?pair=invalid":             http.StatusNotFound,
+		"/order/book/consolidated?pair=ETH-BTC&maxAge=soon": http.StatusBadRequest,
+		"/order/book/consolidated?pair=ETH-BTC&maxAge=-1s":  http.StatusBadRequest,
+		"/order/book/consolidated?pair=error":               http.StatusInternalServerError,
+	}
+	for url, code := range tests {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetConsolidatedOrderBookHandler(rr, req)
+
+		assert.Equal(t, code, rr.Code, url)
+	}
+}
+
+func TestSaveOrderHandler_Unprocessable(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	payload := models.HistoryOrderPayload{
+		Client:  models.Client{ClientName: "testclient"},
+		History: models.HistoryOrder{Type: "unprocessable"},
+	}
+	body, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest("POST", "/order/history", bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderHandler(rr, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+
+	var validationErr service.ValidationError
+	err := json.NewDecoder(rr.Body).Decode(&validationErr)
+	assert.NoError(t, err)
+	assert.Equal(t, "price", validationErr.Violations[0].Field)
+}
+
+func TestGetPairPrecisionHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/pair/precision?exchangeName=test&pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetPairPrecisionHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var precision models.PairPrecision
+	err := json.NewDecoder(rr.Body).Decode(&precision)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), precision.PriceScale)
+	assert.Equal(t, int32(8), precision.QtyScale)
+}
+
+func TestGetPairPrecisionHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		"/pair/precision?exchangeName=test":                 http.StatusBadRequest,
+		"/pair/precision?exchangeName=invalid&pair=ETH-BTC": http.StatusNotFound,
+		"/pair/precision?exchangeName=error&pair=ETH-BTC":   http.StatusInternalServerError,
+	}
+
+	for url, status := range cases {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetPairPrecisionHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, url)
+	}
+}
+
+func TestSavePairPrecisionHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		`{"exchange":"test","pair":"ETH-BTC","priceScale":2,"qtyScale":8}`:  http.StatusOK,
+		`{"exchange":"test","priceScale":2,"qtyScale":8}`:                   http.StatusBadRequest,
+		`{"exchange":"test","pair":"ETH-BTC","priceScale":-1,"qtyScale":8}`: http.StatusBadRequest,
+		`{"exchange":"test","pair":"ETH-BTC","priceScale":2,"qtyScale":19}`: http.StatusBadRequest,
+		`{"exchange":"error","pair":"ETH-BTC","priceScale":2,"qtyScale":8}`: http.StatusInternalServerError,
+	}
+
+	for body, status := range cases {
+		req := httptest.NewRequest("POST", "/pair/precision", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+
+		controller.SavePairPrecisionHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, body)
+	}
+}
+
+// withURLParam adds a chi route parameter to the request, as the router does for path patterns.
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestUpdateOrderStatusHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := []byte(`{"status":"FILLED"}`)
+	req := withURLParam(httptest.NewRequest("POST", "/order/history/order-1/status", bytes.NewReader(body)), "id", "order-1")
+	rr := httptest.NewRecorder()
+
+	controller.UpdateOrderStatusHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var order models.HistoryOrder
+	err := json.NewDecoder(rr.Body).Decode(&order)
+	assert.NoError(t, err)
+	assert.Equal(t, "order-1", order.OrderID)
+	assert.Equal(t, models.OrderStatusFilled, order.Status)
+}
+
+func TestUpdateOrderStatusHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := []struct {
+		orderID string
+		body    string
+		status  int
+	}{
+		{"order-1", `{}`, http.StatusBadRequest},
+		{"order-1", `invalid json`, http.StatusBadRequest},
+		{"invalid", `{"status":"FILLED"}`, http.StatusNotFound},
+		{"final", `{"status":"FILLED"}`, http.StatusConflict},
+		{"unprocessable", `{"status":"DONE"}`, http.StatusUnprocessableEntity},
+		{"error", `{"status":"FILLED"}`, http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		req := withURLParam(httptest.NewRequest("POST", "/order/history/"+c.orderID+"/status", bytes.NewReader([]byte(c.body))), "id", c.orderID)
+		rr := httptest.NewRecorder()
+
+		controller.UpdateOrderStatusHandler(rr, req)
+
+		assert.Equal(t, c.status, rr.Code, c.orderID)
+	}
+}
+
+func TestSaveFillsHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		`[{"fillId":"fill-1","orderId":"order-1","baseQty":1,"price":100}]`: http.StatusOK,
+		`[]`:                  http.StatusBadRequest,
+		`{"fillId":"fill-1"}`: http.StatusBadRequest,
+		`[{"fillId":"fill-1","orderId":"unprocessable"}]`: http.StatusUnprocessableEntity,
+		`[{"fillId":"fill-1","orderId":"error"}]`:         http.StatusInternalServerError,
+	}
+
+	for body, status := range cases {
+		req := httptest.NewRequest("POST", "/order/fills", bytes.NewReader([]byte(body)))
+		rr := httptest.NewRecorder()
+
+		controller.SaveFillsHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, body)
+	}
+}
+
+func TestGetClientPnLHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/client/pnl?clientName=testclient&pair=ETH-BTC", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetClientPnLHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var pnl []*analytics.PnL
+	err := json.NewDecoder(rr.Body).Decode(&pnl)
+	assert.NoError(t, err)
+	assert.Equal(t, "ETH-BTC", pnl[0].Pair)
+	assert.Equal(t, analytics.PnLMethodFIFO, pnl[0].Method)
+}
+
+func TestGetClientPnLHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		"/client/pnl?pair=ETH-BTC":                      http.StatusBadRequest,
+		"/client/pnl?clientName=testclient&method=lifo": http.StatusBadRequest,
+		"/client/pnl?clientName=testclient&from=never":  http.StatusBadRequest,
+		"/client/pnl?clientName=notfound":               http.StatusNotFound,
+		"/client/pnl?clientName=error":                  http.StatusInternalServerError,
+	}
+
+	for url, status := range cases {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetClientPnLHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, url)
+	}
+}
+
+func TestGetExecutionQualityHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/execution-quality?clientName=testclient&algorithmNamePlaced=twap", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetExecutionQualityHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var report analytics.ExecutionQualityReport
+	err := json.NewDecoder(rr.Body).Decode(&report)
+	assert.NoError(t, err)
+	assert.Equal(t, "twap", report.Algorithms[0].Algorithm)
+}
+
+func TestGetExecutionQualityHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		"/order/execution-quality?pair=ETH-BTC":                   http.StatusBadRequest,
+		"/order/execution-quality?clientName=testclient&to=never": http.StatusBadRequest,
+		"/order/execution-quality?clientName=notfound":            http.StatusNotFound,
+		"/order/execution-quality?clientName=error":               http.StatusInternalServerError,
+	}
+
+	for url, status := range cases {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetExecutionQualityHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, url)
+	}
+}
+
+func TestGetAlgorithmsHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/algorithms", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetAlgorithmsHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var algorithms []*models.Algorithm
+	err := json.NewDecoder(rr.Body).Decode(&algorithms)
+	assert.NoError(t, err)
+	assert.Equal(t, "twap", algorithms[0].Name)
+}
+
+func TestGetAlgorithmStatsHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := withURLParam(httptest.NewRequest("GET", "/algorithms/twap/stats?from=2024-05-01T00:00:00Z", nil), "name", "twap")
+	rr := httptest.NewRecorder()
+
+	controller.GetAlgorithmStatsHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var stats models.AlgorithmStats
+	err := json.NewDecoder(rr.Body).Decode(&stats)
+	assert.NoError(t, err)
+	assert.Equal(t, "twap", stats.Algorithm)
+	assert.Equal(t, uint64(2), stats.Orders)
+}
+
+func TestGetAlgorithmStatsHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{"", "", http.StatusBadRequest},
+		{"twap", "from=never", http.StatusBadRequest},
+		{"twap", "from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z", http.StatusBadRequest},
+		{"notfound", "", http.StatusNotFound},
+		{"error", "", http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		req := withURLParam(httptest.NewRequest("GET", "/algorithms/"+c.name+"/stats?"+c.query, nil), "name", c.name)
+		rr := httptest.NewRecorder()
+
+		controller.GetAlgorithmStatsHandler(rr, req)
+
+		assert.Equal(t, c.status, rr.Code, c.name+" "+c.query)
+	}
+}
+
+func TestGetCandlesHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/candles?exchangeName=test_exchange&pair=BTC/USD&interval=5m&mids=true", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetCandlesHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var candles models.Candles
+	err := json.NewDecoder(rr.Body).Decode(&candles)
+	assert.NoError(t, err)
+	assert.Equal(t, "5m", candles.Interval)
+	assert.Len(t, candles.Trades, 1)
+	assert.Len(t, candles.Mids, 1)
+}
+
+func TestGetCandlesHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		"/candles?pair=BTC/USD&interval=1m":                                        http.StatusBadRequest,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD":                         http.StatusBadRequest,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD&interval=2m":             http.StatusBadRequest,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD&interval=1m&from=never":  http.StatusBadRequest,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD&interval=1m&to=0":        http.StatusOK,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD&interval=1m&mids=maybe":  http.StatusBadRequest,
+		"/candles?exchangeName=test_exchange&pair=BTC/USD&interval=1m&from=2&to=1": http.StatusBadRequest,
+		"/candles?exchangeName=error&pair=BTC/USD&interval=1h":                     http.StatusInternalServerError,
+	}
+
+	for url, status := range cases {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetCandlesHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, url)
+	}
+}
+
+func TestGetFeeReportHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/fees?clientName=testclient&period=day", nil)
+	rr := httptest.NewRecorder()
+
+	controller.GetFeeReportHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var report models.FeeReport
+	err := json.NewDecoder(rr.Body).Decode(&report)
+	assert.NoError(t, err)
+	assert.Equal(t, "day", report.Period)
+	assert.Equal(t, "testclient", report.Rows[0].ClientName)
+}
+
+func TestGetFeeReportHandler_CSV(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/fees?clientName=testclient&period=month", nil)
+	req.Header.Set("Accept", "text/csv")
+	rr := httptest.NewRecorder()
+
+	controller.GetFeeReportHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Equal(t, "period,client_name,exchange_name,pair,label,orders,notional,commission,fee_rate_bps\n"+
+		"2024-05,testclient,test_exchange,BTC/USD,\"label,with comma\",2,1000,1,10.0000\n", rr.Body.String())
+}
+
+func TestGetFeeReportHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	cases := map[string]int{
+		"/fees?period=week":      http.StatusBadRequest,
+		"/fees?format=xml":       http.StatusBadRequest,
+		"/fees?from=never":       http.StatusBadRequest,
+		"/fees?from=2&to=1":      http.StatusBadRequest,
+		"/fees?clientName=error": http.StatusInternalServerError,
+		"/fees?format=csv":       http.StatusOK,
+	}
+
+	for url, status := range cases {
+		req := httptest.NewRequest("GET", url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.GetFeeReportHandler(rr, req)
+
+		assert.Equal(t, status, rr.Code, url)
+	}
+}
+
+func TestSaveOrderBookBatchHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := `[{"Exchange":"test_exchange","Pair":"BTC/USD"},{"Exchange":"test_exchange","Pair":"ETH/USD"}]`
+	req := httptest.NewRequest("POST", "/order/book/batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderBookBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var result service.BatchResult
+	err := json.NewDecoder(rr.Body).Decode(&result)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Accepted)
+	assert.Len(t, result.Items, 2)
+}
+
+func TestSaveOrderBookBatchHandler_BufferFull(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := `[{"Exchange":"busy","Pair":"BTC/USD"}]`
+	req := httptest.NewRequest("POST", "/order/book/batch", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderBookBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+}
+
+func TestSaveOrderBatchHandler_NDJSON(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := `{"Client":{"clientName":"testclient"},"History":{"orderId":"order-1","type":"limit"}}
+{"Client":{"clientName":"testclient"},"History":{"orderId":"order-2","type":"limit"}}
+`
+	req := httptest.NewRequest("POST", "/order/history/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderBatchHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var result service.BatchResult
+	err := json.NewDecoder(rr.Body).Decode(&result)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, "order-2", result.Items[1].OrderID)
+}
+
+func TestSaveOrderBatchHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tooLarge := "[" + strings.Repeat(`{"Client":{"clientName":"testclient"}},`, service.MaxBatchSize) + "{}]"
+
+	cases := []struct {
+		body        string
+		contentType string
+		status      int
+	}{
+		{`[]`, "application/json", http.StatusBadRequest},
+		{``, "application/x-ndjson", http.StatusBadRequest},
+		{`{"Client":{}}`, "application/json", http.StatusBadRequest},
+		{`[{"Client":`, "application/json", http.StatusBadRequest},
+		{`{"Client":{}} not json`, "application/x-ndjson", http.StatusBadRequest},
+		{tooLarge, "application/json", http.StatusRequestEntityTooLarge},
+		{`[{"Client":{"clientName":"error"}}]`, "application/json", http.StatusInternalServerError},
+		{`[{"Client":{"clientName":"busy"}}]`, "application/json", http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/order/history/batch", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		rr := httptest.NewRecorder()
+
+		controller.SaveOrderBatchHandler(rr, req)
+
+		assert.Equal(t, c.status, rr.Code, c.body[:min(len(c.body), 40)])
+	}
+}
+
+func TestSaveOrderHandler_BufferFull(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	body := `{"Client":{"clientName":"testclient"},"History":{"type":"busy"}}`
+	req := httptest.NewRequest("POST", "/order/history", strings.NewReader(body))
+	rr := httptest.NewRecorder()
+
+	controller.SaveOrderHandler(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	assert.Equal(t, "1", rr.Header().Get("Retry-After"))
+}
+
+func TestStreamOrderBookHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+	server := httptest.NewServer(http.HandlerFunc(controller.StreamOrderBookHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?subscribe=test_exchange:BTC/USD"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// Subscriptions from the query start with the stored snapshot
+	messages := readStreamMessages(t, conn, 2)
+	assert.Contains(t, messages, `{"type":"subscribed","exchange":"test_exchange","pair":"BTC/USD"}`)
+
+	var update models.OrderBookUpdate
+	assert.NoError(t, json.Unmarshal([]byte(findStreamMessage(messages, `"type":"snapshot"`)), &update))
+	assert.Equal(t, int64(10), update.Book.Sequence)
+
+	assert.NoError(t, conn.WriteJSON(streamRequest{Action: "subscribe", Exchange: "test_exchange", Pair: "ETH/USD"}))
+	assert.Equal(t, []string{`{"type":"subscribed","exchange":"test_exchange","pair":"ETH/USD"}`}, readStreamMessages(t, conn, 1))
+
+	err = streamService.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "ETH/USD", Sequence: 3})
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal([]byte(readStreamMessages(t, conn, 1)[0]), &update))
+	assert.Equal(t, models.OrderBookUpdateSnapshot, update.Type)
+	assert.Equal(t, "ETH/USD", update.Pair)
+
+	assert.NoError(t, conn.WriteJSON(streamRequest{Action: "unsubscribe", Exchange: "test_exchange", Pair: "ETH/USD"}))
+	assert.Equal(t, []string{`{"type":"unsubscribed","exchange":"test_exchange","pair":"ETH/USD"}`}, readStreamMessages(t, conn, 1))
+
+	assert.NoError(t, conn.WriteJSON(streamRequest{Action: "subscribe", Exchange: "error", Pair: "ETH/USD"}))
+	assert.Equal(t, []string{`{"type":"error","exchange":"error","pair":"ETH/USD","error":"failed to load order book"}`}, readStreamMessages(t, conn, 1))
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	assert.Equal(t, []string{`{"type":"error","error":"invalid message"}`}, readStreamMessages(t, conn, 1))
+}
+
+func TestStreamOrderBookHandler_SlowConsumer(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+	server := httptest.NewServer(http.HandlerFunc(controller.StreamOrderBookHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?subscribe=test_exchange:SOL/USD"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	readStreamMessages(t, conn, 1)
+
+	// Updates published faster than the connection buffer drains close it with a policy violation
+	for i := int64(1); i <= streamBufferSize*100; i++ {
+		streamService.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "SOL/USD", Sequence: i})
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err = conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation))
+}
+
+func TestStreamOrderBookHandler_InvalidSubscription(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req, _ := http.NewRequest("GET", "/ws/order/book?subscribe=test_exchange", nil)
+	rr := httptest.NewRecorder()
+	controller.StreamOrderBookHandler(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	// Requests without a WebSocket upgrade are rejected
+	req, _ = http.NewRequest("GET", "/ws/order/book", nil)
+	rr = httptest.NewRecorder()
+	controller.StreamOrderBookHandler(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestStreamOrderBookHandler_Origin(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{}, "https://app.example.com")
+	server := httptest.NewServer(http.HandlerFunc(controller.StreamOrderBookHandler))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+	tests := []struct {
+		name   string
+		origin string
+		ok     bool
+	}{
+		{"no origin", "", true},
+		{"same origin", server.URL, true},
+		{"allowed origin", "https://APP.example.com", true},
+		{"other origin", "https://evil.example.com", false},
+		{"allowed host over other scheme", "http://app.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+
+			conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+			if !tt.ok {
+				assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+				return
+			}
+			assert.NoError(t, err)
+			conn.Close()
+		})
+	}
+}
+
+func readStreamMessages(t *testing.T, conn *websocket.Conn, n int) []string {
+	t.Helper()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	messages := make([]string, 0, n)
+	for len(messages) < n {
+		_, message, err := conn.ReadMessage()
+		if !assert.NoError(t, err) {
+			break
+		}
+		messages = append(messages, strings.TrimSpace(string(message)))
+	}
+	return messages
+}
+
+func findStreamMessage(messages []string, substr string) string {
+	for _, message := range messages {
+		if strings.Contains(message, substr) {
+			return message
+		}
+	}
+	return ""
+}
+
+func TestStreamOrderHistoryHandler(t *testing.T) {
+	historyHeartbeatPeriod = 50 * time.Millisecond
+	defer func() { historyHeartbeatPeriod = 15 * time.Second }()
+
+	controller := NewOrderController(&MockOrderService{})
+	server := httptest.NewServer(http.HandlerFunc(controller.StreamOrderHistoryHandler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?clientName=stream_client")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, reader))
+
+	client := &models.Client{ClientName: "stream_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	_, err = streamService.SaveOrder(&models.Client{ClientName: "other_client"}, &models.HistoryOrder{OrderID: "other"})
+	assert.NoError(t, err)
+	saved, err := streamService.SaveOrder(client, &models.HistoryOrder{OrderID: "order-1"})
+	assert.NoError(t, err)
+
+	event := readEvent(t, reader)
+	for len(event) == 1 {
+		event = readEvent(t, reader)
+	}
+	assert.Len(t, event, 3)
+	assert.Equal(t, "event: order", event[1])
+
+	var order models.HistoryOrder
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(event[2], "data: ")), &order))
+	assert.Equal(t, saved.OrderID, order.OrderID)
+
+	// Reconnecting with the last event ID replays the orders missed since
+	_, err = streamService.SaveOrder(client, &models.HistoryOrder{OrderID: "order-2"})
+	assert.NoError(t, err)
+	req, _ := http.NewRequest("GET", server.URL+"?clientName=stream_client", nil)
+	req.Header.Set("Last-Event-ID", strings.TrimPrefix(event[0], "id: "))
+	resumed, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resumed.Body.Close()
+
+	event = readEvent(t, bufio.NewReader(resumed.Body))
+	assert.Len(t, event, 3)
+	assert.Contains(t, event[2], `"orderId":"missed"`)
+}
+
+func TestCloseStreams(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/order/history/stream", controller.StreamOrderHistoryHandler)
+	mux.HandleFunc("/ws/order/book", controller.StreamOrderBookHandler)
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.RegisterOnShutdown(controller.CloseStreams)
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/order/history/stream")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws/order/book", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// Shutting down ends the streams rather than waiting for them
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, server.Config.Shutdown(ctx))
+
+	_, err = io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+}
+
+func TestStreamOrderHistoryHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := []struct {
+		url          string
+		expectedCode int
+	}{
+		{"/order/history/stream?lastEventId=invalid", http.StatusBadRequest},
+		{"/order/history/stream?clientName=error", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		rr := httptest.NewRecorder()
+		controller.StreamOrderHistoryHandler(rr, req)
+		assert.Equal(t, tt.expectedCode, rr.Code, tt.url)
+	}
+}
+
+// readEvent reads the lines of the next Server-Sent Event up to the blank line ending it.
+func readEvent(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return lines
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestExportHandler(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+	}{
+		{
+			"history csv",
+			"/export?dataset=history&format=csv&clientName=test_client",
+			"text/csv",
+			"order_id,client_order_id,client_name,exchange_name,label,pair,side,type,base_qty,price,algorithm_name_placed,lowest_sell_prc,highest_buy_prc,commission_quote_qty,time_placed,status,updated_at\n" +
+				"order-1,,test_client,,,,buy,,1,100.25,,0,0,0,2024-01-01T00:00:00Z,,\n" +
+				"order-2,,test_client,,,,buy,,1,100.25,,0,0,0,2024-01-01T00:00:00Z,,\n",
+		},
+		{
+			"history ndjson",
+			"/export?dataset=history&format=ndjson&clientName=test_client",
+			"application/x-ndjson",
+			"",
+		},
+		{
+			"books csv",
+			"/export?dataset=books&format=csv&exchangeName=test_exchange&pair=BTC/USD&depth=2",
+			"text/csv",
+			"id,exchange,pair,exchange_time,received_time,sequence,ask_1_price,ask_1_qty,ask_2_price,ask_2_qty,bid_1_price,bid_1_qty,bid_2_price,bid_2_qty\n" +
+				"1,test_exchange,BTC/USD,2024-01-01T00:00:01Z,,1,101,0.5,,,99,1,98,3\n" +
+				"2,test_exchange,BTC/USD,2024-01-01T00:00:02Z,,2,102,0.5,,,99,2,98,3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		rr := httptest.NewRecorder()
+
+		controller.ExportHandler(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code, tt.name)
+		assert.Equal(t, tt.contentType, rr.Header().Get("Content-Type"), tt.name)
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment", tt.name)
+		if tt.body != "" {
+			assert.Equal(t, tt.body, rr.Body.String(), tt.name)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/export?dataset=history&format=ndjson&clientName=test_client", nil)
+	rr := httptest.NewRecorder()
+	controller.ExportHandler(rr, req)
+
+	var orders []models.HistoryOrder
+	scanner := bufio.NewScanner(rr.Body)
+	for scanner.Scan() {
+		var order models.HistoryOrder
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &order))
+		orders = append(orders, order)
+	}
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "order-2", orders[1].OrderID)
+		assert.True(t, decimal.RequireFromString("100.25").Equal(orders[1].Price))
+	}
+}
+
+func TestExportHandler_Parquet(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/export?dataset=books&format=parquet&exchangeName=test_exchange&pair=BTC/USD", nil)
+	rr := httptest.NewRecorder()
+
+	controller.ExportHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/vnd.apache.parquet", rr.Header().Get("Content-Type"))
+
+	body := rr.Body.Bytes()
+	rows, err := parquet.Read[bookParquetRow](bytes.NewReader(body), int64(len(body)))
+	assert.NoError(t, err)
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, int64(2), rows[1].Sequence)
+		assert.Equal(t, []levelParquetRow{{Price: "102", BaseQty: "0.5"}}, rows[1].Asks)
+		assert.Equal(t, []levelParquetRow{{Price: "99", BaseQty: "2"}, {Price: "98", BaseQty: "3"}}, rows[1].Bids)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC).UnixMicro(), rows[1].ExchangeTime)
+	}
+}
+
+func TestExportHandler_Formats(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	req := httptest.NewRequest("GET", "/order/history?clientName=test_client&format=csv", nil)
+	rr := httptest.NewRecorder()
+	controller.GetOrderHistoryHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+	assert.Equal(t, 3, strings.Count(rr.Body.String(), "\n"))
+
+	req = httptest.NewRequest("GET", "/order/book?exchangeName=test_exchange&pair=BTC/USD&format=ndjson", nil)
+	rr = httptest.NewRecorder()
+	controller.GetOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/x-ndjson", rr.Header().Get("Content-Type"))
+	assert.Equal(t, 2, strings.Count(rr.Body.String(), "\n"))
+
+	req = httptest.NewRequest("GET", "/order/book?exchangeName=test_exchange&pair=BTC/USD&format=json", nil)
+	rr = httptest.NewRecorder()
+	controller.GetOrderBookHandler(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+}
+
+func TestExportHandler_Errors(t *testing.T) {
+	controller := NewOrderController(&MockOrderService{})
+
+	tests := []struct {
+		name    string
+		url     string
+		handler http.HandlerFunc
+		code    int
+	}{
+		{"missing format", "/export?dataset=history&clientName=test_client", controller.ExportHandler, http.StatusBadRequest},
+		{"unknown format", "/export?dataset=history&format=xml&clientName=test_client", controller.ExportHandler, http.StatusBadRequest},
+		{"unknown dataset", "/export?dataset=fills&format=csv", controller.ExportHandler, http.StatusBadRequest},
+		{"missing client", "/export?dataset=history&format=csv", controller.ExportHandler, http.StatusBadRequest},
+		{"missing pair", "/export?dataset=books&format=csv&exchangeName=test_exchange", controller.ExportHandler, http.StatusBadRequest},
+		{"invalid range", "/export?dataset=books&format=csv&exchangeName=test_exchange&pair=BTC/USD&depth=2&from=2000&to=1000", controller.ExportHandler, http.StatusBadRequest},
+		{"books csv without depth", "/export?dataset=books&format=csv&exchangeName=test_exchange&pair=BTC/USD", controller.ExportHandler, http.StatusBadRequest},
+		{"order book csv without depth", "/order/book?exchangeName=test_exchange&pair=BTC/USD&format=csv", controller.GetOrderBookHandler, http.StatusBadRequest},
+		{"history error", "/export?dataset=history&format=ndjson&clientName=error", controller.ExportHandler, http.StatusInternalServerError},
+		{"books error", "/export?dataset=books&format=parquet&exchangeName=error&pair=BTC/USD", controller.ExportHandler, http.StatusInternalServerError},
+		{"unknown history format", "/order/history?clientName=test_client&format=xml", controller.GetOrderHistoryHandler, http.StatusBadRequest},
+		{"unknown book format", "/order/book?exchangeName=test_exchange&pair=BTC/USD&format=xml", controller.GetOrderBookHandler, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		rr := httptest.NewRecorder()
+
+		tt.handler(rr, req)
+
+		assert.Equal(t, tt.code, rr.Code, tt.name)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"), tt.name)
+	}
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/service"
+)
+
+// Number of orders buffered per stream before it is closed as a slow consumer
+const historyStreamBufferSize = 256
+
+// Interval of the comments sent on an idle order history stream to keep proxies from closing it
+var historyHeartbeatPeriod = 15 * time.Second
+
+// StreamOrderHistoryHandler streams saved orders as Server-Sent Events.
+//
+//	@Summary		Stream order history
+//	@Description	Streams each order state saved for the client, exchange, label and pair, by SaveOrder or a status update,
+//	@Description	as an "order" Server-Sent Event with the HistoryOrder as data. Empty filter fields match any order.
+//	@Description	Reconnecting with the Last-Event-ID header, or the lastEventId parameter, replays the states stored
+//	@Description	after that event, oldest first, including states not yet written to ClickHouse, then continues with the live ones.
+//	@Description	States replaced by a later state of their order when ClickHouse merges parts are not replayed, the later state is.
+//	@Description	Comments are sent as heartbeats while no orders are saved.
+//	@Description	Streams that do not keep up, or whose replay fails, are ended with an "error" event and should reconnect,
+//	@Description	as should streams ended when the server shuts down.
+//	@Tags			orders
+//	@Produce		text/event-stream
+//	@Param			clientName		query		string	false	"Client Name"
+//	@Param			exchangeName	query		string	false	"Exchange Name"
+//	@Param			label			query		string	false	"Label"
+//	@Param			pair			query		string	false	"Trading Pair"
+//	@Param			lastEventId		query		string	false	"ID of the last event received"
+//	@Param			Last-Event-ID	header		string	false	"ID of the last event received"
+//	@Success		200				{object}	models.HistoryOrder
+//	@Failure		400				{string}	string	"Bad Request"
+//	@Failure		500				{string}	string	"Internal Server Error"
+//	@Router			/order/history/stream [get]
+func (oci *orderControllerImpl) StreamOrderHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := &models.OrderStreamFilter{
+		ClientName:   query.Get("clientName"),
+		ExchangeName: query.Get("exchangeName"),
+		Label:        query.Get("label"),
+		Pair:         query.Get("pair"),
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = query.Get("lastEventId")
+	}
+
+	subscription, err := oci.service.SubscribeOrders(filter, lastEventID, historyStreamBufferSize)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidEventID) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(historyHeartbeatPeriod)
+	defer ticker.Stop()
+
+	events := subscription.Events()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if err := subscription.Err(); err != nil {
+					message := "replay failed"
+					if errors.Is(err, service.ErrSlowConsumer) {
+						message = "slow consumer"
+					}
+					fmt.Fprintf(w, "event: error\ndata: %s\n\n", message)
+					flusher.Flush()
+				}
+				return
+			}
+
+			bytes, _ := json.Marshal(event.Order)
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: order\ndata: %s\n\n", event.ID, bytes); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		case <-oci.streams.Done():
+			return
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/kymaka/vortex-test/internal/models"
 
 	"gorm.io/gorm"
@@ -8,9 +12,28 @@ import (
 
 type OrderRepository interface {
 	FindOrder(exchangeName, pair string) ([]*models.OrderBook, error)
+	FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error)
+	FindLatestOrdersByPair(pair string) ([]*models.OrderBook, error)
+	StreamOrders(exchangeName, pair string, from, to time.Time, fn func(*models.OrderBook) error) error
 	SaveOrder(order models.OrderBook) error
-	FindOrderHistory(client *models.Client) ([]*models.HistoryOrder, error)
+	SaveOrders(orders []models.OrderBook) error
+	FindOrderHistory(filter *models.OrderHistoryFilter, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error)
+	StreamOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error
+	FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error)
+	FindHistoryOrder(orderID string) (*models.HistoryOrder, error)
+	FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error)
+	FindAlgorithms() ([]*models.Algorithm, error)
+	FindAlgorithmVolume(name string, from, to time.Time) (*models.AlgorithmVolume, error)
+	FindAlgorithmExecutions(name string, from, to time.Time, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error)
 	SaveOrderHistory(order models.HistoryOrder) error
+	SaveOrderHistories(orders []models.HistoryOrder) error
+	FindFills(orderIDs []string) ([]*models.Fill, error)
+	SaveFills(fills []models.Fill) error
+	FindTradeCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.Candle, error)
+	FindMidCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.MidCandle, error)
+	FindFeeReport(filter *models.FeeReportFilter) ([]*models.FeeReportRow, error)
+	FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error)
+	SavePairPrecision(precision models.PairPrecision) error
 }
 
 type orderRepositoryImpl struct {
@@ -22,13 +45,15 @@ func NewOrderRepository(d *gorm.DB) OrderRepository {
 }
 
 /*
-FindOrder retrieves an order book from the database based on the exchange name and trading pair.
-Returns the order book if found, or an error if not found or any other issue occurs.
+FindOrder retrieves order book snapshots from the database based on the exchange name and trading pair.
+Snapshots are ordered by exchange time and sequence number, oldest first.
+Returns the order books if found, or an error if not found or any other issue occurs.
 */
 func (ori *orderRepositoryImpl) FindOrder(exchangeName, pair string) ([]*models.OrderBook, error) {
 	var order []*models.OrderBook
 	tx := ori.db.Where("exchange = ?", exchangeName).
 		Where("pair = ?", pair).
+		Order("exchange_time, sequence, received_time").
 		Find(&order)
 
 	if tx.RowsAffected == 0 {
@@ -41,6 +66,56 @@ func (ori *orderRepositoryImpl) FindOrder(exchangeName, pair string) ([]*models.
 	return order, nil
 }
 
+/*
+FindLatestOrder retrieves the order book snapshot in effect at asOf for the exchange and trading pair,
+that is the snapshot with the highest exchange time and sequence not later than asOf.
+A zero asOf returns the most recent snapshot.
+Returns gorm.ErrRecordNotFound if there is no such snapshot.
+*/
+func (ori *orderRepositoryImpl) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	var order []*models.OrderBook
+	tx := ori.db.Where("exchange = ?", exchangeName).
+		Where("pair = ?", pair)
+	if !asOf.IsZero() {
+		tx = tx.Where("exchange_time <= ?", asOf)
+	}
+	tx = tx.Order("exchange_time DESC, sequence DESC, received_time DESC").
+		Limit(1).
+		Find(&order)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return order[0], nil
+}
+
+/*
+FindLatestOrdersByPair retrieves the most recent order book snapshot of every exchange trading the pair.
+Returns gorm.ErrRecordNotFound if no exchange has a snapshot for the pair.
+*/
+func (ori *orderRepositoryImpl) FindLatestOrdersByPair(pair string) ([]*models.OrderBook, error) {
+	var orders []*models.OrderBook
+	tx := ori.db.Raw(`
+			SELECT * FROM order_books
+			WHERE pair = ?
+			ORDER BY exchange, exchange_time DESC, sequence DESC, received_time DESC
+			LIMIT 1 BY exchange`, pair).
+		Scan(&orders)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if len(orders) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return orders, nil
+}
+
 /*
 SaveOrder saves a new order book to the database.
 Returns an error if the operation fails.
@@ -56,27 +131,407 @@ func (ori *orderRepositoryImpl) SaveOrder(order models.OrderBook) error {
 }
 
 /*
-FindOrderHistory retrieves the order history for a given client from the database.
-Returns the order history if found, or an error if not found or any other issue occurs.
+SaveOrders saves a batch of order books to the database in a single insert.
+Returns an error if the operation fails.
 */
-func (ori *orderRepositoryImpl) FindOrderHistory(client *models.Client) ([]*models.HistoryOrder, error) {
+func (ori *orderRepositoryImpl) SaveOrders(orders []models.OrderBook) error {
+	tx := ori.db.Create(&orders)
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+/*
+FindOrderHistory retrieves the current state of the orders matching the filter, of every client if it has no client name.
+Orders are sorted by the time they were placed and their order ID, in the direction of the filter sort.
+If after is set, only orders following it in that order are returned.
+Returns at most limit orders, or gorm.ErrRecordNotFound if no order matches.
+*/
+func (ori *orderRepositoryImpl) FindOrderHistory(filter *models.OrderHistoryFilter, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error) {
+	conditions, args := historyConditions(filter)
+
+	direction := "ASC"
+	comparison := ">"
+	if filter.Sort == models.SortDesc {
+		direction = "DESC"
+		comparison = "<"
+	}
+
+	if after != nil {
+		conditions = append(conditions, fmt.Sprintf("(time_placed, order_id) %s (?, ?)", comparison))
+		args = append(args, after.TimePlaced, after.OrderID)
+	}
+
 	var orderHistory []*models.HistoryOrder
-	tx := ori.db.
-		Where("client_name = ?", client.ClientName).
-		Where("exchange_name = ?", client.ExchangeName).
-		Where("label = ?", client.Label).
-		Where("pair = ?", client.Pair).
-		Find(&orderHistory)
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT * FROM (
+				SELECT * FROM history_orders
+				WHERE %s
+				ORDER BY order_id, updated_at DESC
+				LIMIT 1 BY order_id
+			)
+			ORDER BY time_placed %s, order_id %s
+			LIMIT ?`, strings.Join(conditions, " AND "), direction, direction), append(args, limit)...).
+		Scan(&orderHistory)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if len(orderHistory) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
 
+	return orderHistory, nil
+}
+
+/*
+FindOrdersSavedAfter retrieves the first limit order states ingested after the position, oldest first.
+States replaced by a later state of their order when ClickHouse merges parts are not returned, the later state is.
+*/
+func (ori *orderRepositoryImpl) FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error) {
+	conditions := []string{"1"}
+	var args []any
+
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.ClientName != "" {
+		addCondition("client_name = ?", filter.ClientName)
+	}
+	if filter.ExchangeName != "" {
+		addCondition("exchange_name = ?", filter.ExchangeName)
+	}
+	if filter.Label != "" {
+		addCondition("label = ?", filter.Label)
+	}
+	if filter.Pair != "" {
+		addCondition("pair = ?", filter.Pair)
+	}
+
+	where := strings.Join(conditions, " AND ")
+
+	var orders []*models.HistoryOrder
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT * FROM history_orders
+			WHERE %s AND (ingested_at, order_id) > (fromUnixTimestamp64Micro(?, 'UTC'), ?)
+			ORDER BY ingested_at, order_id
+			LIMIT ?`, where),
+		append(args, after.IngestedAt.UnixMicro(), after.OrderID, limit)...).
+		Scan(&orders)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return orders, nil
+}
+
+// historyConditions lists the conditions of an order history filter and their arguments.
+func historyConditions(filter *models.OrderHistoryFilter) ([]string, []any) {
+	conditions := []string{"1"}
+	var args []any
+
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.ClientName != "" {
+		addCondition("client_name = ?", filter.ClientName)
+	}
+	if filter.ExchangeName != "" {
+		addCondition("exchange_name = ?", filter.ExchangeName)
+	}
+	if filter.Label != "" {
+		addCondition(matchCondition("label", filter.Label), wildcardPattern(filter.Label))
+	}
+	if filter.Pair != "" {
+		addCondition(matchCondition("pair", filter.Pair), wildcardPattern(filter.Pair))
+	}
+	if !filter.From.IsZero() {
+		addCondition("time_placed >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		addCondition("time_placed < ?", filter.To)
+	}
+	if filter.Side != "" {
+		addCondition("side = ?", filter.Side)
+	}
+	if filter.Type != "" {
+		addCondition("type = ?", filter.Type)
+	}
+	if filter.AlgorithmNamePlaced != "" {
+		addCondition("algorithm_name_placed = ?", filter.AlgorithmNamePlaced)
+	}
+
+	return conditions, args
+}
+
+/*
+StreamOrderHistory passes the current state of every order matching the filter to fn, ordered by placement time
+as requested by the filter sort. Orders are read from ClickHouse as fn consumes them, limit and cursor are ignored.
+Stops at the first error returned by fn.
+*/
+func (ori *orderRepositoryImpl) StreamOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error {
+	conditions, args := historyConditions(filter)
+
+	direction := "ASC"
+	if filter.Sort == models.SortDesc {
+		direction = "DESC"
+	}
+
+	rows, err := ori.db.Raw(fmt.Sprintf(`
+			SELECT * FROM (
+				SELECT * FROM history_orders
+				WHERE %s
+				ORDER BY order_id, updated_at DESC
+				LIMIT 1 BY order_id
+			)
+			ORDER BY time_placed %s, order_id %s`, strings.Join(conditions, " AND "), direction, direction), args...).
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order models.HistoryOrder
+		if err := ori.db.ScanRows(rows, &order); err != nil {
+			return err
+		}
+		if err := fn(&order); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+/*
+StreamOrders passes every order book snapshot of the exchange and trading pair received in [from, to) to fn,
+in the order of FindOrder. Zero from or to leaves the range open. Snapshots are read from ClickHouse as fn consumes them.
+Stops at the first error returned by fn.
+*/
+func (ori *orderRepositoryImpl) StreamOrders(exchangeName, pair string, from, to time.Time, fn func(*models.OrderBook) error) error {
+	window, windowArgs := timeWindow("received_time", from, to)
+
+	rows, err := ori.db.Model(&models.OrderBook{}).
+		Where("exchange = ?", exchangeName).
+		Where("pair = ?", pair).
+		Where(window, windowArgs...).
+		Order("exchange_time, sequence, received_time").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var order models.OrderBook
+		if err := ori.db.ScanRows(rows, &order); err != nil {
+			return err
+		}
+		if err := fn(&order); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// matchCondition compares column to a value, by pattern if the value contains * wildcards.
+func matchCondition(column, value string) string {
+	if strings.Contains(value, "*") {
+		return column + " LIKE ?"
+	}
+	return column + " = ?"
+}
+
+// wildcardPattern converts * wildcards to a LIKE pattern, escaping LIKE special characters.
+func wildcardPattern(value string) string {
+	if !strings.Contains(value, "*") {
+		return value
+	}
+
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return escaper.Replace(value)
+}
+
+/*
+FindHistoryOrder retrieves the current state of an order by its order ID.
+Returns gorm.ErrRecordNotFound if no order with the ID exists.
+*/
+func (ori *orderRepositoryImpl) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	var orders []*models.HistoryOrder
+	tx := ori.db.Where("order_id = ?", orderID).
+		Order("updated_at DESC").
+		Limit(1).
+		Find(&orders)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
 	if tx.RowsAffected == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
 
+	return orders[0], nil
+}
+
+/*
+FindHistoryOrders retrieves the current state of every stored order of the given order IDs, in no particular order.
+Order IDs that are not stored are left out, an empty slice is returned if none is.
+*/
+func (ori *orderRepositoryImpl) FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error) {
+	orders := []*models.HistoryOrder{}
+	if len(orderIDs) == 0 {
+		return orders, nil
+	}
+
+	tx := ori.db.Raw(`
+			SELECT * FROM history_orders
+			WHERE order_id IN ?
+			ORDER BY updated_at DESC
+			LIMIT 1 BY order_id`, orderIDs).
+		Scan(&orders)
+
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 
-	return orderHistory, nil
+	return orders, nil
+}
+
+/*
+FindAlgorithms retrieves every algorithm that placed orders, with the number of orders it placed and when, ordered by name.
+Returns an empty slice if no order has an algorithm name.
+*/
+func (ori *orderRepositoryImpl) FindAlgorithms() ([]*models.Algorithm, error) {
+	algorithms := []*models.Algorithm{}
+	tx := ori.db.Raw(`
+			SELECT
+				algorithm_name_placed AS name,
+				uniqExact(order_id) AS orders,
+				min(time_placed) AS first_placed,
+				max(time_placed) AS last_placed
+			FROM history_orders
+			WHERE algorithm_name_placed != ''
+			GROUP BY algorithm_name_placed
+			ORDER BY name`).
+		Scan(&algorithms)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return algorithms, nil
+}
+
+/*
+FindAlgorithmVolume aggregates the current state of the orders an algorithm placed at or after from and before to.
+Volumes are the filled quantity and notional of the fills of the orders, FILLED orders without fills count
+in full at their price, and orders are charged the share of their commission for that execution.
+Zero from or to leave the window open on that side.
+Returns gorm.ErrRecordNotFound if the algorithm placed no orders in the window.
+*/
+func (ori *orderRepositoryImpl) FindAlgorithmVolume(name string, from, to time.Time) (*models.AlgorithmVolume, error) {
+	window, args := timeWindow("time_placed", from, to)
+
+	var volume models.AlgorithmVolume
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT
+				count() AS orders,
+				countIf(lower(o.side) = 'buy') AS buy_orders,
+				countIf(lower(o.side) = 'sell') AS sell_orders,
+				countIf(o.status = 'FILLED') AS filled_orders,
+				countIf(o.status = 'CANCELLED') AS cancelled_orders,
+				countIf(o.status = 'REJECTED') AS rejected_orders,
+				sum(multiIf(
+					f.fill_count > 0, f.base_qty,
+					o.status = 'FILLED', o.base_qty,
+					toDecimal256(0, 18))) AS base_volume,
+				sum(multiIf(
+					f.fill_count > 0, f.notional,
+					o.status = 'FILLED', multiplyDecimal(o.base_qty, o.price, 18),
+					toDecimal256(0, 18))) AS quote_volume,
+				sum(%s) AS commission
+			FROM (
+				SELECT * FROM history_orders
+				WHERE algorithm_name_placed = ? AND %s
+				ORDER BY order_id, updated_at DESC
+				LIMIT 1 BY order_id
+			) AS o
+			LEFT JOIN (
+				SELECT
+					order_id,
+					count() AS fill_count,
+					sum(base_qty) AS base_qty,
+					sum(multiplyDecimal(base_qty, price, 18)) AS notional
+				FROM (SELECT * FROM fills LIMIT 1 BY order_id, fill_id)
+				GROUP BY order_id
+			) AS f ON o.order_id = f.order_id`, chargedCommission, window), append([]any{name}, args...)...).
+		Scan(&volume)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if volume.Orders == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &volume, nil
+}
+
+/*
+FindAlgorithmExecutions retrieves the current state of the buy and sell orders an algorithm placed at or after from
+and before to that executed, those with fills or FILLED, ordered by placement time and order ID after the cursor.
+Orders that never executed are left in the database, they do not change PnL or execution quality.
+Zero from or to leave the window open on that side. Returns an empty slice once there are no more orders.
+*/
+func (ori *orderRepositoryImpl) FindAlgorithmExecutions(name string, from, to time.Time, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error) {
+	window, windowArgs := timeWindow("time_placed", from, to)
+
+	cursor := "1"
+	var cursorArgs []any
+	if after != nil {
+		cursor = "(time_placed, order_id) > (?, ?)"
+		cursorArgs = []any{after.TimePlaced, after.OrderID}
+	}
+
+	args := append([]any{name}, windowArgs...)
+	args = append(args, name)
+	args = append(args, windowArgs...)
+	args = append(args, cursorArgs...)
+	args = append(args, limit)
+
+	var orders []*models.HistoryOrder
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT * FROM (
+				SELECT * FROM history_orders
+				WHERE algorithm_name_placed = ? AND %s
+				ORDER BY order_id, updated_at DESC
+				LIMIT 1 BY order_id
+			)
+			WHERE lower(side) IN ('buy', 'sell')
+				AND (status = 'FILLED' OR order_id IN (
+					SELECT order_id FROM fills
+					WHERE order_id IN (SELECT order_id FROM history_orders WHERE algorithm_name_placed = ? AND %s)
+				))
+				AND %s
+			ORDER BY time_placed, order_id
+			LIMIT ?`, window, window, cursor), args...).
+		Scan(&orders)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return orders, nil
 }
 
 /*
@@ -92,3 +547,254 @@ func (ori *orderRepositoryImpl) SaveOrderHistory(order models.HistoryOrder) erro
 
 	return nil
 }
+
+/*
+SaveOrderHistories saves a batch of order history records to the database in a single insert.
+Returns an error if the operation fails.
+*/
+func (ori *orderRepositoryImpl) SaveOrderHistories(orders []models.HistoryOrder) error {
+	tx := ori.db.Create(&orders)
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+/*
+FindFills retrieves the fills of the given orders, ordered by order and execution time.
+Fills stored more than once are returned once.
+Returns an empty slice if the orders have no fills.
+*/
+func (ori *orderRepositoryImpl) FindFills(orderIDs []string) ([]*models.Fill, error) {
+	var fills []*models.Fill
+	if len(orderIDs) == 0 {
+		return fills, nil
+	}
+
+	tx := ori.db.Raw(`
+			SELECT * FROM (
+				SELECT * FROM fills
+				WHERE order_id IN ?
+				LIMIT 1 BY order_id, fill_id
+			)
+			ORDER BY order_id, executed_at, fill_id`, orderIDs).
+		Scan(&fills)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return fills, nil
+}
+
+/*
+SaveFills saves a batch of fills to the database.
+Returns an error if the operation fails.
+*/
+func (ori *orderRepositoryImpl) SaveFills(fills []models.Fill) error {
+	tx := ori.db.Create(&fills)
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
+
+/*
+FindTradeCandles merges the minute candles of the fills of a pair on an exchange into candles of the interval, oldest first.
+Zero from or to leave the window open on that side.
+Returns an empty slice if there are no fills in the window.
+*/
+func (ori *orderRepositoryImpl) FindTradeCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.Candle, error) {
+	window, args := timeWindow("bucket", from, to)
+
+	candles := []*models.Candle{}
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT
+				toStartOfInterval(bucket, INTERVAL %d SECOND) AS time,
+				argMinMerge(open) AS open,
+				maxMerge(high) AS high,
+				minMerge(low) AS low,
+				argMaxMerge(close) AS close,
+				sumMerge(volume) AS volume,
+				sumMerge(quote_volume) AS quote_volume,
+				countMerge(trades) AS trades
+			FROM trade_candles
+			WHERE exchange = ? AND pair = ? AND %s
+			GROUP BY time
+			ORDER BY time`, int64(interval.Seconds()), window), append([]any{exchangeName, pair}, args...)...).
+		Scan(&candles)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return candles, nil
+}
+
+/*
+FindMidCandles merges the minute candles of the mid price of a pair on an exchange into candles of the interval, oldest first.
+Zero from or to leave the window open on that side.
+Returns an empty slice if there are no order books in the window.
+*/
+func (ori *orderRepositoryImpl) FindMidCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.MidCandle, error) {
+	window, args := timeWindow("bucket", from, to)
+
+	candles := []*models.MidCandle{}
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT
+				toStartOfInterval(bucket, INTERVAL %d SECOND) AS time,
+				argMinMerge(open) AS open,
+				maxMerge(high) AS high,
+				minMerge(low) AS low,
+				argMaxMerge(close) AS close,
+				countMerge(snapshots) AS snapshots
+			FROM order_book_mid_candles
+			WHERE exchange = ? AND pair = ? AND %s
+			GROUP BY time
+			ORDER BY time`, int64(interval.Seconds()), window), append([]any{exchangeName, pair}, args...)...).
+		Scan(&candles)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return candles, nil
+}
+
+/*
+FindFeeReport sums the commissions and executed notional of the current state of the orders matching the filter,
+grouped by the period they were placed in, client, exchange, pair and label, in that order.
+Orders are charged the share of their commission for their executed quantity, as HistoryOrder.CommissionFor.
+Returns an empty slice if no order matches.
+*/
+func (ori *orderRepositoryImpl) FindFeeReport(filter *models.FeeReportFilter) ([]*models.FeeReportRow, error) {
+	period := "toStartOfMonth(time_placed, 'UTC')"
+	if filter.Period == models.FeePeriodDay {
+		period = "toDate(time_placed, 'UTC')"
+	}
+
+	window, args := timeWindow("time_placed", filter.From, filter.To)
+	conditions := []string{window}
+
+	addCondition := func(condition string, value any) {
+		conditions = append(conditions, condition)
+		args = append(args, value)
+	}
+
+	if filter.ClientName != "" {
+		addCondition("client_name = ?", filter.ClientName)
+	}
+	if filter.ExchangeName != "" {
+		addCondition("exchange_name = ?", filter.ExchangeName)
+	}
+	if filter.Label != "" {
+		addCondition(matchCondition("label", filter.Label), wildcardPattern(filter.Label))
+	}
+	if filter.Pair != "" {
+		addCondition(matchCondition("pair", filter.Pair), wildcardPattern(filter.Pair))
+	}
+
+	rows := []*models.FeeReportRow{}
+	tx := ori.db.Raw(fmt.Sprintf(`
+			SELECT
+				%s AS period_start,
+				o.client_name AS client_name,
+				o.exchange_name AS exchange_name,
+				o.pair AS pair,
+				o.label AS label,
+				count() AS orders,
+				sum(multiIf(
+					f.fill_count > 0, f.notional,
+					o.status = 'FILLED', multiplyDecimal(o.base_qty, o.price, 18),
+					toDecimal256(0, 18))) AS notional,
+				sum(%s) AS commission
+			FROM (
+				SELECT * FROM history_orders
+				WHERE %s
+				ORDER BY order_id, updated_at DESC
+				LIMIT 1 BY order_id
+			) AS o
+			LEFT JOIN (
+				SELECT
+					order_id,
+					count() AS fill_count,
+					sum(base_qty) AS base_qty,
+					sum(multiplyDecimal(base_qty, price, 18)) AS notional
+				FROM (SELECT * FROM fills LIMIT 1 BY order_id, fill_id)
+				GROUP BY order_id
+			) AS f ON o.order_id = f.order_id
+			GROUP BY period_start, client_name, exchange_name, pair, label
+			ORDER BY period_start, client_name, exchange_name, pair, label`, period, chargedCommission, strings.Join(conditions, " AND ")), args...).
+		Scan(&rows)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	return rows, nil
+}
+
+/*
+chargedCommission is the share of the commission of order o charged for its executed quantity, as HistoryOrder.CommissionFor,
+given the fill count and filled quantity f of the order. Orders without fills have executed in full only if FILLED.
+*/
+const chargedCommission = `multiIf(
+					f.fill_count > 0 AND f.base_qty < o.base_qty,
+						divideDecimal(multiplyDecimal(o.commission_quote_qty, f.base_qty, 18), o.base_qty, 18),
+					f.fill_count > 0 OR o.status = 'FILLED', o.commission_quote_qty,
+					toDecimal256(0, 18))`
+
+// timeWindow returns the condition selecting column values at or after from and before to, and its arguments.
+func timeWindow(column string, from, to time.Time) (string, []any) {
+	conditions := []string{"1"}
+	var args []any
+	if !from.IsZero() {
+		conditions = append(conditions, column+" >= ?")
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		conditions = append(conditions, column+" < ?")
+		args = append(args, to)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+/*
+FindPairPrecision retrieves the most recent precision of a trading pair on an exchange.
+Returns gorm.ErrRecordNotFound if no precision is stored for the pair.
+*/
+func (ori *orderRepositoryImpl) FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	var precision []*models.PairPrecision
+	tx := ori.db.Where("exchange = ?", exchangeName).
+		Where("pair = ?", pair).
+		Order("updated_at DESC").
+		Limit(1).
+		Find(&precision)
+
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return precision[0], nil
+}
+
+/*
+SavePairPrecision saves the precision of a trading pair, replacing any previous one.
+Returns an error if the operation fails.
+*/
+func (ori *orderRepositoryImpl) SavePairPrecision(precision models.PairPrecision) error {
+	tx := ori.db.Create(&precision)
+
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	return nil
+}
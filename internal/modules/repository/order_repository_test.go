@@ -3,13 +3,17 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"testing"
+	"time"
 
+	database "github.com/kymaka/vortex-test/internal/infrastructure/db"
 	"github.com/kymaka/vortex-test/internal/models"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	c "gorm.io/driver/clickhouse"
 	"gorm.io/gorm"
@@ -45,7 +49,7 @@ func setupTestDB(t *testing.T) (*gorm.DB, error) {
 		return nil, err
 	}
 	// Migrate the schema
-	db.AutoMigrate(&models.HistoryOrder{}, &models.OrderBook{})
+	db.AutoMigrate(&models.HistoryOrder{}, &models.OrderBook{}, &models.PairPrecision{}, &models.Fill{})
 	return db, nil
 }
 
@@ -81,6 +85,92 @@ func TestFindOrder(t *testing.T) {
 	assert.Equal(t, pair, foundOrder[0].Pair)
 }
 
+func TestFindOrder_OrderedBySequence(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	exchangeTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, sequence := range []int64{3, 1, 2} {
+		order := models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", ExchangeTime: exchangeTime, Sequence: sequence}
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("failed to create test order: %v", err)
+		}
+	}
+
+	foundOrder, err := repo.FindOrder("test_exchange", "BTC/USD")
+	assert.NoError(t, err)
+	assert.Len(t, foundOrder, 3)
+	assert.Equal(t, int64(1), foundOrder[0].Sequence)
+	assert.Equal(t, int64(2), foundOrder[1].Sequence)
+	assert.Equal(t, int64(3), foundOrder[2].Sequence)
+}
+
+func TestFindLatestOrder(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := int64(0); i < 3; i++ {
+		order := models.OrderBook{
+			Exchange:     "test_exchange",
+			Pair:         "BTC/USD",
+			ExchangeTime: start.Add(time.Duration(i) * time.Minute),
+			Sequence:     i,
+		}
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("failed to create test order: %v", err)
+		}
+	}
+
+	latest, err := repo.FindLatestOrder("test_exchange", "BTC/USD", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), latest.Sequence)
+
+	asOf, err := repo.FindLatestOrder("test_exchange", "BTC/USD", start.Add(90*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), asOf.Sequence)
+
+	_, err = repo.FindLatestOrder("test_exchange", "BTC/USD", start.Add(-time.Minute))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestFindLatestOrdersByPair(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	orders := []models.OrderBook{
+		{Exchange: "exchange_a", Pair: "BTC/USD", Sequence: 1},
+		{Exchange: "exchange_a", Pair: "BTC/USD", Sequence: 2},
+		{Exchange: "exchange_b", Pair: "BTC/USD", Sequence: 5},
+		{Exchange: "exchange_b", Pair: "ETH/USD", Sequence: 9},
+	}
+	for _, order := range orders {
+		if err := db.Create(&order).Error; err != nil {
+			t.Fatalf("failed to create test order: %v", err)
+		}
+	}
+
+	found, err := repo.FindLatestOrdersByPair("BTC/USD")
+	assert.NoError(t, err)
+	assert.Len(t, found, 2)
+	assert.Equal(t, "exchange_a", found[0].Exchange)
+	assert.Equal(t, int64(2), found[0].Sequence)
+	assert.Equal(t, "exchange_b", found[1].Exchange)
+	assert.Equal(t, int64(5), found[1].Sequence)
+}
+
 func TestSaveOrder(t *testing.T) {
 	db, err := setupTestDB(t)
 	if err != nil {
@@ -103,6 +193,35 @@ func TestSaveOrder(t *testing.T) {
 	assert.Equal(t, order.Pair, savedOrder.Pair)
 }
 
+func TestSaveOrder_Levels(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	order := models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks: models.Tuples{
+			{decimal.RequireFromString("101.5"), decimal.RequireFromString("2")},
+			{decimal.RequireFromString("102"), decimal.RequireFromString("0.25")},
+		},
+		Bids: models.Tuples{
+			{decimal.RequireFromString("100"), decimal.RequireFromString("1.5")},
+		},
+	}
+
+	err = repo.SaveOrder(order)
+	assert.NoError(t, err)
+
+	foundOrder, err := repo.FindOrder(order.Exchange, order.Pair)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprint(order.Asks), fmt.Sprint(foundOrder[0].Asks))
+	assert.Equal(t, fmt.Sprint(order.Bids), fmt.Sprint(foundOrder[0].Bids))
+}
+
 func TestFindOrderHistory(t *testing.T) {
 	db, err := setupTestDB(t)
 	if err != nil {
@@ -111,9 +230,10 @@ func TestFindOrderHistory(t *testing.T) {
 	defer teardownTestDB(db)
 
 	repo := NewOrderRepository(db)
-	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Label: "test_label", Pair: "BTC/USD"}
+	client := &models.OrderHistoryFilter{ClientName: "test_client", ExchangeName: "test_exchange", Label: "test_label", Pair: "BTC/USD"}
 
 	orderHistory := models.HistoryOrder{
+		OrderID:      "order-1",
 		ClientName:   client.ClientName,
 		ExchangeName: client.ExchangeName,
 		Label:        client.Label,
@@ -123,7 +243,7 @@ func TestFindOrderHistory(t *testing.T) {
 		t.Fatalf("failed to create test order history: %v", err)
 	}
 
-	foundHistory, err := repo.FindOrderHistory(client)
+	foundHistory, err := repo.FindOrderHistory(client, nil, 10)
 	assert.NoError(t, err)
 	assert.NotNil(t, foundHistory)
 	assert.Equal(t, client.ClientName, foundHistory[0].ClientName)
@@ -132,6 +252,65 @@ func TestFindOrderHistory(t *testing.T) {
 	assert.Equal(t, client.Pair, foundHistory[0].Pair)
 }
 
+func TestFindOrderHistory_Filters(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []models.HistoryOrder{
+		{OrderID: "order-1", Pair: "BTC/USD", Side: "buy", TimePlaced: placedAt},
+		{OrderID: "order-2", Pair: "BTC/EUR", Side: "sell", TimePlaced: placedAt.Add(time.Minute)},
+		{OrderID: "order-3", Pair: "ETH/USD", Side: "buy", TimePlaced: placedAt.Add(2 * time.Minute)},
+		{OrderID: "order-4", Pair: "BTC_USD", Side: "buy", TimePlaced: placedAt.Add(3 * time.Minute)},
+	}
+	for _, order := range orders {
+		order.ClientName = "test_client"
+		order.UpdatedAt = order.TimePlaced
+		assert.NoError(t, repo.SaveOrderHistory(order))
+	}
+
+	orderIDs := func(history []*models.HistoryOrder) []string {
+		ids := make([]string, len(history))
+		for i, order := range history {
+			ids[i] = order.OrderID
+		}
+		return ids
+	}
+
+	history, err := repo.FindOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client", Pair: "BTC/*"}, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-1", "order-2", "order-4"}, orderIDs(history))
+
+	// _ is not a wildcard
+	history, err = repo.FindOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client", Pair: "BTC_*"}, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-4"}, orderIDs(history))
+
+	history, err = repo.FindOrderHistory(&models.OrderHistoryFilter{
+		ClientName: "test_client",
+		Side:       "buy",
+		From:       placedAt.Add(time.Minute),
+		To:         placedAt.Add(3 * time.Minute),
+	}, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-3"}, orderIDs(history))
+
+	filter := &models.OrderHistoryFilter{ClientName: "test_client", Sort: models.SortDesc}
+	history, err = repo.FindOrderHistory(filter, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-4", "order-3"}, orderIDs(history))
+
+	after := &models.OrderHistoryCursor{TimePlaced: history[1].TimePlaced, OrderID: history[1].OrderID, Sort: models.SortDesc}
+	history, err = repo.FindOrderHistory(filter, after, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-2", "order-1"}, orderIDs(history))
+}
+
 func TestSaveOrderHistory(t *testing.T) {
 	db, err := setupTestDB(t)
 	if err != nil {
@@ -163,3 +342,434 @@ func TestSaveOrderHistory(t *testing.T) {
 	assert.Equal(t, order.Label, savedOrderHistory.Label)
 	assert.Equal(t, order.Pair, savedOrderHistory.Pair)
 }
+
+func TestFindPairPrecision(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	_, err = repo.FindPairPrecision("test_exchange", "BTC/USD")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err = repo.SavePairPrecision(models.PairPrecision{Exchange: "test_exchange", Pair: "BTC/USD", PriceScale: 2, QtyScale: 6, UpdatedAt: updatedAt})
+	assert.NoError(t, err)
+	err = repo.SavePairPrecision(models.PairPrecision{Exchange: "test_exchange", Pair: "BTC/USD", PriceScale: 1, QtyScale: 4, UpdatedAt: updatedAt.Add(time.Hour)})
+	assert.NoError(t, err)
+
+	precision, err := repo.FindPairPrecision("test_exchange", "BTC/USD")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), precision.PriceScale)
+	assert.Equal(t, int32(4), precision.QtyScale)
+}
+
+func TestFindOrderHistory_CurrentState(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	client := &models.OrderHistoryFilter{ClientName: "test_client", ExchangeName: "test_exchange", Label: "test_label", Pair: "BTC/USD"}
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	order := models.HistoryOrder{
+		OrderID:      "order-1",
+		ClientName:   client.ClientName,
+		ExchangeName: client.ExchangeName,
+		Label:        client.Label,
+		Pair:         client.Pair,
+		TimePlaced:   placedAt,
+		Status:       models.OrderStatusNew,
+		UpdatedAt:    placedAt,
+	}
+	assert.NoError(t, repo.SaveOrderHistory(order))
+
+	order.Status = models.OrderStatusFilled
+	order.UpdatedAt = placedAt.Add(time.Minute)
+	assert.NoError(t, repo.SaveOrderHistory(order))
+
+	other := order
+	other.OrderID = "order-2"
+	other.Status = models.OrderStatusNew
+	assert.NoError(t, repo.SaveOrderHistory(other))
+
+	history, err := repo.FindOrderHistory(client, nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, "order-1", history[0].OrderID)
+	assert.Equal(t, models.OrderStatusFilled, history[0].Status)
+
+	current, err := repo.FindHistoryOrder("order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusFilled, current.Status)
+
+	_, err = repo.FindHistoryOrder("missing")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	orders, err := repo.FindHistoryOrders([]string{"order-1", "order-2", "missing"})
+	assert.NoError(t, err)
+	statuses := make(map[string]string)
+	for _, order := range orders {
+		statuses[order.OrderID] = order.Status
+	}
+	assert.Equal(t, map[string]string{"order-1": models.OrderStatusFilled, "order-2": models.OrderStatusNew}, statuses)
+}
+
+func TestStreamOrderHistory(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []models.HistoryOrder{
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusNew, TimePlaced: placedAt, UpdatedAt: placedAt},
+		{OrderID: "order-2", ClientName: "test_client", Status: models.OrderStatusNew, TimePlaced: placedAt.Add(time.Second), UpdatedAt: placedAt.Add(time.Second)},
+		{OrderID: "order-3", ClientName: "other_client", Status: models.OrderStatusNew, TimePlaced: placedAt, UpdatedAt: placedAt},
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusFilled, TimePlaced: placedAt, UpdatedAt: placedAt.Add(time.Minute)},
+	}
+	assert.NoError(t, repo.SaveOrderHistories(orders))
+
+	var streamed []*models.HistoryOrder
+	err = repo.StreamOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client", Sort: models.SortDesc}, func(order *models.HistoryOrder) error {
+		streamed = append(streamed, order)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, streamed, 2) {
+		assert.Equal(t, "order-2", streamed[0].OrderID)
+		assert.Equal(t, "order-1", streamed[1].OrderID)
+		assert.Equal(t, models.OrderStatusFilled, streamed[1].Status)
+	}
+
+	// Errors of fn stop the stream
+	stop := errors.New("stop")
+	err = repo.StreamOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client"}, func(order *models.HistoryOrder) error {
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestFindFills(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	fills, err := repo.FindFills(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, fills)
+
+	executedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fill := models.Fill{
+		FillID:     "fill-1",
+		OrderID:    "order-1",
+		BaseQty:    decimal.RequireFromString("0.5"),
+		Price:      decimal.RequireFromString("100.25"),
+		Fee:        decimal.RequireFromString("0.01"),
+		FeeAsset:   "USD",
+		Liquidity:  models.LiquidityMaker,
+		ExecutedAt: executedAt,
+	}
+	other := fill
+	other.FillID = "fill-2"
+	other.ExecutedAt = executedAt.Add(-time.Second)
+
+	// A fill stored twice is returned once
+	assert.NoError(t, repo.SaveFills([]models.Fill{fill, other}))
+	assert.NoError(t, repo.SaveFills([]models.Fill{fill}))
+
+	fills, err = repo.FindFills([]string{"order-1", "order-2"})
+	assert.NoError(t, err)
+	assert.Len(t, fills, 2)
+	assert.Equal(t, "fill-2", fills[0].FillID)
+	assert.Equal(t, "100.25", fills[1].Price.String())
+}
+
+func TestFindAlgorithmVolume(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	buy := models.HistoryOrder{
+		OrderID:             "order-1",
+		ClientName:          "client-a",
+		AlgorithmNamePlaced: "twap",
+		Side:                "buy",
+		BaseQty:             decimal.NewFromInt(2),
+		Price:               decimal.RequireFromString("100.5"),
+		CommissionQuoteQty:  decimal.RequireFromString("0.2"),
+		TimePlaced:          placedAt,
+		Status:              models.OrderStatusNew,
+		UpdatedAt:           placedAt,
+	}
+	assert.NoError(t, repo.SaveOrderHistory(buy))
+
+	buy.Status = models.OrderStatusFilled
+	buy.UpdatedAt = placedAt.Add(time.Minute)
+	assert.NoError(t, repo.SaveOrderHistory(buy))
+
+	sell := buy
+	sell.OrderID = "order-2"
+	sell.ClientName = "client-b"
+	sell.Side = "SELL"
+	sell.Status = models.OrderStatusCancelled
+	assert.NoError(t, repo.SaveOrderHistory(sell))
+
+	// order-2 was cancelled after partially filling
+	assert.NoError(t, repo.SaveFills([]models.Fill{{
+		FillID:     "fill-1",
+		OrderID:    "order-2",
+		BaseQty:    decimal.RequireFromString("0.5"),
+		Price:      decimal.NewFromInt(101),
+		ExecutedAt: placedAt.Add(time.Minute),
+	}}))
+
+	late := buy
+	late.OrderID = "order-3"
+	late.TimePlaced = placedAt.Add(time.Hour)
+	assert.NoError(t, repo.SaveOrderHistory(late))
+
+	algorithms, err := repo.FindAlgorithms()
+	assert.NoError(t, err)
+	assert.Len(t, algorithms, 1)
+	assert.Equal(t, "twap", algorithms[0].Name)
+	assert.Equal(t, uint64(3), algorithms[0].Orders)
+
+	volume, err := repo.FindAlgorithmVolume("twap", time.Time{}, placedAt.Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), volume.Orders)
+	assert.Equal(t, uint64(1), volume.BuyOrders)
+	assert.Equal(t, uint64(1), volume.SellOrders)
+	assert.Equal(t, uint64(1), volume.FilledOrders)
+	assert.Equal(t, uint64(1), volume.CancelledOrders)
+	assert.Equal(t, "2.5", volume.BaseVolume.String())
+	assert.Equal(t, "251.5", volume.QuoteVolume.String())
+	// order-2 filled a quarter of its quantity and is charged a quarter of its commission
+	assert.Equal(t, "0.25", volume.Commission.String())
+
+	_, err = repo.FindAlgorithmVolume("vwap", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// order-4 never executed and is not loaded
+	placed := buy
+	placed.OrderID = "order-4"
+	placed.Status = models.OrderStatusNew
+	assert.NoError(t, repo.SaveOrderHistory(placed))
+
+	executions, err := repo.FindAlgorithmExecutions("twap", time.Time{}, placedAt.Add(time.Hour), nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 2)
+	assert.Equal(t, "order-1", executions[0].OrderID)
+	assert.Equal(t, models.OrderStatusFilled, executions[0].Status)
+	assert.Equal(t, "order-2", executions[1].OrderID)
+
+	executions, err = repo.FindAlgorithmExecutions("twap", time.Time{}, time.Time{},
+		&models.OrderHistoryCursor{TimePlaced: placedAt, OrderID: "order-1"}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, executions, 2)
+	assert.Equal(t, "order-2", executions[0].OrderID)
+	assert.Equal(t, "order-3", executions[1].OrderID)
+
+	executions, err = repo.FindAlgorithmExecutions("vwap", time.Time{}, time.Time{}, nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, executions)
+}
+
+func TestFindTradeCandles(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	assert.NoError(t, database.MigrateCandles(db))
+
+	repo := NewOrderRepository(db)
+
+	executedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	assert.NoError(t, repo.SaveFills([]models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(101), ExecutedAt: executedAt.Add(time.Second)},
+		{FillID: "fill-2", OrderID: "order-1", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(99), ExecutedAt: executedAt.Add(2 * time.Second)},
+		{FillID: "fill-3", OrderID: "order-2", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(104), ExecutedAt: executedAt.Add(3 * time.Minute)},
+		{FillID: "fill-4", OrderID: "order-3", ExchangeName: "test_exchange", Pair: "ETH/USD",
+			BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(5), ExecutedAt: executedAt},
+	}))
+
+	candles, err := repo.FindTradeCandles("test_exchange", "BTC/USD", 5*time.Minute, executedAt, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, candles, 1)
+	assert.Equal(t, executedAt, candles[0].Time.UTC())
+	assert.Equal(t, "101", candles[0].Open.String())
+	assert.Equal(t, "104", candles[0].High.String())
+	assert.Equal(t, "99", candles[0].Low.String())
+	assert.Equal(t, "104", candles[0].Close.String())
+	assert.Equal(t, "3", candles[0].Volume.String())
+	assert.Equal(t, "304", candles[0].QuoteVolume.String())
+	assert.Equal(t, uint64(3), candles[0].Trades)
+
+	candles, err = repo.FindTradeCandles("test_exchange", "BTC/USD", time.Minute, executedAt, time.Time{})
+	assert.NoError(t, err)
+	assert.Len(t, candles, 2)
+
+	candles, err = repo.FindTradeCandles("test_exchange", "BTC/USD", time.Minute, executedAt.Add(time.Hour), time.Time{})
+	assert.NoError(t, err)
+	assert.Empty(t, candles)
+}
+
+func TestFindFeeReport(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	filled := models.HistoryOrder{
+		OrderID:            "order-1",
+		ClientName:         "test_client",
+		ExchangeName:       "test_exchange",
+		Pair:               "BTC/USD",
+		Label:              "test_label",
+		BaseQty:            decimal.NewFromInt(2),
+		Price:              decimal.NewFromInt(100),
+		CommissionQuoteQty: decimal.RequireFromString("0.2"),
+		TimePlaced:         placedAt,
+		Status:             models.OrderStatusFilled,
+		UpdatedAt:          placedAt,
+	}
+	open := filled
+	open.OrderID = "order-2"
+	open.CommissionQuoteQty = decimal.RequireFromString("0.1")
+	open.Status = models.OrderStatusNew
+	cancelled := open
+	cancelled.OrderID = "order-3"
+	cancelled.Status = models.OrderStatusCancelled
+	nextMonth := open
+	nextMonth.OrderID = "order-4"
+	nextMonth.Status = models.OrderStatusFilled
+	nextMonth.TimePlaced = placedAt.AddDate(0, 1, 0)
+	for _, order := range []models.HistoryOrder{filled, open, cancelled, nextMonth} {
+		assert.NoError(t, repo.SaveOrderHistory(order))
+	}
+
+	// order-2 is still open with half of its quantity filled
+	assert.NoError(t, repo.SaveFills([]models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.NewFromInt(2), Price: decimal.NewFromInt(101), ExecutedAt: placedAt},
+		{FillID: "fill-2", OrderID: "order-2", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), ExecutedAt: placedAt},
+	}))
+
+	rows, err := repo.FindFeeReport(&models.FeeReportFilter{ClientName: "test_client", Period: models.FeePeriodMonth})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), rows[0].PeriodStart.UTC())
+	assert.Equal(t, uint64(3), rows[0].Orders)
+	assert.Equal(t, "302", rows[0].Notional.String())
+	assert.Equal(t, "0.25", rows[0].Commission.String())
+	assert.Equal(t, "200", rows[1].Notional.String())
+	assert.Equal(t, "0.1", rows[1].Commission.String())
+
+	rows, err = repo.FindFeeReport(&models.FeeReportFilter{ClientName: "other_client", Period: models.FeePeriodDay})
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func TestSaveOrderHistories(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+	client := &models.OrderHistoryFilter{ClientName: "test_client"}
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := make([]models.HistoryOrder, 3)
+	for i := range orders {
+		orders[i] = models.HistoryOrder{
+			OrderID:    fmt.Sprintf("order-%d", i),
+			ClientName: client.ClientName,
+			TimePlaced: placedAt.Add(time.Duration(i) * time.Second),
+			Status:     models.OrderStatusNew,
+			UpdatedAt:  placedAt,
+		}
+	}
+
+	assert.NoError(t, repo.SaveOrderHistories(orders))
+
+	history, err := repo.FindOrderHistory(client, nil, 10)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, "order-2", history[2].OrderID)
+}
+
+func TestFindOrdersSavedAfter(t *testing.T) {
+	db, err := setupTestDB(t)
+	if err != nil {
+		t.Fatalf("failed to set up test DB: %v", err)
+	}
+	defer teardownTestDB(db)
+
+	repo := NewOrderRepository(db)
+
+	ingestedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []models.HistoryOrder{
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusNew, UpdatedAt: ingestedAt, IngestedAt: ingestedAt},
+		{OrderID: "order-2", ClientName: "test_client", Status: models.OrderStatusNew, UpdatedAt: ingestedAt, IngestedAt: ingestedAt.Add(time.Millisecond)},
+		{OrderID: "order-3", ClientName: "other_client", Status: models.OrderStatusNew, UpdatedAt: ingestedAt, IngestedAt: ingestedAt.Add(2 * time.Millisecond)},
+		// A later state of an order saved before the position is replayed, even with an earlier update time
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusFilled, UpdatedAt: ingestedAt, IngestedAt: ingestedAt.Add(3 * time.Millisecond)},
+		{OrderID: "order-4", ClientName: "test_client", Status: models.OrderStatusNew, UpdatedAt: ingestedAt.Add(-time.Hour), IngestedAt: ingestedAt.Add(4 * time.Millisecond)},
+	}
+	assert.NoError(t, repo.SaveOrderHistories(orders))
+
+	filter := &models.OrderStreamFilter{ClientName: "test_client"}
+	after := &models.OrderEventPosition{IngestedAt: ingestedAt, OrderID: "order-1"}
+
+	saved, err := repo.FindOrdersSavedAfter(filter, after, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, saved, 3) {
+		assert.Equal(t, "order-2", saved[0].OrderID)
+		assert.Equal(t, "order-1", saved[1].OrderID)
+		assert.Equal(t, models.OrderStatusFilled, saved[1].Status)
+		assert.Equal(t, "order-4", saved[2].OrderID)
+	}
+
+	// The oldest states come first, the next page continues after the last of them
+	saved, err = repo.FindOrdersSavedAfter(filter, after, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, saved, 2) {
+		assert.Equal(t, "order-2", saved[0].OrderID)
+		assert.Equal(t, "order-1", saved[1].OrderID)
+	}
+
+	position := models.EventPosition(saved[1])
+	saved, err = repo.FindOrdersSavedAfter(filter, &position, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, saved, 1) {
+		assert.Equal(t, "order-4", saved[0].OrderID)
+	}
+}
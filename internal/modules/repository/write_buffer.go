@@ -0,0 +1,590 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBufferFull is returned when a row could not be buffered before the enqueue timeout because the buffer is full.
+	ErrBufferFull = errors.New("write buffer is full")
+	// ErrBufferClosed is returned for writes after the buffer was closed.
+	ErrBufferClosed = errors.New("write buffer is closed")
+)
+
+/*
+BufferConfig sets when buffered rows are flushed and how many may be pending.
+Rows of a kind are flushed once FlushSize of them are pending, and all rows at least every FlushInterval.
+Writers wait for space while Capacity rows are pending, for at most EnqueueTimeout.
+After a failed flush, the next one waits twice as long as the previous wait, starting at FlushInterval,
+up to MaxRetryBackoff. Rows are inserted at most MaxFlushRetries times before they are written to DeadLetter
+as JSON lines, or logged if DeadLetter is nil.
+*/
+type BufferConfig struct {
+	FlushSize       int
+	FlushInterval   time.Duration
+	Capacity        int
+	EnqueueTimeout  time.Duration
+	MaxFlushRetries int
+	MaxRetryBackoff time.Duration
+	DeadLetter      io.Writer
+}
+
+// DefaultBufferConfig is used for every limit of a BufferConfig that is not set.
+var DefaultBufferConfig = BufferConfig{
+	FlushSize:       1000,
+	FlushInterval:   time.Second,
+	Capacity:        10000,
+	EnqueueTimeout:  5 * time.Second,
+	MaxFlushRetries: 5,
+	MaxRetryBackoff: 30 * time.Second,
+}
+
+// BufferStats describes the queue depth and flushes of a write buffer.
+type BufferStats struct {
+	PendingOrderBooks    int     `json:"pendingOrderBooks"`
+	PendingHistoryOrders int     `json:"pendingHistoryOrders"`
+	Capacity             int     `json:"capacity"`
+	Flushes              uint64  `json:"flushes"`
+	FailedFlushes        uint64  `json:"failedFlushes"`
+	FlushedRows          uint64  `json:"flushedRows"`
+	RejectedRows         uint64  `json:"rejectedRows"`
+	DeadLetteredRows     uint64  `json:"deadLetteredRows"`
+	LastFlushMillis      float64 `json:"lastFlushMillis"`
+	MaxFlushMillis       float64 `json:"maxFlushMillis"`
+}
+
+/*
+BufferedOrderRepository accumulates saved order books and order history rows in memory
+and inserts them in batches, as ClickHouse creates a part per insert.
+All other methods go straight to the wrapped repository.
+Buffered order books are visible to FindLatestOrder, so deltas and PnL marks use the latest book,
+and not to other reads until flushed. Buffered order history rows are visible to FindHistoryOrder
+and FindHistoryOrders so status transitions are checked against the latest state,
+and to FindOrdersSavedAfter so the order history stream replays them.
+A batch that keeps failing is split in halves until the rows that cannot be inserted are isolated
+and dead-lettered, so that they do not hold back the other rows.
+Writes succeed once rows are buffered, so a dead-lettered row may already have been published
+to the order book and order history streams, and been read, though it is never stored.
+*/
+type BufferedOrderRepository struct {
+	OrderRepository
+
+	config BufferConfig
+	slots  chan struct{}
+	flush  chan struct{}
+	done   chan struct{}
+	closed chan struct{}
+
+	mu              sync.Mutex
+	isClosed        bool
+	books           []pendingRow[models.OrderBook]
+	history         []pendingRow[models.HistoryOrder]
+	flushingHistory []pendingRow[models.HistoryOrder]
+	flushingBooks   []pendingRow[models.OrderBook]
+	stats           BufferStats
+	flushErr        error
+	failedFlushes   int
+	retryAt         time.Time
+}
+
+// pendingRow is a buffered row with the number of times inserting it failed.
+type pendingRow[T any] struct {
+	row      T
+	attempts int
+}
+
+/*
+NewBufferedOrderRepository wraps repo with a write buffer and starts flushing it in the background.
+Close must be called to flush the remaining rows.
+*/
+func NewBufferedOrderRepository(repo OrderRepository, config BufferConfig) *BufferedOrderRepository {
+	if config.FlushSize <= 0 {
+		config.FlushSize = DefaultBufferConfig.FlushSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultBufferConfig.FlushInterval
+	}
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultBufferConfig.Capacity
+	}
+	if config.EnqueueTimeout <= 0 {
+		config.EnqueueTimeout = DefaultBufferConfig.EnqueueTimeout
+	}
+	if config.MaxFlushRetries <= 0 {
+		config.MaxFlushRetries = DefaultBufferConfig.MaxFlushRetries
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = DefaultBufferConfig.MaxRetryBackoff
+	}
+
+	b := &BufferedOrderRepository{
+		OrderRepository: repo,
+		config:          config,
+		slots:           make(chan struct{}, config.Capacity),
+		flush:           make(chan struct{}, 1),
+		done:            make(chan struct{}),
+		closed:          make(chan struct{}),
+	}
+	b.stats.Capacity = config.Capacity
+
+	go b.run()
+	return b
+}
+
+/*
+SaveOrder buffers an order book, waiting for space if the buffer is full.
+Returns ErrBufferFull if there is no space before the enqueue timeout, or ErrBufferClosed.
+*/
+func (b *BufferedOrderRepository) SaveOrder(order models.OrderBook) error {
+	return b.enqueue(func() int {
+		b.books = append(b.books, pendingRow[models.OrderBook]{row: order})
+		return len(b.books)
+	})
+}
+
+/*
+SaveOrderHistory buffers an order history row, waiting for space if the buffer is full.
+Returns ErrBufferFull if there is no space before the enqueue timeout, or ErrBufferClosed.
+*/
+func (b *BufferedOrderRepository) SaveOrderHistory(order models.HistoryOrder) error {
+	return b.enqueue(func() int {
+		b.history = append(b.history, pendingRow[models.HistoryOrder]{row: order})
+		return len(b.history)
+	})
+}
+
+/*
+FindHistoryOrder retrieves the current state of an order, including buffered rows that are not stored yet.
+Returns gorm.ErrRecordNotFound if no order with the ID exists.
+*/
+func (b *BufferedOrderRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	pending := b.pendingHistoryOrder(orderID)
+
+	stored, err := b.OrderRepository.FindHistoryOrder(orderID)
+	if err != nil {
+		if pending != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+			return pending, nil
+		}
+		return nil, err
+	}
+
+	if pending != nil && pending.UpdatedAt.After(stored.UpdatedAt) {
+		return pending, nil
+	}
+	return stored, nil
+}
+
+/*
+FindHistoryOrders retrieves the current state of every stored or buffered order of the given order IDs, in no particular order.
+*/
+func (b *BufferedOrderRepository) FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error) {
+	pending := b.pendingHistoryOrders(orderIDs)
+
+	stored, err := b.OrderRepository.FindHistoryOrders(orderIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]*models.HistoryOrder, 0, len(stored)+len(pending))
+	for _, order := range stored {
+		if latest, ok := pending[order.OrderID]; ok && latest.UpdatedAt.After(order.UpdatedAt) {
+			continue
+		}
+		delete(pending, order.OrderID)
+		orders = append(orders, order)
+	}
+	for _, order := range pending {
+		orders = append(orders, order)
+	}
+	return orders, nil
+}
+
+/*
+FindLatestOrder retrieves the order book snapshot in effect at asOf for the exchange and trading pair,
+including buffered snapshots that are not stored yet. A zero asOf returns the most recent snapshot.
+Returns gorm.ErrRecordNotFound if there is no such snapshot.
+*/
+func (b *BufferedOrderRepository) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	pending := b.pendingOrderBook(exchangeName, pair, asOf)
+
+	stored, err := b.OrderRepository.FindLatestOrder(exchangeName, pair, asOf)
+	if err != nil {
+		if pending != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+			return pending, nil
+		}
+		return nil, err
+	}
+
+	if pending != nil && bookBefore(stored, pending) {
+		return pending, nil
+	}
+	return stored, nil
+}
+
+/*
+FindOrdersSavedAfter retrieves the first limit order states ingested after the position, oldest first,
+including buffered rows that are not stored yet.
+*/
+func (b *BufferedOrderRepository) FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error) {
+	pending := b.pendingHistoryOrdersAfter(filter, after)
+
+	orders, err := b.OrderRepository.FindOrdersSavedAfter(filter, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rows flushed while the stored rows were read are returned by both
+	stored := make(map[models.OrderEventPosition]bool, len(orders))
+	for _, order := range orders {
+		stored[models.EventPosition(order)] = true
+	}
+	for _, order := range pending {
+		if !stored[models.EventPosition(order)] {
+			orders = append(orders, order)
+		}
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool {
+		return models.EventPosition(orders[i]).Before(models.EventPosition(orders[j]))
+	})
+	if len(orders) > limit {
+		orders = orders[:limit]
+	}
+	return orders, nil
+}
+
+// Stats returns the current queue depth and flush statistics of the buffer.
+func (b *BufferedOrderRepository) Stats() BufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := b.stats
+	stats.PendingOrderBooks = len(b.books) + len(b.flushingBooks)
+	stats.PendingHistoryOrders = len(b.history) + len(b.flushingHistory)
+	return stats
+}
+
+/*
+Close stops accepting writes and flushes the buffered rows, rows that cannot be stored are dead-lettered.
+Returns the error of the last flush if rows could not be stored.
+*/
+func (b *BufferedOrderRepository) Close() error {
+	b.mu.Lock()
+	if b.isClosed {
+		b.mu.Unlock()
+		<-b.closed
+		return b.lastFlushErr()
+	}
+	b.isClosed = true
+	b.mu.Unlock()
+
+	close(b.done)
+	<-b.closed
+	return b.lastFlushErr()
+}
+
+func (b *BufferedOrderRepository) enqueue(add func() int) error {
+	timer := time.NewTimer(b.config.EnqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+	case <-timer.C:
+		b.mu.Lock()
+		b.stats.RejectedRows++
+		b.mu.Unlock()
+		return ErrBufferFull
+	case <-b.done:
+		return ErrBufferClosed
+	}
+
+	b.mu.Lock()
+	if b.isClosed {
+		b.mu.Unlock()
+		<-b.slots
+		return ErrBufferClosed
+	}
+	pending := add()
+	b.mu.Unlock()
+
+	if pending >= b.config.FlushSize {
+		select {
+		case b.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *BufferedOrderRepository) run() {
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.flushPending(false)
+		case <-b.flush:
+			b.flushPending(false)
+		case <-b.done:
+			b.flushPending(true)
+			close(b.closed)
+			return
+		}
+	}
+}
+
+/*
+flushPending inserts the buffered rows, one batch per kind, unless it is backing off after a failed flush.
+Rows of a failed insert stay buffered, ahead of newer rows, and are retried on a later flush.
+On the last flush, rows that cannot be stored are dead-lettered rather than kept.
+*/
+func (b *BufferedOrderRepository) flushPending(last bool) {
+	b.mu.Lock()
+	if !last && time.Now().Before(b.retryAt) {
+		b.mu.Unlock()
+		return
+	}
+	books := b.books
+	history := b.history
+	b.books = nil
+	b.history = nil
+	b.flushingHistory = history
+	b.flushingBooks = books
+	b.mu.Unlock()
+
+	if len(books) == 0 && len(history) == 0 {
+		return
+	}
+
+	maxAttempts := b.config.MaxFlushRetries
+	if last {
+		maxAttempts = 1
+	}
+
+	start := time.Now()
+	var booksErr, historyErr error
+	var retryBooks, deadBooks []pendingRow[models.OrderBook]
+	var retryHistory, deadHistory []pendingRow[models.HistoryOrder]
+	if len(books) > 0 {
+		retryBooks, deadBooks, booksErr = flushRows(books, b.OrderRepository.SaveOrders, maxAttempts)
+	}
+	if len(history) > 0 {
+		retryHistory, deadHistory, historyErr = flushRows(history, b.OrderRepository.SaveOrderHistories, maxAttempts)
+	}
+	latency := float64(time.Since(start).Microseconds()) / 1000
+
+	deadLettered := deadLetter(b.config.DeadLetter, "order_books", deadBooks, booksErr) +
+		deadLetter(b.config.DeadLetter, "history_orders", deadHistory, historyErr)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.books = append(retryBooks, b.books...)
+	b.history = append(retryHistory, b.history...)
+	stored := len(books) + len(history) - len(retryBooks) - len(retryHistory) - deadLettered
+	b.flushingHistory = nil
+	b.flushingBooks = nil
+
+	b.stats.Flushes++
+	b.stats.FlushedRows += uint64(stored)
+	b.stats.DeadLetteredRows += uint64(deadLettered)
+	b.stats.LastFlushMillis = latency
+	b.stats.MaxFlushMillis = max(b.stats.MaxFlushMillis, latency)
+	b.flushErr = errors.Join(booksErr, historyErr)
+	if b.flushErr != nil {
+		b.stats.FailedFlushes++
+		b.failedFlushes++
+		backoff := b.config.FlushInterval << min(b.failedFlushes-1, 30)
+		if backoff <= 0 || backoff > b.config.MaxRetryBackoff {
+			backoff = b.config.MaxRetryBackoff
+		}
+		b.retryAt = time.Now().Add(backoff)
+	} else {
+		b.failedFlushes = 0
+		b.retryAt = time.Time{}
+	}
+
+	for i := 0; i < stored+deadLettered; i++ {
+		<-b.slots
+	}
+}
+
+/*
+flushRows inserts rows in a single batch. If the insert fails, every row counts an attempt, and once a row
+has been attempted maxAttempts times the rows that cannot be inserted are isolated to be dead-lettered.
+Returns the rows to retry, the rows to dead-letter and the insert errors.
+*/
+func flushRows[T any](rows []pendingRow[T], save func([]T) error, maxAttempts int) ([]pendingRow[T], []pendingRow[T], error) {
+	err := saveRows(rows, save)
+	if err == nil {
+		return nil, nil, nil
+	}
+
+	exhausted := false
+	for i := range rows {
+		rows[i].attempts++
+		exhausted = exhausted || rows[i].attempts >= maxAttempts
+	}
+	if !exhausted {
+		return rows, nil, err
+	}
+
+	dead, err := isolateRows(rows, save, err)
+	return nil, dead, err
+}
+
+/*
+isolateRows splits rows whose insert failed with err in halves and inserts them on their own,
+until the rows that fail alone are found. Returns those rows and their errors.
+*/
+func isolateRows[T any](rows []pendingRow[T], save func([]T) error, err error) ([]pendingRow[T], error) {
+	if len(rows) == 1 {
+		return rows, err
+	}
+
+	half := len(rows) / 2
+	var dead []pendingRow[T]
+	var errs error
+	for _, part := range [][]pendingRow[T]{rows[:half], rows[half:]} {
+		if err := saveRows(part, save); err != nil {
+			partDead, partErr := isolateRows(part, save, err)
+			dead = append(dead, partDead...)
+			errs = errors.Join(errs, partErr)
+		}
+	}
+	return dead, errs
+}
+
+// saveRows inserts the values of rows in a single batch.
+func saveRows[T any](rows []pendingRow[T], save func([]T) error) error {
+	values := make([]T, len(rows))
+	for i := range rows {
+		values[i] = rows[i].row
+	}
+	return save(values)
+}
+
+// deadLetter writes rows that could not be inserted into table to w as JSON lines, or logs them if w is nil.
+func deadLetter[T any](w io.Writer, table string, rows []pendingRow[T], cause error) int {
+	for _, row := range rows {
+		line, err := json.Marshal(struct {
+			Table    string `json:"table"`
+			Error    string `json:"error"`
+			Attempts int    `json:"attempts"`
+			Row      T      `json:"row"`
+		}{table, cause.Error(), row.attempts, row.row})
+		if err != nil {
+			log.Printf("failed to encode dead-lettered row of %s: %v", table, err)
+			continue
+		}
+
+		if w == nil {
+			log.Printf("dead-lettered row: %s", line)
+			continue
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			log.Printf("failed to dead-letter row: %v: %s", err, line)
+		}
+	}
+	return len(rows)
+}
+
+/*
+pendingOrderBook returns the latest buffered snapshot of the exchange and pair with an exchange time
+not later than asOf, or any if asOf is zero. Returns nil if none is buffered.
+*/
+func (b *BufferedOrderRepository) pendingOrderBook(exchangeName, pair string, asOf time.Time) *models.OrderBook {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var latest *models.OrderBook
+	for _, rows := range [][]pendingRow[models.OrderBook]{b.flushingBooks, b.books} {
+		for i := range rows {
+			book := rows[i].row
+			if book.Exchange != exchangeName || book.Pair != pair || (!asOf.IsZero() && book.ExchangeTime.After(asOf)) {
+				continue
+			}
+			if latest == nil || !bookBefore(&book, latest) {
+				latest = &book
+			}
+		}
+	}
+	return latest
+}
+
+// bookBefore reports whether snapshot a precedes b by exchange time, sequence and received time, as stored books are ordered.
+func bookBefore(a, b *models.OrderBook) bool {
+	if !a.ExchangeTime.Equal(b.ExchangeTime) {
+		return a.ExchangeTime.Before(b.ExchangeTime)
+	}
+	if a.Sequence != b.Sequence {
+		return a.Sequence < b.Sequence
+	}
+	return a.ReceivedTime.Before(b.ReceivedTime)
+}
+
+// pendingHistoryOrder returns the latest buffered row of an order, or nil if none is buffered.
+func (b *BufferedOrderRepository) pendingHistoryOrder(orderID string) *models.HistoryOrder {
+	return b.pendingHistoryOrders([]string{orderID})[orderID]
+}
+
+// pendingHistoryOrders returns the latest buffered row of every given order that has one, by order ID.
+func (b *BufferedOrderRepository) pendingHistoryOrders(orderIDs []string) map[string]*models.HistoryOrder {
+	wanted := make(map[string]bool, len(orderIDs))
+	for _, orderID := range orderIDs {
+		wanted[orderID] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	latest := make(map[string]*models.HistoryOrder)
+	for _, rows := range [][]pendingRow[models.HistoryOrder]{b.flushingHistory, b.history} {
+		for i := range rows {
+			orderID := rows[i].row.OrderID
+			if !wanted[orderID] {
+				continue
+			}
+			if current, ok := latest[orderID]; !ok || !rows[i].row.UpdatedAt.Before(current.UpdatedAt) {
+				order := rows[i].row
+				latest[orderID] = &order
+			}
+		}
+	}
+	return latest
+}
+
+// pendingHistoryOrdersAfter returns the buffered rows matching the filter that were ingested after the position.
+func (b *BufferedOrderRepository) pendingHistoryOrdersAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition) []*models.HistoryOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var orders []*models.HistoryOrder
+	for _, rows := range [][]pendingRow[models.HistoryOrder]{b.flushingHistory, b.history} {
+		for i := range rows {
+			order := rows[i].row
+			if filter.Matches(&order) && after.Before(models.EventPosition(&order)) {
+				orders = append(orders, &order)
+			}
+		}
+	}
+	return orders
+}
+
+func (b *BufferedOrderRepository) lastFlushErr() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.flushErr
+}
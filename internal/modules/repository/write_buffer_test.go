@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// stubOrderRepository records batch inserts, failing the first failures of them.
+type stubOrderRepository struct {
+	OrderRepository
+
+	mu       sync.Mutex
+	books    [][]models.OrderBook
+	history  [][]models.HistoryOrder
+	stored   *models.HistoryOrder
+	failures int
+}
+
+func (s *stubOrderRepository) SaveOrders(orders []models.OrderBook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("insert failed")
+	}
+	for _, order := range orders {
+		if order.Pair == "POISON" {
+			return errors.New("invalid row")
+		}
+	}
+	s.books = append(s.books, orders)
+	return nil
+}
+
+func (s *stubOrderRepository) SaveOrderHistories(orders []models.HistoryOrder) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.history = append(s.history, orders)
+	return nil
+}
+
+func (s *stubOrderRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	if s.stored == nil || s.stored.OrderID != orderID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return s.stored, nil
+}
+
+func (s *stubOrderRepository) FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error) {
+	orders := []*models.HistoryOrder{}
+	for _, orderID := range orderIDs {
+		if order, err := s.FindHistoryOrder(orderID); err == nil {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func (s *stubOrderRepository) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *models.OrderBook
+	for _, batch := range s.books {
+		for i := range batch {
+			book := &batch[i]
+			if book.Exchange == exchangeName && book.Pair == pair && (asOf.IsZero() || !book.ExchangeTime.After(asOf)) &&
+				(latest == nil || bookBefore(latest, book)) {
+				latest = book
+			}
+		}
+	}
+	if latest == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return latest, nil
+}
+
+func (s *stubOrderRepository) FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []*models.HistoryOrder
+	for _, batch := range s.history {
+		for i := range batch {
+			if filter.Matches(&batch[i]) && after.Before(models.EventPosition(&batch[i])) {
+				orders = append(orders, &batch[i])
+			}
+		}
+	}
+	return orders[:min(len(orders), limit)], nil
+}
+
+func (s *stubOrderRepository) batches() (int, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.books), len(s.history)
+}
+
+func TestBufferedOrderRepository_FlushOnSize(t *testing.T) {
+	stub := &stubOrderRepository{}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushSize: 2, FlushInterval: time.Hour})
+	defer buffer.Close()
+
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{OrderID: "order-1"}))
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{OrderID: "order-2"}))
+
+	assert.Eventually(t, func() bool {
+		_, history := stub.batches()
+		return history == 1
+	}, time.Second, time.Millisecond)
+	assert.Len(t, stub.history[0], 2)
+	assert.Equal(t, uint64(2), buffer.Stats().FlushedRows)
+}
+
+func TestBufferedOrderRepository_FlushOnInterval(t *testing.T) {
+	stub := &stubOrderRepository{}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer buffer.Close()
+
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{Pair: "BTC/USD"}))
+
+	assert.Eventually(t, func() bool {
+		books, _ := stub.batches()
+		return books == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 0, buffer.Stats().PendingOrderBooks)
+}
+
+func TestBufferedOrderRepository_Backpressure(t *testing.T) {
+	stub := &stubOrderRepository{}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{
+		FlushSize:      100,
+		FlushInterval:  time.Hour,
+		Capacity:       1,
+		EnqueueTimeout: 10 * time.Millisecond,
+	})
+
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{Pair: "BTC/USD"}))
+	assert.ErrorIs(t, buffer.SaveOrder(models.OrderBook{Pair: "ETH/USD"}), ErrBufferFull)
+
+	stats := buffer.Stats()
+	assert.Equal(t, 1, stats.PendingOrderBooks)
+	assert.Equal(t, uint64(1), stats.RejectedRows)
+
+	assert.NoError(t, buffer.Close())
+	assert.Len(t, stub.books, 1)
+	assert.ErrorIs(t, buffer.SaveOrder(models.OrderBook{Pair: "ETH/USD"}), ErrBufferClosed)
+}
+
+func TestBufferedOrderRepository_RetriesFailedFlush(t *testing.T) {
+	stub := &stubOrderRepository{failures: 1}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushSize: 1, FlushInterval: 10 * time.Millisecond})
+
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{Pair: "BTC/USD"}))
+
+	assert.Eventually(t, func() bool {
+		books, _ := stub.batches()
+		return books == 1
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, buffer.Close())
+
+	stats := buffer.Stats()
+	assert.Equal(t, uint64(1), stats.FailedFlushes)
+	assert.Equal(t, uint64(1), stats.FlushedRows)
+}
+
+func TestBufferedOrderRepository_DeadLetter(t *testing.T) {
+	stub := &stubOrderRepository{}
+	var deadLetters bytes.Buffer
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{
+		FlushSize:       100,
+		FlushInterval:   time.Millisecond,
+		MaxFlushRetries: 2,
+		DeadLetter:      &deadLetters,
+	})
+
+	for _, pair := range []string{"BTC/USD", "POISON", "ETH/USD"} {
+		assert.NoError(t, buffer.SaveOrder(models.OrderBook{Pair: pair}))
+	}
+
+	assert.Eventually(t, func() bool {
+		return buffer.Stats().DeadLetteredRows == 1
+	}, time.Second, time.Millisecond)
+	// the last flush isolated the row that could not be stored
+	assert.EqualError(t, buffer.Close(), "invalid row")
+
+	stats := buffer.Stats()
+	assert.Equal(t, uint64(2), stats.FlushedRows)
+	assert.Equal(t, 0, stats.PendingOrderBooks)
+
+	var stored []string
+	for _, batch := range stub.books {
+		for _, book := range batch {
+			stored = append(stored, book.Pair)
+		}
+	}
+	assert.ElementsMatch(t, []string{"BTC/USD", "ETH/USD"}, stored)
+
+	var line struct {
+		Table    string           `json:"table"`
+		Attempts int              `json:"attempts"`
+		Row      models.OrderBook `json:"row"`
+	}
+	assert.NoError(t, json.Unmarshal(deadLetters.Bytes(), &line))
+	assert.Equal(t, "order_books", line.Table)
+	assert.Equal(t, 2, line.Attempts)
+	assert.Equal(t, "POISON", line.Row.Pair)
+}
+
+func TestBufferedOrderRepository_DeadLetterOnClose(t *testing.T) {
+	stub := &stubOrderRepository{failures: 100}
+	var deadLetters bytes.Buffer
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushInterval: time.Hour, DeadLetter: &deadLetters})
+
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{Pair: "BTC/USD"}))
+	assert.Error(t, buffer.Close())
+
+	assert.Equal(t, uint64(1), buffer.Stats().DeadLetteredRows)
+	assert.Contains(t, deadLetters.String(), "BTC/USD")
+}
+
+func TestBufferedOrderRepository_FindHistoryOrder(t *testing.T) {
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stub := &stubOrderRepository{stored: &models.HistoryOrder{OrderID: "order-1", Status: models.OrderStatusNew, UpdatedAt: placedAt}}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushInterval: time.Hour})
+	defer buffer.Close()
+
+	order, err := buffer.FindHistoryOrder("order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusNew, order.Status)
+
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{
+		OrderID: "order-1", Status: models.OrderStatusFilled, UpdatedAt: placedAt.Add(time.Minute)}))
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{OrderID: "order-2", Status: models.OrderStatusNew}))
+
+	order, err = buffer.FindHistoryOrder("order-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusFilled, order.Status)
+
+	order, err = buffer.FindHistoryOrder("order-2")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OrderStatusNew, order.Status)
+
+	_, err = buffer.FindHistoryOrder("order-3")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	orders, err := buffer.FindHistoryOrders([]string{"order-1", "order-2", "order-3"})
+	assert.NoError(t, err)
+	statuses := make(map[string]string)
+	for _, order := range orders {
+		statuses[order.OrderID] = order.Status
+	}
+	assert.Equal(t, map[string]string{"order-1": models.OrderStatusFilled, "order-2": models.OrderStatusNew}, statuses)
+}
+
+func TestBufferedOrderRepository_FindLatestOrder(t *testing.T) {
+	exchangeTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stub := &stubOrderRepository{books: [][]models.OrderBook{{
+		{Exchange: "test_exchange", Pair: "BTC/USD", ExchangeTime: exchangeTime, Sequence: 1},
+	}}}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushInterval: time.Hour})
+	defer buffer.Close()
+
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{
+		Exchange: "test_exchange", Pair: "BTC/USD", ExchangeTime: exchangeTime.Add(time.Second), Sequence: 2}))
+	assert.NoError(t, buffer.SaveOrder(models.OrderBook{
+		Exchange: "test_exchange", Pair: "ETH/USD", ExchangeTime: exchangeTime, Sequence: 7}))
+
+	book, err := buffer.FindLatestOrder("test_exchange", "BTC/USD", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), book.Sequence)
+
+	book, err = buffer.FindLatestOrder("test_exchange", "BTC/USD", exchangeTime)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), book.Sequence)
+
+	book, err = buffer.FindLatestOrder("test_exchange", "ETH/USD", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), book.Sequence)
+
+	_, err = buffer.FindLatestOrder("other_exchange", "BTC/USD", time.Time{})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestBufferedOrderRepository_FindOrdersSavedAfter(t *testing.T) {
+	ingestedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stored := models.HistoryOrder{OrderID: "order-2", ClientName: "test_client", Status: models.OrderStatusNew, IngestedAt: ingestedAt.Add(2 * time.Second)}
+	stub := &stubOrderRepository{history: [][]models.HistoryOrder{{
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusNew, IngestedAt: ingestedAt},
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusPartiallyFilled, IngestedAt: ingestedAt.Add(time.Second)},
+		stored,
+	}}}
+	buffer := NewBufferedOrderRepository(stub, BufferConfig{FlushInterval: time.Hour})
+	defer buffer.Close()
+
+	// A row that is being flushed is returned once
+	assert.NoError(t, buffer.SaveOrderHistory(stored))
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{
+		OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusFilled, IngestedAt: ingestedAt.Add(3 * time.Second)}))
+	assert.NoError(t, buffer.SaveOrderHistory(models.HistoryOrder{
+		OrderID: "order-3", ClientName: "other_client", Status: models.OrderStatusNew, IngestedAt: ingestedAt.Add(4 * time.Second)}))
+
+	filter := &models.OrderStreamFilter{ClientName: "test_client"}
+	after := &models.OrderEventPosition{IngestedAt: ingestedAt, OrderID: "order-1"}
+
+	orders, err := buffer.FindOrdersSavedAfter(filter, after, 10)
+	assert.NoError(t, err)
+	var states []string
+	for _, order := range orders {
+		states = append(states, order.OrderID+":"+order.Status)
+	}
+	assert.Equal(t, []string{"order-1:PARTIALLY_FILLED", "order-2:NEW", "order-1:FILLED"}, states)
+
+	// The oldest states come first, the next page continues after the last of them
+	orders, err = buffer.FindOrdersSavedAfter(filter, after, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, models.OrderStatusPartiallyFilled, orders[0].Status)
+	}
+
+	position := models.EventPosition(orders[0])
+	orders, err = buffer.FindOrdersSavedAfter(filter, &position, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, orders, 1) {
+		assert.Equal(t, "order-2", orders[0].OrderID)
+	}
+}
@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+
+	orderv1 "github.com/kymaka/vortex-test/api/order/v1"
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// parseDecimal parses a decimal string of a request field, an empty string is zero.
+func parseDecimal(field, value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("%s: invalid decimal %q", field, value)
+	}
+	return d, nil
+}
+
+// toTime converts a request timestamp, a missing timestamp is zero time.
+func toTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// fromTime converts a time to a response timestamp, zero time is left unset.
+func fromTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toLevels(field string, levels []*orderv1.Level) ([]*models.DepthOrder, error) {
+	orders := make([]*models.DepthOrder, len(levels))
+	for i, level := range levels {
+		price, err := parseDecimal(fmt.Sprintf("%s[%d].price", field, i), level.GetPrice())
+		if err != nil {
+			return nil, err
+		}
+		qty, err := parseDecimal(fmt.Sprintf("%s[%d].base_qty", field, i), level.GetBaseQty())
+		if err != nil {
+			return nil, err
+		}
+		orders[i] = &models.DepthOrder{Price: price, BaseQty: qty}
+	}
+	return orders, nil
+}
+
+func fromLevels(orders []*models.DepthOrder) []*orderv1.Level {
+	levels := make([]*orderv1.Level, len(orders))
+	for i, order := range orders {
+		levels[i] = &orderv1.Level{Price: order.Price.String(), BaseQty: order.BaseQty.String()}
+	}
+	return levels
+}
+
+func toOrderBook(book *orderv1.OrderBook) (*models.OrderBookDTO, error) {
+	asks, err := toLevels("asks", book.GetAsks())
+	if err != nil {
+		return nil, err
+	}
+	bids, err := toLevels("bids", book.GetBids())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderBookDTO{
+		ID:           book.GetId(),
+		Exchange:     book.GetExchange(),
+		Pair:         book.GetPair(),
+		Asks:         asks,
+		Bids:         bids,
+		ExchangeTime: toTime(book.GetExchangeTime()),
+		ReceivedTime: toTime(book.GetReceivedTime()),
+		Sequence:     book.GetSequence(),
+	}, nil
+}
+
+func fromOrderBook(order *models.OrderBookDTO) *orderv1.OrderBook {
+	return &orderv1.OrderBook{
+		Id:           order.ID,
+		Exchange:     order.Exchange,
+		Pair:         order.Pair,
+		Asks:         fromLevels(order.Asks),
+		Bids:         fromLevels(order.Bids),
+		ExchangeTime: fromTime(order.ExchangeTime),
+		ReceivedTime: fromTime(order.ReceivedTime),
+		Sequence:     order.Sequence,
+	}
+}
+
+func toOrderBookDelta(delta *orderv1.OrderBookDelta) (*models.OrderBookDelta, error) {
+	asks, err := toLevels("asks", delta.GetAsks())
+	if err != nil {
+		return nil, err
+	}
+	bids, err := toLevels("bids", delta.GetBids())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderBookDelta{
+		Exchange:     delta.GetExchange(),
+		Pair:         delta.GetPair(),
+		Sequence:     delta.GetSequence(),
+		ExchangeTime: toTime(delta.GetExchangeTime()),
+		Asks:         asks,
+		Bids:         bids,
+	}, nil
+}
+
+func fromOrderBookDelta(delta *models.OrderBookDelta) *orderv1.OrderBookDelta {
+	return &orderv1.OrderBookDelta{
+		Exchange:     delta.Exchange,
+		Pair:         delta.Pair,
+		Sequence:     delta.Sequence,
+		ExchangeTime: fromTime(delta.ExchangeTime),
+		Asks:         fromLevels(delta.Asks),
+		Bids:         fromLevels(delta.Bids),
+	}
+}
+
+func toHistoryOrder(order *orderv1.HistoryOrder) (*models.HistoryOrder, error) {
+	history := &models.HistoryOrder{
+		OrderID:             order.GetOrderId(),
+		ClientOrderID:       order.GetClientOrderId(),
+		Side:                order.GetSide(),
+		Type:                order.GetType(),
+		AlgorithmNamePlaced: order.GetAlgorithmNamePlaced(),
+		TimePlaced:          toTime(order.GetTimePlaced()),
+		Status:              order.GetStatus(),
+		UpdatedAt:           toTime(order.GetUpdatedAt()),
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  *decimal.Decimal
+	}{
+		{"base_qty", order.GetBaseQty(), &history.BaseQty},
+		{"price", order.GetPrice(), &history.Price},
+		{"lowest_sell_prc", order.GetLowestSellPrc(), &history.LowestSellPrc},
+		{"highest_buy_prc", order.GetHighestBuyPrc(), &history.HighestBuyPrc},
+		{"commission_quote_qty", order.GetCommissionQuoteQty(), &history.CommissionQuoteQty},
+	} {
+		d, err := parseDecimal(field.name, field.value)
+		if err != nil {
+			return nil, err
+		}
+		*field.dest = d
+	}
+
+	return history, nil
+}
+
+func fromHistoryOrder(order *models.HistoryOrder) *orderv1.HistoryOrder {
+	return &orderv1.HistoryOrder{
+		OrderId:             order.OrderID,
+		ClientOrderId:       order.ClientOrderID,
+		ClientName:          order.ClientName,
+		ExchangeName:        order.ExchangeName,
+		Label:               order.Label,
+		Pair:                order.Pair,
+		Side:                order.Side,
+		Type:                order.Type,
+		BaseQty:             order.BaseQty.String(),
+		Price:               order.Price.String(),
+		AlgorithmNamePlaced: order.AlgorithmNamePlaced,
+		LowestSellPrc:       order.LowestSellPrc.String(),
+		HighestBuyPrc:       order.HighestBuyPrc.String(),
+		CommissionQuoteQty:  order.CommissionQuoteQty.String(),
+		TimePlaced:          fromTime(order.TimePlaced),
+		Status:              order.Status,
+		UpdatedAt:           fromTime(order.UpdatedAt),
+	}
+}
+
+func toHistoryFilter(req *orderv1.GetOrderHistoryRequest) *models.OrderHistoryFilter {
+	return &models.OrderHistoryFilter{
+		ClientName:          req.GetClientName(),
+		ExchangeName:        req.GetExchangeName(),
+		Label:               req.GetLabel(),
+		Pair:                req.GetPair(),
+		From:                toTime(req.GetFrom()),
+		To:                  toTime(req.GetTo()),
+		Side:                req.GetSide(),
+		Type:                req.GetType(),
+		AlgorithmNamePlaced: req.GetAlgorithmNamePlaced(),
+		Sort:                req.GetSort(),
+		Limit:               int(req.GetLimit()),
+		Cursor:              req.GetCursor(),
+	}
+}
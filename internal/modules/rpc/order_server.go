@@ -0,0 +1,259 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	orderv1 "github.com/kymaka/vortex-test/api/order/v1"
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
+	"github.com/kymaka/vortex-test/internal/modules/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// Number of updates buffered per stream before it is ended as a slow consumer
+const streamBufferSize = 256
+
+/*
+orderServerImpl serves the order book and order history operations over gRPC,
+validating requests and mapping errors the same way the HTTP controller does.
+*/
+type orderServerImpl struct {
+	orderv1.UnimplementedOrderServiceServer
+	service service.OrderService
+}
+
+func NewOrderServer(s service.OrderService) orderv1.OrderServiceServer {
+	return &orderServerImpl{service: s}
+}
+
+func (osi *orderServerImpl) GetOrderBook(ctx context.Context, req *orderv1.GetOrderBookRequest) (*orderv1.GetOrderBookResponse, error) {
+	if req.GetExchangeName() == "" || req.GetPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "exchange name and pair are required")
+	}
+	if req.GetDepth() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "depth must be a non-negative integer")
+	}
+
+	tick, err := parseDecimal("tick", req.GetTick())
+	if err != nil || tick.IsNegative() {
+		return nil, status.Error(codes.InvalidArgument, "tick must be a non-negative number")
+	}
+
+	orders, err := osi.service.GetOrderBook(req.GetExchangeName(), req.GetPair(), int(req.GetDepth()), tick)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &orderv1.GetOrderBookResponse{OrderBooks: make([]*orderv1.OrderBook, len(orders))}
+	for i, order := range orders {
+		resp.OrderBooks[i] = fromOrderBook(order)
+	}
+	return resp, nil
+}
+
+func (osi *orderServerImpl) GetLatestOrderBook(ctx context.Context, req *orderv1.GetLatestOrderBookRequest) (*orderv1.OrderBook, error) {
+	if req.GetExchangeName() == "" || req.GetPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "exchange name and pair are required")
+	}
+
+	order, err := osi.service.GetLatestOrderBook(req.GetExchangeName(), req.GetPair(), toTime(req.GetAsOf()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromOrderBook(order), nil
+}
+
+func (osi *orderServerImpl) SaveOrderBook(ctx context.Context, req *orderv1.SaveOrderBookRequest) (*orderv1.SaveOrderBookResponse, error) {
+	if req.GetOrderBook().GetPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "pair is required")
+	}
+
+	order, err := toOrderBook(req.GetOrderBook())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := osi.service.SaveOrderBook(order); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &orderv1.SaveOrderBookResponse{}, nil
+}
+
+func (osi *orderServerImpl) SaveOrderBookDelta(ctx context.Context, req *orderv1.SaveOrderBookDeltaRequest) (*orderv1.SaveOrderBookDeltaResponse, error) {
+	if req.GetDelta().GetExchange() == "" || req.GetDelta().GetPair() == "" {
+		return nil, status.Error(codes.InvalidArgument, "exchange and pair are required")
+	}
+
+	delta, err := toOrderBookDelta(req.GetDelta())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := osi.service.SaveOrderBookDelta(delta); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &orderv1.SaveOrderBookDeltaResponse{}, nil
+}
+
+func (osi *orderServerImpl) GetOrderHistory(ctx context.Context, req *orderv1.GetOrderHistoryRequest) (*orderv1.GetOrderHistoryResponse, error) {
+	filter := toHistoryFilter(req)
+	if err := validateHistoryFilter(filter); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	page, err := osi.service.GetOrderHistory(filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &orderv1.GetOrderHistoryResponse{
+		Orders:     make([]*orderv1.HistoryOrder, len(page.Orders)),
+		NextCursor: page.NextCursor,
+	}
+	for i, order := range page.Orders {
+		resp.Orders[i] = fromHistoryOrder(order)
+	}
+	return resp, nil
+}
+
+func (osi *orderServerImpl) SaveOrder(ctx context.Context, req *orderv1.SaveOrderRequest) (*orderv1.HistoryOrder, error) {
+	if req.GetClient().GetClientName() == "" || req.GetOrder().GetType() == "" {
+		return nil, status.Error(codes.InvalidArgument, "client name and order type are required")
+	}
+
+	order, err := toHistoryOrder(req.GetOrder())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	client := &models.Client{
+		ClientName:   req.GetClient().GetClientName(),
+		ExchangeName: req.GetClient().GetExchangeName(),
+		Label:        req.GetClient().GetLabel(),
+		Pair:         req.GetClient().GetPair(),
+	}
+
+	saved, err := osi.service.SaveOrder(client, order)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromHistoryOrder(saved), nil
+}
+
+func (osi *orderServerImpl) SubscribeOrderBook(req *orderv1.SubscribeOrderBookRequest, stream orderv1.OrderService_SubscribeOrderBookServer) error {
+	if req.GetExchangeName() == "" || req.GetPair() == "" {
+		return status.Error(codes.InvalidArgument, "exchange name and pair are required")
+	}
+
+	subscription := osi.service.SubscribeOrderBooks(streamBufferSize)
+	defer subscription.Close()
+
+	if err := osi.service.JoinOrderBook(subscription, req.GetExchangeName(), req.GetPair()); err != nil {
+		return toStatus(err)
+	}
+
+	for {
+		select {
+		case update, ok := <-subscription.Updates():
+			if !ok {
+				return toStatus(subscription.Err())
+			}
+
+			resp := &orderv1.OrderBookUpdate{}
+			if update.Type == models.OrderBookUpdateDelta {
+				resp.Update = &orderv1.OrderBookUpdate_Delta{Delta: fromOrderBookDelta(update.Delta)}
+			} else {
+				resp.Update = &orderv1.OrderBookUpdate_Snapshot{Snapshot: fromOrderBook(update.Book)}
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (osi *orderServerImpl) SubscribeOrders(req *orderv1.SubscribeOrdersRequest, stream orderv1.OrderService_SubscribeOrdersServer) error {
+	filter := &models.OrderStreamFilter{
+		ClientName:   req.GetClientName(),
+		ExchangeName: req.GetExchangeName(),
+		Label:        req.GetLabel(),
+		Pair:         req.GetPair(),
+	}
+
+	subscription, err := osi.service.SubscribeOrders(filter, req.GetLastEventId(), streamBufferSize)
+	if err != nil {
+		return toStatus(err)
+	}
+	defer subscription.Close()
+
+	events := subscription.Events()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return toStatus(subscription.Err())
+			}
+
+			if err := stream.Send(&orderv1.OrderEvent{Id: event.ID, Order: fromHistoryOrder(event.Order)}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// validateHistoryFilter checks that an order history filter names a client and has a valid sort, limit and time range.
+func validateHistoryFilter(filter *models.OrderHistoryFilter) error {
+	switch {
+	case filter.ClientName == "":
+		return errors.New("client name is required")
+	case filter.Sort != "" && filter.Sort != models.SortAsc && filter.Sort != models.SortDesc:
+		return errors.New("sort must be asc or desc")
+	case filter.Limit < 0 || filter.Limit > service.MaxHistoryLimit:
+		return errors.New("limit is out of range")
+	case !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To):
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// toStatus maps a service error to the gRPC status matching the HTTP status the controller replies with.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		messages := make([]string, len(validationErr.Violations))
+		for i, violation := range validationErr.Violations {
+			messages[i] = violation.Field + ": " + violation.Message
+		}
+		return status.Error(codes.InvalidArgument, strings.Join(messages, "; "))
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidCursor), errors.Is(err, service.ErrInvalidEventID):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrSequenceGap), errors.Is(err, service.ErrOrderBookOutOfSync):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, repository.ErrBufferFull):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, service.ErrSlowConsumer):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
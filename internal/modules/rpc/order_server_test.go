@@ -0,0 +1,293 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	orderv1 "github.com/kymaka/vortex-test/api/order/v1"
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
+	"github.com/kymaka/vortex-test/internal/modules/service"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"gorm.io/gorm"
+)
+
+/*
+stubRepository backs a real service for the tests, test_exchange BTC/USD has a stored snapshot,
+other pairs have none and writes fail only for the busy client.
+*/
+type stubRepository struct {
+	repository.OrderRepository
+}
+
+func (r *stubRepository) FindOrder(exchangeName, pair string) ([]*models.OrderBook, error) {
+	if exchangeName == "test_exchange" && pair == "BTC/USD" {
+		return []*models.OrderBook{{Exchange: exchangeName, Pair: pair, Asks: models.Tuples{{decimal.RequireFromString("101.5"), decimal.NewFromInt(2)}}}}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *stubRepository) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	if exchangeName == "test_exchange" && pair == "BTC/USD" {
+		return &models.OrderBook{Exchange: exchangeName, Pair: pair, Sequence: 10}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *stubRepository) FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	if exchangeName == "precise_exchange" {
+		return &models.PairPrecision{Exchange: exchangeName, Pair: pair, PriceScale: 0, QtyScale: 0}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *stubRepository) FindOrderHistory(filter *models.OrderHistoryFilter, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error) {
+	if filter.ClientName == "error" {
+		return nil, errors.New("error finding order history")
+	}
+	return []*models.HistoryOrder{{OrderID: "order-1", ClientName: filter.ClientName, Price: decimal.RequireFromString("100.25")}}, nil
+}
+
+func (r *stubRepository) SaveOrder(order models.OrderBook) error {
+	return nil
+}
+
+func (r *stubRepository) SaveOrderHistory(order models.HistoryOrder) error {
+	if order.ClientName == "busy" {
+		return repository.ErrBufferFull
+	}
+	return nil
+}
+
+func (r *stubRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	if orderID == "existing" {
+		return &models.HistoryOrder{OrderID: orderID, Status: models.OrderStatusFilled}, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *stubRepository) FindFills(orderIDs []string) ([]*models.Fill, error) {
+	return nil, nil
+}
+
+// newTestClient serves the service over an in-memory connection and returns a client connected to it.
+func newTestClient(t *testing.T, s service.OrderService, opts ...grpc.ServerOption) orderv1.OrderServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	orderv1.RegisterOrderServiceServer(server, NewOrderServer(s))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return orderv1.NewOrderServiceClient(conn)
+}
+
+func TestOrderServer_OrderBooks(t *testing.T) {
+	client := newTestClient(t, service.NewOrderService(&stubRepository{}))
+	ctx := context.Background()
+
+	books, err := client.GetOrderBook(ctx, &orderv1.GetOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD"})
+	assert.NoError(t, err)
+	if assert.Len(t, books.OrderBooks, 1) {
+		assert.Equal(t, "101.5", books.OrderBooks[0].Asks[0].Price)
+	}
+
+	latest, err := client.GetLatestOrderBook(ctx, &orderv1.GetLatestOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), latest.Sequence)
+
+	_, err = client.SaveOrderBook(ctx, &orderv1.SaveOrderBookRequest{OrderBook: &orderv1.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     []*orderv1.Level{{Price: "101", BaseQty: "1"}},
+	}})
+	assert.NoError(t, err)
+
+	_, err = client.SaveOrderBookDelta(ctx, &orderv1.SaveOrderBookDeltaRequest{Delta: &orderv1.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11}})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{"missing pair", func() error {
+			_, err := client.GetOrderBook(ctx, &orderv1.GetOrderBookRequest{ExchangeName: "test_exchange"})
+			return err
+		}, codes.InvalidArgument},
+		{"negative tick", func() error {
+			_, err := client.GetOrderBook(ctx, &orderv1.GetOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD", Tick: "-1"})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown pair", func() error {
+			_, err := client.GetLatestOrderBook(ctx, &orderv1.GetLatestOrderBookRequest{ExchangeName: "test_exchange", Pair: "ETH/BTC"})
+			return err
+		}, codes.NotFound},
+		{"invalid price", func() error {
+			_, err := client.SaveOrderBook(ctx, &orderv1.SaveOrderBookRequest{OrderBook: &orderv1.OrderBook{Pair: "BTC/USD", Asks: []*orderv1.Level{{Price: "abc", BaseQty: "1"}}}})
+			return err
+		}, codes.InvalidArgument},
+		{"too precise", func() error {
+			_, err := client.SaveOrderBook(ctx, &orderv1.SaveOrderBookRequest{OrderBook: &orderv1.OrderBook{Exchange: "precise_exchange", Pair: "BTC/USD", Asks: []*orderv1.Level{{Price: "1.5", BaseQty: "1"}}}})
+			return err
+		}, codes.InvalidArgument},
+		{"sequence gap", func() error {
+			_, err := client.SaveOrderBookDelta(ctx, &orderv1.SaveOrderBookDeltaRequest{Delta: &orderv1.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 15}})
+			return err
+		}, codes.FailedPrecondition},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.code, status.Code(tt.call()), tt.name)
+	}
+}
+
+func TestOrderServer_OrderHistory(t *testing.T) {
+	client := newTestClient(t, service.NewOrderService(&stubRepository{}))
+	ctx := context.Background()
+
+	page, err := client.GetOrderHistory(ctx, &orderv1.GetOrderHistoryRequest{ClientName: "test_client"})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Orders, 1) {
+		assert.Equal(t, "100.25", page.Orders[0].Price)
+	}
+
+	saved, err := client.SaveOrder(ctx, &orderv1.SaveOrderRequest{
+		Client: &orderv1.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"},
+		Order:  &orderv1.HistoryOrder{Type: "limit", Side: "buy", Price: "100", BaseQty: "0.5"},
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, saved.OrderId)
+	assert.Equal(t, models.OrderStatusNew, saved.Status)
+	assert.Equal(t, "0.5", saved.BaseQty)
+
+	_, err = client.GetOrderHistory(ctx, &orderv1.GetOrderHistoryRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetOrderHistory(ctx, &orderv1.GetOrderHistoryRequest{ClientName: "test_client", Cursor: "not a cursor"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetOrderHistory(ctx, &orderv1.GetOrderHistoryRequest{ClientName: "error"})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	_, err = client.SaveOrder(ctx, &orderv1.SaveOrderRequest{Client: &orderv1.Client{ClientName: "test_client"}, Order: &orderv1.HistoryOrder{}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.SaveOrder(ctx, &orderv1.SaveOrderRequest{Client: &orderv1.Client{ClientName: "test_client"}, Order: &orderv1.HistoryOrder{OrderId: "existing", Type: "limit"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.SaveOrder(ctx, &orderv1.SaveOrderRequest{Client: &orderv1.Client{ClientName: "busy"}, Order: &orderv1.HistoryOrder{Type: "limit"}})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestOrderServer_Subscriptions(t *testing.T) {
+	orderService := service.NewOrderService(&stubRepository{})
+	client := newTestClient(t, orderService)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	books, err := client.SubscribeOrderBook(ctx, &orderv1.SubscribeOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD"})
+	assert.NoError(t, err)
+
+	// The stream starts with the current snapshot
+	update, err := books.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), update.GetSnapshot().GetSequence())
+
+	orders, err := client.SubscribeOrders(ctx, &orderv1.SubscribeOrdersRequest{ClientName: "test_client"})
+	assert.NoError(t, err)
+
+	// The order book subscription is registered once its snapshot has been received
+	err = orderService.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11})
+	assert.NoError(t, err)
+
+	update, err = books.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), update.GetDelta().GetSequence())
+
+	events := make(chan *orderv1.OrderEvent, 16)
+	go func() {
+		for {
+			event, err := orders.Recv()
+			if err != nil {
+				close(events)
+				return
+			}
+			events <- event
+		}
+	}()
+
+	// Orders are saved until the order subscription has been registered on the server
+	var event *orderv1.OrderEvent
+	assert.Eventually(t, func() bool {
+		_, err := orderService.SaveOrder(&models.Client{ClientName: "test_client"}, &models.HistoryOrder{OrderID: "order-1"})
+		assert.NoError(t, err)
+
+		select {
+		case event = <-events:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, 10*time.Millisecond)
+	if assert.NotNil(t, event) {
+		assert.Equal(t, "order-1", event.Order.OrderId)
+		assert.NotEmpty(t, event.Id)
+	}
+
+	invalid, err := client.SubscribeOrders(ctx, &orderv1.SubscribeOrdersRequest{LastEventId: "not an event"})
+	assert.NoError(t, err)
+	_, err = invalid.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestOrderServer_RateLimit(t *testing.T) {
+	limiter := NewRateLimiter(2, 1, time.Minute)
+	client := newTestClient(t, service.NewOrderService(&stubRepository{}),
+		grpc.ChainUnaryInterceptor(limiter.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(limiter.StreamInterceptor))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request := &orderv1.GetOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	for i := 0; i < 2; i++ {
+		_, err := client.GetOrderBook(ctx, request)
+		assert.NoError(t, err)
+	}
+	_, err := client.GetOrderBook(ctx, request)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Streams count against the reads
+	books, err := client.SubscribeOrderBook(ctx, &orderv1.SubscribeOrderBookRequest{ExchangeName: "test_exchange", Pair: "BTC/USD"})
+	assert.NoError(t, err)
+	_, err = books.Recv()
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Writes have their own limit
+	save := &orderv1.SaveOrderBookRequest{OrderBook: &orderv1.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD"}}
+	_, err = client.SaveOrderBook(ctx, save)
+	assert.NoError(t, err)
+	_, err = client.SaveOrderBook(ctx, save)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,94 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/httprate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+/*
+RateLimiter limits the calls of every peer address with the sliding window counters the HTTP API uses,
+writes and reads are limited separately like POST and GET requests. Streams count as a single call.
+Calls over the limit fail with codes.ResourceExhausted.
+*/
+type RateLimiter struct {
+	reads  *callLimiter
+	writes *callLimiter
+}
+
+// NewRateLimiter allows readLimit reads and writeLimit writes of every peer address per window.
+func NewRateLimiter(readLimit, writeLimit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		reads:  newCallLimiter(readLimit, window),
+		writes: newCallLimiter(writeLimit, window),
+	}
+}
+
+// UnaryInterceptor rejects unary calls over the limit of their peer address.
+func (l *RateLimiter) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if !l.allow(ctx, info.FullMethod) {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return handler(ctx, req)
+}
+
+// StreamInterceptor rejects streams over the limit of their peer address.
+func (l *RateLimiter) StreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if !l.allow(stream.Context(), info.FullMethod) {
+		return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return handler(srv, stream)
+}
+
+// allow counts a call of the method from the peer of ctx, reporting whether it is within the limit.
+func (l *RateLimiter) allow(ctx context.Context, fullMethod string) bool {
+	limiter := l.reads
+	if strings.HasPrefix(path.Base(fullMethod), "Save") {
+		limiter = l.writes
+	}
+
+	key := ""
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		key = p.Addr.String()
+		if host, _, err := net.SplitHostPort(key); err == nil {
+			key = host
+		}
+	}
+
+	return limiter.allow(key)
+}
+
+// callLimiter counts calls by key, checking and counting a call atomically.
+type callLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	window  time.Duration
+	counter httprate.LimitCounter
+	status  func(key string) (bool, float64, error)
+}
+
+func newCallLimiter(limit int, window time.Duration) *callLimiter {
+	limiter := httprate.NewRateLimiter(limit, window)
+	return &callLimiter{limit: limit, window: window, counter: limiter.Counter(), status: limiter.Status}
+}
+
+func (l *callLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, rate, err := l.status(key)
+	if err != nil || int(math.Round(rate)) >= l.limit {
+		return false
+	}
+	return l.counter.Increment(key, time.Now().UTC().Truncate(l.window)) == nil
+}
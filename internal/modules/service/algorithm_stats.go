@@ -0,0 +1,91 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+)
+
+// GetAlgorithms returns every algorithm that placed orders, ordered by name.
+func (osi *orderServiceImpl) GetAlgorithms() ([]*models.Algorithm, error) {
+	return osi.repo.FindAlgorithms()
+}
+
+/*
+GetAlgorithmStats computes the performance of an algorithm from the orders it placed at or after from and before to,
+across all clients. Order counts, volumes and commission are aggregated by the database. Only the orders that executed
+in the window are loaded, PnL is replayed from them with FIFO matching per client, exchange, label and pair,
+and slippage is measured against the book at their placement.
+Returns gorm.ErrRecordNotFound if the algorithm placed no orders in the window.
+*/
+func (osi *orderServiceImpl) GetAlgorithmStats(name string, from, to time.Time) (*models.AlgorithmStats, error) {
+	volume, err := osi.repo.FindAlgorithmVolume(name, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.AlgorithmStats{Algorithm: name, AlgorithmVolume: *volume}
+	if !from.IsZero() {
+		stats.From = &from
+	}
+	if !to.IsZero() {
+		stats.To = &to
+	}
+	if volume.SellOrders > 0 {
+		ratio := float64(volume.BuyOrders) / float64(volume.SellOrders)
+		stats.BuySellRatio = &ratio
+	}
+
+	orders, err := osi.algorithmExecutions(name, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := osi.findFills(orders)
+	if err != nil {
+		return nil, err
+	}
+
+	pnls, err := osi.replayPnL(orders, fills, analytics.PnLMethodFIFO)
+	if err != nil {
+		return nil, err
+	}
+	for _, pnl := range pnls {
+		stats.RealizedPnL = stats.RealizedPnL.Add(pnl.RealizedPnL)
+		stats.UnrealizedPnL = stats.UnrealizedPnL.Add(pnl.UnrealizedPnL)
+		stats.NetPnL = stats.NetPnL.Add(pnl.NetPnL)
+	}
+
+	attachFillSummaries(orders, fills)
+	report := analytics.ExecutionQuality(orders)
+	stats.EvaluatedOrders = len(report.Orders)
+	if len(report.Algorithms) > 0 {
+		execution := report.Algorithms[0]
+		stats.SlippageBps = execution.SlippageBps
+		stats.CrossedSpreadRate = execution.CrossedSpreadRate
+		stats.FeeDragBps = execution.FeeDragBps
+	}
+
+	return stats, nil
+}
+
+// algorithmExecutions loads the executed orders an algorithm placed in the window, page by page.
+func (osi *orderServiceImpl) algorithmExecutions(name string, from, to time.Time) ([]*models.HistoryOrder, error) {
+	var orders []*models.HistoryOrder
+	var after *models.OrderHistoryCursor
+	for {
+		page, err := osi.repo.FindAlgorithmExecutions(name, from, to, after, MaxHistoryLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		orders = append(orders, page...)
+		if len(page) < MaxHistoryLimit {
+			return orders, nil
+		}
+
+		last := page[len(page)-1]
+		after = &models.OrderHistoryCursor{TimePlaced: last.TimePlaced, OrderID: last.OrderID, Sort: models.SortAsc}
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kymaka/vortex-test/internal/models"
+)
+
+// Highest number of items accepted in a single batch.
+const MaxBatchSize = 10000
+
+// BatchItemResult is the outcome of one item of a batch, identified by its position in the batch.
+type BatchItemResult struct {
+	Index      int         `json:"index"`
+	Accepted   bool        `json:"accepted"`
+	OrderID    string      `json:"orderId,omitempty"`
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// BatchResult holds the outcome of every item of a batch, in the order they were sent.
+type BatchResult struct {
+	Accepted int               `json:"accepted"`
+	Rejected int               `json:"rejected"`
+	Items    []BatchItemResult `json:"items"`
+}
+
+func (b *BatchResult) accept(index int, orderID string) {
+	b.Accepted++
+	b.Items = append(b.Items, BatchItemResult{Index: index, Accepted: true, OrderID: orderID})
+}
+
+func (b *BatchResult) reject(index int, violations []Violation) {
+	b.Rejected++
+	b.Items = append(b.Items, BatchItemResult{Index: index, Violations: violations})
+}
+
+/*
+SaveOrderBooks validates every order book of a batch as SaveOrderBook does and saves the valid ones in a single insert.
+Invalid order books are rejected with their violations without affecting the others.
+Returns an error, and saves nothing, if looking up a precision or the insert fails.
+*/
+func (osi *orderServiceImpl) SaveOrderBooks(orders []*models.OrderBookDTO) (*BatchResult, error) {
+	result := &BatchResult{Items: make([]BatchItemResult, 0, len(orders))}
+	dtos := make([]*models.OrderBookDTO, 0, len(orders))
+	books := make([]models.OrderBook, 0, len(orders))
+
+	for i, order := range orders {
+		if violations := missingBookFields(order); len(violations) > 0 {
+			result.reject(i, violations)
+			continue
+		}
+
+		dto, err := osi.prepareOrderBook(order)
+		if err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				result.reject(i, validationErr.Violations)
+				continue
+			}
+			return nil, err
+		}
+
+		dtos = append(dtos, dto)
+		books = append(books, dto.ToOrderBook())
+		result.accept(i, "")
+	}
+
+	if len(books) > 0 {
+		if err := osi.repo.SaveOrders(books); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dto := range dtos {
+		osi.publishSnapshot(dto)
+	}
+
+	return result, nil
+}
+
+/*
+SaveOrders validates every order of a batch as SaveOrder does and saves the valid ones in a single insert.
+Invalid orders, and orders with an ID that is already stored or repeated in the batch, are rejected
+with their violations without affecting the others, accepted orders report their ID.
+The order IDs of the batch are looked up together and stay locked until it is saved, as by SaveOrder.
+Returns an error, and saves nothing, if looking up the orders or a precision or the insert fails.
+*/
+func (osi *orderServiceImpl) SaveOrders(payloads []*models.HistoryOrderPayload) (*BatchResult, error) {
+	givenIDs := make([]string, 0, len(payloads))
+	given := make(map[string]bool, len(payloads))
+	for _, payload := range payloads {
+		if payload != nil && payload.History.OrderID != "" && !given[payload.History.OrderID] {
+			given[payload.History.OrderID] = true
+			givenIDs = append(givenIDs, payload.History.OrderID)
+		}
+	}
+	unlock := osi.orderLocks.lock(givenIDs...)
+	defer unlock()
+
+	stored, err := osi.findHistoryOrders(givenIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BatchResult{Items: make([]BatchItemResult, 0, len(payloads))}
+	orders := make([]models.HistoryOrder, 0, len(payloads))
+	orderIDs := make(map[string]bool, len(payloads))
+
+	for i, payload := range payloads {
+		if violations := missingOrderFields(payload); len(violations) > 0 {
+			result.reject(i, violations)
+			continue
+		}
+
+		if orderID := payload.History.OrderID; orderID != "" {
+			if orderIDs[orderID] {
+				result.reject(i, []Violation{{Field: "history.orderId", Message: fmt.Sprintf("order %q is repeated in the batch", orderID)}})
+				continue
+			}
+			orderIDs[orderID] = true
+
+			if stored[orderID] != nil {
+				result.reject(i, []Violation{existingOrderViolation(orderID)})
+				continue
+			}
+		}
+
+		order, err := osi.prepareOrder(&payload.Client, &payload.History)
+		if err != nil {
+			var validationErr *ValidationError
+			if errors.As(err, &validationErr) {
+				result.reject(i, validationErr.Violations)
+				continue
+			}
+			return nil, err
+		}
+
+		orders = append(orders, *order)
+		result.accept(i, order.OrderID)
+	}
+
+	if len(orders) == 0 {
+		return result, nil
+	}
+
+	saved := make([]*models.HistoryOrder, len(orders))
+	for i := range orders {
+		saved[i] = &orders[i]
+	}
+	err = osi.orders.save(saved, func() error {
+		return osi.repo.SaveOrderHistories(orders)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// missingBookFields lists the fields an order book of a batch requires but does not have.
+func missingBookFields(order *models.OrderBookDTO) []Violation {
+	if order == nil {
+		return []Violation{{Field: "", Message: "order book is missing"}}
+	}
+
+	var violations []Violation
+	if order.Exchange == "" {
+		violations = append(violations, Violation{Field: "exchange", Message: "exchange is required"})
+	}
+	if order.Pair == "" {
+		violations = append(violations, Violation{Field: "pair", Message: "pair is required"})
+	}
+	return violations
+}
+
+// missingOrderFields lists the fields an order of a batch requires but does not have.
+func missingOrderFields(payload *models.HistoryOrderPayload) []Violation {
+	if payload == nil {
+		return []Violation{{Field: "", Message: "order is missing"}}
+	}
+
+	var violations []Violation
+	if payload.Client.ClientName == "" {
+		violations = append(violations, Violation{Field: "client.clientName", Message: "client name is required"})
+	}
+	if payload.History.Type == "" {
+		violations = append(violations, Violation{Field: "history.type", Message: "order type is required"})
+	}
+	return violations
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+)
+
+/*
+GetCandles returns OHLCV candles of the fills of a pair on an exchange over the interval,
+for fills executed at or after from and before to, and mid price candles of its order books if mids is set.
+FILLED orders without fills are not part of the candles.
+*/
+func (osi *orderServiceImpl) GetCandles(exchangeName, pair, interval string, from, to time.Time, mids bool) (*models.Candles, error) {
+	length := models.CandleIntervals[interval]
+
+	trades, err := osi.repo.FindTradeCandles(exchangeName, pair, length, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := &models.Candles{Exchange: exchangeName, Pair: pair, Interval: interval, Trades: trades}
+	if mids {
+		if candles.Mids, err = osi.repo.FindMidCandles(exchangeName, pair, length, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	return candles, nil
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+ExportOrderBooks passes every order book of an exchange and pair received in [from, to) to fn as it is read,
+grouped into price buckets of size tick and truncated to the top depth levels as by GetOrderBook.
+Stops at the first error returned by fn.
+*/
+func (osi *orderServiceImpl) ExportOrderBooks(exchangeName, pair string, from, to time.Time, depth int, tick decimal.Decimal, fn func(*models.OrderBookDTO) error) error {
+	return osi.repo.StreamOrders(exchangeName, pair, from, to, func(order *models.OrderBook) error {
+		dto := order.ToDTO()
+		if depth > 0 || tick.IsPositive() {
+			return fn(AggregateOrderBook(&dto, depth, tick))
+		}
+		return fn(&dto)
+	})
+}
+
+/*
+ExportOrderHistory passes the current state of every order matching the filter to fn as it is read,
+without paging. Fill summaries are not attached. Stops at the first error returned by fn.
+*/
+func (osi *orderServiceImpl) ExportOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error {
+	query := *filter
+	if query.Sort == "" {
+		query.Sort = models.SortAsc
+	}
+	return osi.repo.StreamOrderHistory(&query, fn)
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+)
+
+/*
+GetFeeReport groups the commissions of the orders matching the filter by period, client, exchange, pair and label,
+with the effective fee rate in bps of the executed notional per row and in total.
+Commissions are grouped by month unless the filter asks for days.
+*/
+func (osi *orderServiceImpl) GetFeeReport(filter *models.FeeReportFilter) (*models.FeeReport, error) {
+	query := *filter
+	if query.Period == "" {
+		query.Period = models.FeePeriodMonth
+	}
+
+	rows, err := osi.repo.FindFeeReport(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.FeeReport{Period: query.Period, Rows: rows}
+	for _, row := range rows {
+		row.FeeRateBps = analytics.Bps(row.Commission, row.Notional)
+
+		report.Orders += row.Orders
+		report.Notional = report.Notional.Add(row.Notional)
+		report.Commission = report.Commission.Add(row.Commission)
+	}
+	report.FeeRateBps = analytics.Bps(report.Commission, report.Notional)
+
+	return report, nil
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+SaveFills saves executions of orders.
+Every fill must have an ID, reference a stored order, have a positive quantity and price,
+a non-negative fee and, if set, MAKER or TAKER liquidity.
+Fills without an execution time are stamped with the current time.
+Fills already stored, or repeated in the batch, are saved once, so a batch can be sent again.
+Fills of an order are saved one batch at a time, so concurrent batches cannot both save a fill.
+Returns a ValidationError listing every violation, in which case no fill is saved.
+*/
+func (osi *orderServiceImpl) SaveFills(fills []*models.Fill) error {
+	var orderIDs []string
+	seen := make(map[string]bool)
+	for _, fill := range fills {
+		if fill != nil && fill.OrderID != "" && !seen[fill.OrderID] {
+			seen[fill.OrderID] = true
+			orderIDs = append(orderIDs, fill.OrderID)
+		}
+	}
+	sort.Strings(orderIDs)
+
+	unlock := osi.orderLocks.lock(orderIDs...)
+	defer unlock()
+
+	knownOrders, err := osi.findHistoryOrders(orderIDs)
+	if err != nil {
+		return err
+	}
+
+	var violations []Violation
+	for i, fill := range fills {
+		field := fmt.Sprintf("fills[%d]", i)
+		if fill == nil {
+			violations = append(violations, Violation{Field: field, Message: "fill is missing"})
+			continue
+		}
+		violations = append(violations, validateFill(field, fill)...)
+
+		if fill.OrderID != "" && knownOrders[fill.OrderID] == nil {
+			violations = append(violations, Violation{
+				Field:   field + ".orderId",
+				Message: fmt.Sprintf("unknown order %q", fill.OrderID),
+			})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	stored, err := osi.repo.FindFills(orderIDs)
+	if err != nil {
+		return err
+	}
+	saved := make(map[fillKey]bool)
+	for _, fill := range stored {
+		saved[fillKey{orderID: fill.OrderID, fillID: fill.FillID}] = true
+	}
+
+	now := time.Now().UTC()
+	var newFills []models.Fill
+	for _, fill := range fills {
+		key := fillKey{orderID: fill.OrderID, fillID: fill.FillID}
+		if saved[key] {
+			continue
+		}
+		saved[key] = true
+
+		order := knownOrders[fill.OrderID]
+		newFill := *fill
+		newFill.ExchangeName, newFill.Pair = order.ExchangeName, order.Pair
+		if newFill.ExecutedAt.IsZero() {
+			newFill.ExecutedAt = now
+		}
+		newFills = append(newFills, newFill)
+	}
+
+	if len(newFills) == 0 {
+		return nil
+	}
+
+	return osi.repo.SaveFills(newFills)
+}
+
+// fillKey identifies a stored fill.
+type fillKey struct {
+	orderID string
+	fillID  string
+}
+
+func validateFill(field string, fill *models.Fill) []Violation {
+	var violations []Violation
+
+	if fill.FillID == "" {
+		violations = append(violations, Violation{Field: field + ".fillId", Message: "fill ID is required"})
+	}
+	if fill.OrderID == "" {
+		violations = append(violations, Violation{Field: field + ".orderId", Message: "order ID is required"})
+	}
+	if !fill.BaseQty.IsPositive() {
+		violations = append(violations, Violation{
+			Field:   field + ".baseQty",
+			Message: fmt.Sprintf("quantity must be a positive number, got %v", fill.BaseQty),
+		})
+	}
+	if !fill.Price.IsPositive() {
+		violations = append(violations, Violation{
+			Field:   field + ".price",
+			Message: fmt.Sprintf("price must be a positive number, got %v", fill.Price),
+		})
+	}
+	if fill.Fee.IsNegative() {
+		violations = append(violations, Violation{
+			Field:   field + ".fee",
+			Message: fmt.Sprintf("fee must not be negative, got %v", fill.Fee),
+		})
+	}
+	if fill.Liquidity != "" && fill.Liquidity != models.LiquidityMaker && fill.Liquidity != models.LiquidityTaker {
+		violations = append(violations, Violation{
+			Field:   field + ".liquidity",
+			Message: fmt.Sprintf("liquidity must be %s or %s, got %q", models.LiquidityMaker, models.LiquidityTaker, fill.Liquidity),
+		})
+	}
+
+	return violations
+}
+
+// findFills retrieves the fills of the orders, in batches of at most MaxHistoryLimit orders.
+func (osi *orderServiceImpl) findFills(orders []*models.HistoryOrder) ([]*models.Fill, error) {
+	var fills []*models.Fill
+	for i := 0; i < len(orders); i += MaxHistoryLimit {
+		batch := orders[i:min(i+MaxHistoryLimit, len(orders))]
+
+		orderIDs := make([]string, len(batch))
+		for j, order := range batch {
+			orderIDs[j] = order.OrderID
+		}
+
+		batchFills, err := osi.repo.FindFills(orderIDs)
+		if err != nil {
+			return nil, err
+		}
+		fills = append(fills, batchFills...)
+	}
+
+	return fills, nil
+}
+
+// attachFillSummaries sets the summary of the fills of every order, orders without fills get an empty summary.
+func attachFillSummaries(orders []*models.HistoryOrder, fills []*models.Fill) {
+	summaries := summarizeFills(fills)
+	for _, order := range orders {
+		order.Fills = summaries[order.OrderID]
+		if order.Fills == nil {
+			order.Fills = newFillSummary()
+		}
+	}
+}
+
+// summarizeFills aggregates fills by the order they belong to.
+func summarizeFills(fills []*models.Fill) map[string]*models.FillSummary {
+	summaries := make(map[string]*models.FillSummary)
+
+	for _, fill := range fills {
+		summary, ok := summaries[fill.OrderID]
+		if !ok {
+			summary = newFillSummary()
+			summaries[fill.OrderID] = summary
+		}
+
+		summary.FillCount++
+		summary.FilledQty = summary.FilledQty.Add(fill.BaseQty)
+		summary.QuoteQty = summary.QuoteQty.Add(fill.BaseQty.Mul(fill.Price))
+		summary.TotalFees[fill.FeeAsset] = summary.TotalFees[fill.FeeAsset].Add(fill.Fee)
+		if fill.ExecutedAt.After(summary.LastFillAt) {
+			summary.LastFillAt = fill.ExecutedAt
+		}
+	}
+
+	for _, summary := range summaries {
+		if summary.FilledQty.IsPositive() {
+			summary.AvgFillPrice = summary.QuoteQty.DivRound(summary.FilledQty, 18)
+		}
+	}
+
+	return summaries
+}
+
+func newFillSummary() *models.FillSummary {
+	return &models.FillSummary{TotalFees: make(map[string]decimal.Decimal)}
+}
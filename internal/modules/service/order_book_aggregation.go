@@ -0,0 +1,62 @@
+package service
+
+import (
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+
+	"github.com/shopspring/decimal"
+)
+
+/*
+AggregateOrderBook groups the levels of an order book into price buckets of size tick
+and keeps only the best depth buckets of each side.
+Asks are rounded up and bids down to the bucket boundary, so buckets never look better than the book.
+A zero tick keeps the original prices and a zero depth keeps all levels.
+*/
+func AggregateOrderBook(book *models.OrderBookDTO, depth int, tick decimal.Decimal) *models.OrderBookDTO {
+	aggregated := *book
+	aggregated.Asks = AggregateLevels(book.Asks, false, depth, tick)
+	aggregated.Bids = AggregateLevels(book.Bids, true, depth, tick)
+	return &aggregated
+}
+
+/*
+AggregateLevels buckets one side of the book, bids are descending and asks ascending.
+Returns the levels sorted best price first with quantities summed per bucket.
+*/
+func AggregateLevels(levels []*models.DepthOrder, descending bool, depth int, tick decimal.Decimal) []*models.DepthOrder {
+	sorted := analytics.SortedLevels(levels, descending)
+
+	aggregated := make([]*models.DepthOrder, 0, len(sorted))
+	for _, level := range sorted {
+		price := level.Price
+		if tick.IsPositive() {
+			price = bucketPrice(price, tick, descending)
+		}
+
+		if n := len(aggregated); n > 0 && aggregated[n-1].Price.Equal(price) {
+			aggregated[n-1].BaseQty = aggregated[n-1].BaseQty.Add(level.BaseQty)
+			continue
+		}
+		if depth > 0 && len(aggregated) == depth {
+			break
+		}
+		aggregated = append(aggregated, &models.DepthOrder{Price: price, BaseQty: level.BaseQty})
+	}
+
+	return aggregated
+}
+
+// bucketPrice rounds a positive price down (bids) or up (asks) to a multiple of tick.
+func bucketPrice(price, tick decimal.Decimal, down bool) decimal.Decimal {
+	remainder := price.Mod(tick)
+	if remainder.IsZero() {
+		return price
+	}
+
+	bucket := price.Sub(remainder)
+	if !down {
+		bucket = bucket.Add(tick)
+	}
+	return bucket
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrSlowConsumer is the error of a subscription closed because its buffer was full.
+var ErrSlowConsumer = errors.New("subscriber too slow")
+
+// orderBookHub delivers order book updates to the subscriptions of their exchange and pair.
+type orderBookHub struct {
+	mu            sync.RWMutex
+	subscriptions map[string]map[*OrderBookSubscription]struct{}
+	locks         map[string]*sync.Mutex
+}
+
+func newOrderBookHub() *orderBookHub {
+	return &orderBookHub{
+		subscriptions: make(map[string]map[*OrderBookSubscription]struct{}),
+		locks:         make(map[string]*sync.Mutex),
+	}
+}
+
+/*
+lock serializes the updates of an exchange and pair with the snapshots of subscriptions joining it,
+so that a subscription never receives an update ahead of its first snapshot.
+Returns the function releasing the lock.
+*/
+func (h *orderBookHub) lock(key string) func() {
+	h.mu.Lock()
+	lock, ok := h.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		h.locks[key] = lock
+	}
+	h.mu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// publish delivers the update to every subscription of its exchange and pair without waiting for them.
+func (h *orderBookHub) publish(update *models.OrderBookUpdate) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscription := range h.subscriptions[bookKey(update.Exchange, update.Pair)] {
+		subscription.push(update)
+	}
+}
+
+func (h *orderBookHub) join(subscription *OrderBookSubscription, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subscriptions, ok := h.subscriptions[key]
+	if !ok {
+		subscriptions = make(map[*OrderBookSubscription]struct{})
+		h.subscriptions[key] = subscriptions
+	}
+	subscriptions[subscription] = struct{}{}
+}
+
+func (h *orderBookHub) leave(subscription *OrderBookSubscription, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscriptions[key], subscription)
+	if len(h.subscriptions[key]) == 0 {
+		delete(h.subscriptions, key)
+	}
+}
+
+/*
+OrderBookSubscription receives the updates of the order books it joined on its Updates channel.
+A subscription that does not keep up is closed, its channel is then closed and Err returns ErrSlowConsumer.
+*/
+type OrderBookSubscription struct {
+	hub     *orderBookHub
+	updates chan *models.OrderBookUpdate
+
+	mu     sync.Mutex
+	keys   map[string]struct{}
+	closed bool
+	err    error
+}
+
+// Updates returns the channel updates are delivered on, closed when the subscription is closed.
+func (s *OrderBookSubscription) Updates() <-chan *models.OrderBookUpdate {
+	return s.updates
+}
+
+// Err returns ErrSlowConsumer if the subscription was closed for not keeping up, nil otherwise.
+func (s *OrderBookSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Leave stops the updates of an exchange and pair.
+func (s *OrderBookSubscription) Leave(exchangeName, pair string) {
+	key := bookKey(exchangeName, pair)
+
+	s.mu.Lock()
+	delete(s.keys, key)
+	s.mu.Unlock()
+
+	s.hub.leave(s, key)
+}
+
+// Close stops all updates and closes the updates channel.
+func (s *OrderBookSubscription) Close() {
+	s.mu.Lock()
+	keys := s.keys
+	s.keys = make(map[string]struct{})
+	s.closeLocked(nil)
+	s.mu.Unlock()
+
+	for key := range keys {
+		s.hub.leave(s, key)
+	}
+}
+
+func (s *OrderBookSubscription) join(exchangeName, pair string) bool {
+	key := bookKey(exchangeName, pair)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return false
+	}
+	s.keys[key] = struct{}{}
+	s.mu.Unlock()
+
+	s.hub.join(s, key)
+	return true
+}
+
+// push delivers an update, closing the subscription if its buffer is full.
+func (s *OrderBookSubscription) push(update *models.OrderBookUpdate) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.updates <- update:
+	default:
+		s.closeLocked(ErrSlowConsumer)
+	}
+}
+
+func (s *OrderBookSubscription) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.updates)
+}
+
+/*
+SubscribeOrderBooks creates a subscription buffering up to bufferSize updates.
+The subscription receives nothing until it joins order books with JoinOrderBook and must be closed when done.
+*/
+func (osi *orderServiceImpl) SubscribeOrderBooks(bufferSize int) *OrderBookSubscription {
+	return &OrderBookSubscription{
+		hub:     osi.hub,
+		updates: make(chan *models.OrderBookUpdate, bufferSize),
+		keys:    make(map[string]struct{}),
+	}
+}
+
+/*
+JoinOrderBook subscribes to the snapshots and deltas of an exchange and pair, starting with its current snapshot.
+The snapshot is the book reconstructed from deltas if there is one, otherwise the latest stored snapshot if there is any.
+Updates of the exchange and pair wait until the snapshot is delivered, so none is delivered ahead of it.
+*/
+func (osi *orderServiceImpl) JoinOrderBook(subscription *OrderBookSubscription, exchangeName, pair string) error {
+	unlock := osi.hub.lock(bookKey(exchangeName, pair))
+	defer unlock()
+
+	book := osi.books.get(exchangeName, pair)
+	if book != nil {
+		book.mu.Lock()
+		defer book.mu.Unlock()
+	}
+
+	if !subscription.join(exchangeName, pair) {
+		return nil
+	}
+
+	if book != nil && book.synced {
+		subscription.push(snapshotUpdate(book.snapshot()))
+		return nil
+	}
+
+	order, err := osi.repo.FindLatestOrder(exchangeName, pair, time.Time{})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	dto := order.ToDTO()
+	subscription.push(snapshotUpdate(&dto))
+	return nil
+}
+
+/*
+publishSnapshot replaces the reconstructed book of the snapshot's exchange and pair, if deltas are tracked for it,
+and publishes the snapshot while the book is locked so that no later delta is delivered ahead of it.
+A snapshot not newer than the last sequence number applied to the book is stale and neither replaces nor is published,
+as deltas already applied are ignored.
+*/
+func (osi *orderServiceImpl) publishSnapshot(order *models.OrderBookDTO) {
+	unlock := osi.hub.lock(bookKey(order.Exchange, order.Pair))
+	defer unlock()
+
+	if book := osi.books.get(order.Exchange, order.Pair); book != nil {
+		book.mu.Lock()
+		defer book.mu.Unlock()
+
+		if order.Sequence <= book.sequence {
+			return
+		}
+		book.reset(order)
+	}
+
+	osi.hub.publish(snapshotUpdate(order))
+}
+
+func snapshotUpdate(order *models.OrderBookDTO) *models.OrderBookUpdate {
+	return &models.OrderBookUpdate{
+		Type:     models.OrderBookUpdateSnapshot,
+		Exchange: order.Exchange,
+		Pair:     order.Pair,
+		Book:     order,
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+)
+
+var (
+	// ErrSequenceGap is returned when a delta does not directly follow the last applied sequence number.
+	ErrSequenceGap = errors.New("order book sequence gap")
+	// ErrOrderBookOutOfSync is returned for deltas received after a gap and before a new snapshot.
+	ErrOrderBookOutOfSync = errors.New("order book out of sync, snapshot required")
+)
+
+const (
+	// Number of applied deltas after which the reconstructed book is persisted.
+	snapshotEveryDeltas = 100
+	// Maximum time between persisted snapshots of a book receiving deltas.
+	snapshotPeriod = 10 * time.Second
+)
+
+/*
+bookState is an order book reconstructed in memory from a snapshot and the deltas applied to it
+Levels are kept in maps keyed by price and sorted when a snapshot is taken
+*/
+type bookState struct {
+	mu            sync.Mutex
+	id            int64
+	exchange      string
+	pair          string
+	asks          map[string]*models.DepthOrder
+	bids          map[string]*models.DepthOrder
+	exchangeTime  time.Time
+	sequence      int64
+	synced        bool
+	pending       int
+	lastPersisted time.Time
+}
+
+func newBookState(order *models.OrderBookDTO) *bookState {
+	book := &bookState{}
+	book.reset(order)
+	return book
+}
+
+// reset replaces the state of the book with a full snapshot.
+func (b *bookState) reset(order *models.OrderBookDTO) {
+	b.id = order.ID
+	b.exchange = order.Exchange
+	b.pair = order.Pair
+	b.asks = levelsToMap(order.Asks)
+	b.bids = levelsToMap(order.Bids)
+	b.exchangeTime = order.ExchangeTime
+	b.sequence = order.Sequence
+	b.synced = true
+	b.persisted()
+}
+
+/*
+apply updates the book with a delta.
+Deltas with a sequence number already applied are ignored,
+a gap in sequence numbers marks the book out of sync until the next snapshot.
+The resulting book is checked as a snapshot would be, a delta leaving it invalid is rejected
+with a ValidationError of the resulting levels and the book is left unchanged.
+Returns true if the delta was applied.
+*/
+func (b *bookState) apply(delta *models.OrderBookDelta, precision *models.PairPrecision) (bool, error) {
+	if !b.synced {
+		return false, ErrOrderBookOutOfSync
+	}
+	if delta.Sequence <= b.sequence {
+		return false, nil
+	}
+	if delta.Sequence != b.sequence+1 {
+		b.synced = false
+		return false, ErrSequenceGap
+	}
+
+	asks := copyLevels(b.asks)
+	bids := copyLevels(b.bids)
+	applyLevels(asks, delta.Asks)
+	applyLevels(bids, delta.Bids)
+	if err := validateOrderBook(mapToLevels(asks, false), mapToLevels(bids, true), precision); err != nil {
+		return false, err
+	}
+
+	b.asks = asks
+	b.bids = bids
+	b.sequence = delta.Sequence
+	b.exchangeTime = delta.ExchangeTime
+	b.pending++
+	return true, nil
+}
+
+// shouldPersist reports whether enough deltas or time have passed since the last persisted snapshot.
+func (b *bookState) shouldPersist() bool {
+	return b.pending >= snapshotEveryDeltas || b.stale()
+}
+
+// stale reports whether deltas applied to the book have not been persisted for the snapshot period.
+func (b *bookState) stale() bool {
+	return b.pending > 0 && time.Since(b.lastPersisted) >= snapshotPeriod
+}
+
+// persisted records that the current state of the book has been saved.
+func (b *bookState) persisted() {
+	b.pending = 0
+	b.lastPersisted = time.Now()
+}
+
+// snapshot returns the current state of the book with asks ascending and bids descending.
+func (b *bookState) snapshot() *models.OrderBookDTO {
+	return &models.OrderBookDTO{
+		ID:           b.id,
+		Exchange:     b.exchange,
+		Pair:         b.pair,
+		Asks:         mapToLevels(b.asks, false),
+		Bids:         mapToLevels(b.bids, true),
+		ExchangeTime: b.exchangeTime,
+		ReceivedTime: time.Now().UTC(),
+		Sequence:     b.sequence,
+	}
+}
+
+func levelsToMap(levels []*models.DepthOrder) map[string]*models.DepthOrder {
+	m := make(map[string]*models.DepthOrder, len(levels))
+	applyLevels(m, levels)
+	return m
+}
+
+func copyLevels(m map[string]*models.DepthOrder) map[string]*models.DepthOrder {
+	levels := make(map[string]*models.DepthOrder, len(m))
+	for key, level := range m {
+		levels[key] = level
+	}
+	return levels
+}
+
+func applyLevels(m map[string]*models.DepthOrder, levels []*models.DepthOrder) {
+	for _, level := range levels {
+		key := level.Price.String()
+		if level.BaseQty.IsZero() {
+			delete(m, key)
+			continue
+		}
+		m[key] = &models.DepthOrder{Price: level.Price, BaseQty: level.BaseQty}
+	}
+}
+
+func mapToLevels(m map[string]*models.DepthOrder, descending bool) []*models.DepthOrder {
+	levels := make([]*models.DepthOrder, 0, len(m))
+	for _, level := range m {
+		levels = append(levels, &models.DepthOrder{Price: level.Price, BaseQty: level.BaseQty})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price.GreaterThan(levels[j].Price)
+		}
+		return levels[i].Price.LessThan(levels[j].Price)
+	})
+	return levels
+}
+
+// bookStore holds the reconstructed order book of every exchange and pair receiving deltas.
+type bookStore struct {
+	mu    sync.Mutex
+	books map[string]*bookState
+}
+
+func newBookStore() *bookStore {
+	return &bookStore{books: make(map[string]*bookState)}
+}
+
+func bookKey(exchangeName, pair string) string {
+	return exchangeName + "|" + pair
+}
+
+func (s *bookStore) get(exchangeName, pair string) *bookState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.books[bookKey(exchangeName, pair)]
+}
+
+// all returns every book in the store.
+func (s *bookStore) all() []*bookState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	books := make([]*bookState, 0, len(s.books))
+	for _, book := range s.books {
+		books = append(books, book)
+	}
+	return books
+}
+
+/*
+seed adds the book for the snapshot's exchange and pair unless one already exists.
+Returns the book stored for the exchange and pair.
+*/
+func (s *bookStore) seed(order *models.OrderBookDTO) *bookState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := bookKey(order.Exchange, order.Pair)
+	if book, ok := s.books[key]; ok {
+		return book
+	}
+	book := newBookState(order)
+	s.books[key] = book
+	return book
+}
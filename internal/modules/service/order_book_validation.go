@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Violation describes a single rule an order book does not satisfy.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when an order book is rejected, listing every violation found.
+type ValidationError struct {
+	Violations []Violation `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Field + ": " + v.Message
+	}
+	return "invalid order book: " + strings.Join(messages, "; ")
+}
+
+/*
+validateOrderBook checks that asks are sorted ascending and bids descending without duplicate levels,
+every price and quantity is positive and the best bid is below the best ask.
+If the precision of the pair is known, values must not have more decimal places than it allows.
+Returns a ValidationError with all violations, or nil for a valid book.
+*/
+func validateOrderBook(asks, bids []*models.DepthOrder, precision *models.PairPrecision) error {
+	var violations []Violation
+	violations = append(violations, validateSide("asks", asks, false, precision)...)
+	violations = append(violations, validateSide("bids", bids, true, precision)...)
+
+	if len(asks) > 0 && len(bids) > 0 && asks[0] != nil && bids[0] != nil && bids[0].Price.GreaterThanOrEqual(asks[0].Price) {
+		violations = append(violations, Violation{
+			Field:   "bids[0].price",
+			Message: fmt.Sprintf("book is crossed: best bid %v is not below best ask %v", bids[0].Price, asks[0].Price),
+		})
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func validateSide(side string, levels []*models.DepthOrder, descending bool, precision *models.PairPrecision) []Violation {
+	var violations []Violation
+	var prev *models.DepthOrder
+
+	for i, level := range levels {
+		field := fmt.Sprintf("%s[%d]", side, i)
+		if level == nil {
+			violations = append(violations, Violation{Field: field, Message: "level is missing"})
+			continue
+		}
+
+		if !level.Price.IsPositive() {
+			violations = append(violations, Violation{
+				Field:   field + ".price",
+				Message: fmt.Sprintf("price must be a positive number, got %v", level.Price),
+			})
+		}
+		if !level.BaseQty.IsPositive() {
+			violations = append(violations, Violation{
+				Field:   field + ".baseQty",
+				Message: fmt.Sprintf("quantity must be a positive number, got %v", level.BaseQty),
+			})
+		}
+		if precision != nil {
+			violations = append(violations, validateScale(field+".price", level.Price, precision.PriceScale)...)
+			violations = append(violations, validateScale(field+".baseQty", level.BaseQty, precision.QtyScale)...)
+		}
+
+		if prev != nil {
+			switch {
+			case level.Price.Equal(prev.Price):
+				violations = append(violations, Violation{
+					Field:   field + ".price",
+					Message: fmt.Sprintf("duplicate price level %v", level.Price),
+				})
+			case descending && level.Price.GreaterThan(prev.Price):
+				violations = append(violations, Violation{
+					Field:   field + ".price",
+					Message: fmt.Sprintf("%s must be sorted descending, %v follows %v", side, level.Price, prev.Price),
+				})
+			case !descending && level.Price.LessThan(prev.Price):
+				violations = append(violations, Violation{
+					Field:   field + ".price",
+					Message: fmt.Sprintf("%s must be sorted ascending, %v follows %v", side, level.Price, prev.Price),
+				})
+			}
+		}
+		prev = level
+	}
+
+	return violations
+}
+
+// validateScale checks that value has at most scale decimal places.
+func validateScale(field string, value decimal.Decimal, scale int32) []Violation {
+	if value.Equal(value.Truncate(scale)) {
+		return nil
+	}
+	return []Violation{{
+		Field:   field,
+		Message: fmt.Sprintf("%v has more than %d decimal places", value, scale),
+	}}
+}
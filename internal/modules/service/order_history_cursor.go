@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/kymaka/vortex-test/internal/models"
+)
+
+// ErrInvalidCursor is returned for an order history cursor that was not issued for the requested sort order.
+var ErrInvalidCursor = errors.New("invalid order history cursor")
+
+// Number of orders per order history page if no limit is given, and the highest limit allowed.
+const (
+	DefaultHistoryLimit = 100
+	MaxHistoryLimit     = 1000
+)
+
+// encodeCursor returns an opaque cursor pointing after the order.
+func encodeCursor(order *models.HistoryOrder, sort string) string {
+	bytes, _ := json.Marshal(models.OrderHistoryCursor{
+		TimePlaced: order.TimePlaced,
+		OrderID:    order.OrderID,
+		Sort:       sort,
+	})
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeCursor parses a cursor returned by encodeCursor, returns nil for an empty cursor.
+func decodeCursor(cursor, sort string) (*models.OrderHistoryCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	bytes, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var position models.OrderHistoryCursor
+	if err := json.Unmarshal(bytes, &position); err != nil || position.OrderID == "" || position.Sort != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	return &position, nil
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
+)
+
+// ErrInvalidEventID is returned for a last event ID that was not issued by the order history stream.
+var ErrInvalidEventID = errors.New("invalid order history event ID")
+
+// Number of missed orders read per query when resuming the order history stream
+const OrderReplayPageSize = MaxHistoryLimit
+
+// orderHub delivers saved orders to the subscriptions whose filter they match.
+type orderHub struct {
+	mu            sync.RWMutex
+	subscriptions map[*OrderSubscription]struct{}
+
+	saveMu     sync.Mutex
+	lastIngest time.Time
+	inFlight   []*orderSave
+}
+
+// orderSave is a save whose orders are stamped with ingest times, published once it and the saves before it are done.
+type orderSave struct {
+	orders []*models.HistoryOrder
+	done   bool
+	stored bool
+}
+
+func newOrderHub() *orderHub {
+	return &orderHub{subscriptions: make(map[*OrderSubscription]struct{})}
+}
+
+// publish delivers the orders to the matching subscriptions without waiting for them.
+func (h *orderHub) publish(orders ...*models.HistoryOrder) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for subscription := range h.subscriptions {
+		for _, order := range orders {
+			if subscription.filter.Matches(order) {
+				subscription.push(&models.OrderEvent{ID: encodeEventID(order), Order: order})
+			}
+		}
+	}
+}
+
+/*
+save stamps the orders with increasing ingest times, stores them with store and publishes them once stored.
+Saves store concurrently, but are published in the order of their ingest times, a save waits for the saves
+stamped before it to be done and is published with them. A subscription resuming after an event
+then does not miss an order ingested before it. Orders that fail to store are not published.
+*/
+func (h *orderHub) save(orders []*models.HistoryOrder, store func() error) error {
+	h.saveMu.Lock()
+	for _, order := range orders {
+		ingestedAt := time.Now().UTC().Truncate(time.Microsecond)
+		if !ingestedAt.After(h.lastIngest) {
+			ingestedAt = h.lastIngest.Add(time.Microsecond)
+		}
+		order.IngestedAt = ingestedAt
+		h.lastIngest = ingestedAt
+	}
+	pending := &orderSave{orders: orders}
+	h.inFlight = append(h.inFlight, pending)
+	h.saveMu.Unlock()
+
+	err := store()
+
+	h.saveMu.Lock()
+	defer h.saveMu.Unlock()
+
+	pending.done, pending.stored = true, err == nil
+	for len(h.inFlight) > 0 && h.inFlight[0].done {
+		if h.inFlight[0].stored {
+			h.publish(h.inFlight[0].orders...)
+		}
+		h.inFlight = h.inFlight[1:]
+	}
+	return err
+}
+
+/*
+watermark returns the ingest time from which saves are not published yet,
+every order ingested before it is stored or failed to store and no longer published.
+*/
+func (h *orderHub) watermark() time.Time {
+	h.saveMu.Lock()
+	defer h.saveMu.Unlock()
+
+	for _, pending := range h.inFlight {
+		if len(pending.orders) > 0 {
+			return pending.orders[0].IngestedAt
+		}
+	}
+	return h.lastIngest.Add(time.Microsecond)
+}
+
+func (h *orderHub) join(subscription *OrderSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscriptions[subscription] = struct{}{}
+}
+
+func (h *orderHub) leave(subscription *OrderSubscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscriptions, subscription)
+}
+
+/*
+OrderSubscription receives the orders saved for its filter on its Events channel.
+A subscription that does not keep up is closed, its channel is then closed and Err returns ErrSlowConsumer.
+A subscription whose replay fails is closed the same way, Err then returns the error of the replay.
+*/
+type OrderSubscription struct {
+	hub    *orderHub
+	filter models.OrderStreamFilter
+
+	mu     sync.Mutex
+	live   chan *models.OrderEvent
+	events chan *models.OrderEvent
+	done   chan struct{}
+	closed bool
+	err    error
+}
+
+// Events returns the channel orders are delivered on, closed when the subscription is closed.
+func (s *OrderSubscription) Events() <-chan *models.OrderEvent {
+	return s.events
+}
+
+// Err returns ErrSlowConsumer if the subscription was closed for not keeping up, the error of a failed replay, or nil.
+func (s *OrderSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Close stops the delivery of orders and closes the events channel.
+func (s *OrderSubscription) Close() {
+	s.fail(nil)
+}
+
+// fail closes the subscription with err.
+func (s *OrderSubscription) fail(err error) {
+	s.hub.leave(s)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closeLocked(err)
+}
+
+// push delivers an event, closing the subscription if its buffer is full.
+func (s *OrderSubscription) push(event *models.OrderEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.live <- event:
+	default:
+		s.closeLocked(ErrSlowConsumer)
+	}
+}
+
+func (s *OrderSubscription) closeLocked(err error) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.live)
+	close(s.done)
+}
+
+/*
+resume delivers the orders saved after the position and before the watermark, page by page starting from the
+first page, then the orders published live, skipping those the replay delivered. It runs until the subscription
+is closed, and closes the events channel.
+*/
+func (s *OrderSubscription) resume(repo repository.OrderRepository, after *models.OrderEventPosition, watermark time.Time, first []*models.HistoryOrder) {
+	defer close(s.events)
+
+	send := func(order *models.HistoryOrder) bool {
+		select {
+		case s.events <- &models.OrderEvent{ID: encodeEventID(order), Order: order}:
+			return true
+		case <-s.done:
+			return false
+		}
+	}
+
+	page := first
+	for {
+		for _, order := range page {
+			if !order.IngestedAt.Before(watermark) {
+				page = nil
+				break
+			}
+			if !send(order) {
+				return
+			}
+			position := models.EventPosition(order)
+			after = &position
+		}
+		if len(page) < OrderReplayPageSize {
+			break
+		}
+
+		var err error
+		if page, err = repo.FindOrdersSavedAfter(&s.filter, after, OrderReplayPageSize); err != nil {
+			s.fail(fmt.Errorf("replaying orders: %w", err))
+			return
+		}
+	}
+
+	for event := range s.live {
+		if event.Order.IngestedAt.Before(watermark) || !after.Before(models.EventPosition(event.Order)) {
+			continue
+		}
+		if !send(event.Order) {
+			return
+		}
+	}
+}
+
+/*
+SubscribeOrders creates a subscription to the order states saved for the filter, buffering up to bufferSize states.
+If lastEventID is given, the states stored after it are replayed first, oldest first, until the replay reaches
+the states published live. States replaced by a later state of their order when ClickHouse merges parts
+are not replayed, the later state is. If the replay fails after the first page, the subscription is closed
+with the error. Returns ErrInvalidEventID for a last event ID that was not issued by the stream.
+The subscription must be closed when done.
+*/
+func (osi *orderServiceImpl) SubscribeOrders(filter *models.OrderStreamFilter, lastEventID string, bufferSize int) (*OrderSubscription, error) {
+	var after *models.OrderEventPosition
+	if lastEventID != "" {
+		position, err := decodeEventID(lastEventID)
+		if err != nil {
+			return nil, err
+		}
+		after = position
+	}
+
+	live := make(chan *models.OrderEvent, bufferSize)
+	subscription := &OrderSubscription{
+		hub:    osi.orders,
+		filter: *filter,
+		live:   live,
+		events: live,
+		done:   make(chan struct{}),
+	}
+	// Joins before taking the watermark so that every order from it on is published to the subscription
+	osi.orders.join(subscription)
+
+	if after == nil {
+		return subscription, nil
+	}
+
+	watermark := osi.orders.watermark()
+	orders, err := osi.repo.FindOrdersSavedAfter(filter, after, OrderReplayPageSize)
+	if err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	subscription.events = make(chan *models.OrderEvent)
+	go subscription.resume(osi.repo, after, watermark, orders)
+
+	return subscription, nil
+}
+
+// encodeEventID returns an opaque event ID pointing after the order state.
+func encodeEventID(order *models.HistoryOrder) string {
+	bytes, _ := json.Marshal(models.EventPosition(order))
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// decodeEventID parses an event ID returned by encodeEventID.
+func decodeEventID(id string) (*models.OrderEventPosition, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return nil, ErrInvalidEventID
+	}
+
+	var position models.OrderEventPosition
+	if err := json.Unmarshal(bytes, &position); err != nil || position.OrderID == "" {
+		return nil, ErrInvalidEventID
+	}
+
+	return &position, nil
+}
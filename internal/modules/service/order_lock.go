@@ -0,0 +1,68 @@
+package service
+
+import (
+	"slices"
+	"sync"
+)
+
+/*
+orderLocks serializes the changes of every order ID within the service,
+so that checking the current state of an order and storing its next state happen as one step.
+Locks are created when first needed and removed once no one holds or waits for them.
+*/
+type orderLocks struct {
+	mu    sync.Mutex
+	locks map[string]*orderLock
+}
+
+type orderLock struct {
+	sync.Mutex
+	users int
+}
+
+func newOrderLocks() *orderLocks {
+	return &orderLocks{locks: make(map[string]*orderLock)}
+}
+
+/*
+lock locks every given order ID, in sorted order so that callers locking several IDs cannot deadlock.
+Empty and repeated IDs are ignored. Returns the function releasing the locks.
+*/
+func (l *orderLocks) lock(orderIDs ...string) func() {
+	ids := make([]string, 0, len(orderIDs))
+	for _, orderID := range orderIDs {
+		if orderID != "" {
+			ids = append(ids, orderID)
+		}
+	}
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	held := make([]*orderLock, len(ids))
+	for i, orderID := range ids {
+		l.mu.Lock()
+		lock, ok := l.locks[orderID]
+		if !ok {
+			lock = &orderLock{}
+			l.locks[orderID] = lock
+		}
+		lock.users++
+		l.mu.Unlock()
+
+		lock.Lock()
+		held[i] = lock
+	}
+
+	return func() {
+		for i := len(ids) - 1; i >= 0; i-- {
+			held[i].Unlock()
+
+			l.mu.Lock()
+			held[i].users--
+			if held[i].users == 0 {
+				delete(l.locks, ids[i])
+			}
+			l.mu.Unlock()
+		}
+	}
+}
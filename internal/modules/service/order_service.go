@@ -1,30 +1,92 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
 	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
 	"github.com/kymaka/vortex-test/internal/modules/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
+// Age after which the latest snapshot of an exchange is left out of consolidated order books by default
+const DefaultMaxBookAge = 30 * time.Second
+
 type OrderService interface {
-	GetOrderBook(exchangeName, pair string) ([]*models.OrderBookDTO, error)
-	SaveOrderBook(id int64, exchangeName, pair string, asks, bids []*models.DepthOrder) error
-	GetOrderHistory(client *models.Client) ([]*models.HistoryOrder, error)
-	SaveOrder(client *models.Client, order *models.HistoryOrder) error
+	GetOrderBook(exchangeName, pair string, depth int, tick decimal.Decimal) ([]*models.OrderBookDTO, error)
+	GetLatestOrderBook(exchangeName, pair string, asOf time.Time) (*models.OrderBookDTO, error)
+	GetOrderBookMetrics(exchangeName, pair string, asOf time.Time, levels int, bands []float64) (*analytics.OrderBookMetrics, error)
+	EstimateMarketImpact(exchangeName, pair, side string, baseQty decimal.Decimal) (*analytics.MarketImpact, error)
+	GetConsolidatedOrderBook(pair string, maxAge time.Duration) (*analytics.ConsolidatedOrderBook, error)
+	SaveOrderBook(order *models.OrderBookDTO) error
+	SaveOrderBookDelta(delta *models.OrderBookDelta) error
+	GetOrderHistory(filter *models.OrderHistoryFilter) (*models.OrderHistoryPage, error)
+	SaveOrder(client *models.Client, order *models.HistoryOrder) (*models.HistoryOrder, error)
+	UpdateOrderStatus(orderID string, update *models.OrderStatusUpdate) (*models.HistoryOrder, error)
+	SaveFills(fills []*models.Fill) error
+	GetClientPnL(filter *models.OrderHistoryFilter, method string) ([]*analytics.PnL, error)
+	GetExecutionQuality(filter *models.OrderHistoryFilter) (*analytics.ExecutionQualityReport, error)
+	GetAlgorithms() ([]*models.Algorithm, error)
+	GetAlgorithmStats(name string, from, to time.Time) (*models.AlgorithmStats, error)
+	GetCandles(exchangeName, pair, interval string, from, to time.Time, mids bool) (*models.Candles, error)
+	GetFeeReport(filter *models.FeeReportFilter) (*models.FeeReport, error)
+	SaveOrderBooks(orders []*models.OrderBookDTO) (*BatchResult, error)
+	SaveOrders(payloads []*models.HistoryOrderPayload) (*BatchResult, error)
+	SubscribeOrderBooks(bufferSize int) *OrderBookSubscription
+	JoinOrderBook(subscription *OrderBookSubscription, exchangeName, pair string) error
+	SubscribeOrders(filter *models.OrderStreamFilter, lastEventID string, bufferSize int) (*OrderSubscription, error)
+	ExportOrderBooks(exchangeName, pair string, from, to time.Time, depth int, tick decimal.Decimal, fn func(*models.OrderBookDTO) error) error
+	ExportOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error
+	GetPairPrecision(exchangeName, pair string) (*models.PairPrecision, error)
+	SavePairPrecision(precision *models.PairPrecision) error
+	Close() error
 }
 
 type orderServiceImpl struct {
-	repo repository.OrderRepository
+	repo       repository.OrderRepository
+	books      *bookStore
+	precisions *precisionCache
+	hub        *orderBookHub
+	orders     *orderHub
+	orderLocks *orderLocks
+	done       chan struct{}
+	closed     chan struct{}
+	closeOnce  sync.Once
 }
 
+/*
+NewOrderService creates the service and starts persisting reconstructed order books in the background.
+Close must be called to persist the deltas applied since the last snapshot.
+*/
 func NewOrderService(r repository.OrderRepository) OrderService {
-	return &orderServiceImpl{repo: r}
+	osi := &orderServiceImpl{
+		repo:       r,
+		books:      newBookStore(),
+		precisions: newPrecisionCache(),
+		hub:        newOrderBookHub(),
+		orders:     newOrderHub(),
+		orderLocks: newOrderLocks(),
+		done:       make(chan struct{}),
+		closed:     make(chan struct{}),
+	}
+
+	go osi.runSnapshots()
+	return osi
 }
 
 /*
 GetOrderBook retrieves the order book for a specific exchange and trading pair.
 Converts the order book model to a DTO before returning.
+If depth or tick are set, levels are bucketed by tick and truncated to depth levels per side.
 */
-func (osi *orderServiceImpl) GetOrderBook(exchangeName, pair string) ([]*models.OrderBookDTO, error) {
+func (osi *orderServiceImpl) GetOrderBook(exchangeName, pair string, depth int, tick decimal.Decimal) ([]*models.OrderBookDTO, error) {
 	orders, err := osi.repo.FindOrder(exchangeName, pair)
 	if err != nil {
 		return nil, err
@@ -33,43 +95,444 @@ func (osi *orderServiceImpl) GetOrderBook(exchangeName, pair string) ([]*models.
 	ordersDTO := make([]*models.OrderBookDTO, 0, len(orders))
 	for _, order := range orders {
 		dto := order.ToDTO()
+		if depth > 0 || tick.IsPositive() {
+			ordersDTO = append(ordersDTO, AggregateOrderBook(&dto, depth, tick))
+			continue
+		}
 		ordersDTO = append(ordersDTO, &dto)
 	}
 	return ordersDTO, nil
 }
 
 /*
-SaveOrderBook saves the order book details using the provided parameters.
-Converts the DTO to a model before saving to the repository.
+GetLatestOrderBook retrieves the order book in effect at asOf for a specific exchange and trading pair.
+A zero asOf returns the most recent order book.
+*/
+func (osi *orderServiceImpl) GetLatestOrderBook(exchangeName, pair string, asOf time.Time) (*models.OrderBookDTO, error) {
+	order, err := osi.repo.FindLatestOrder(exchangeName, pair, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	dto := order.ToDTO()
+	return &dto, nil
+}
+
+/*
+GetOrderBookMetrics computes top of book and depth metrics of the order book in effect at asOf.
+A zero asOf uses the most recent order book.
 */
-func (osi *orderServiceImpl) SaveOrderBook(id int64, exchangeName, pair string, asks, bids []*models.DepthOrder) error {
-	orderDTO := models.OrderBookDTO{
-		ID:       id,
-		Exchange: exchangeName,
-		Pair:     pair,
-		Asks:     asks,
-		Bids:     bids,
+func (osi *orderServiceImpl) GetOrderBookMetrics(exchangeName, pair string, asOf time.Time, levels int, bands []float64) (*analytics.OrderBookMetrics, error) {
+	order, err := osi.GetLatestOrderBook(exchangeName, pair, asOf)
+	if err != nil {
+		return nil, err
 	}
 
-	order := orderDTO.ToOrderBook()
-	return osi.repo.SaveOrder(order)
+	return analytics.ComputeMetrics(order, levels, bands)
 }
 
-// GetOrderHistory retrieves the order history for a given client.
-func (osi *orderServiceImpl) GetOrderHistory(client *models.Client) ([]*models.HistoryOrder, error) {
-	return osi.repo.FindOrderHistory(client)
+/*
+EstimateMarketImpact estimates the fill of a market order of baseQty on the given side
+against the latest order book of the exchange and trading pair.
+*/
+func (osi *orderServiceImpl) EstimateMarketImpact(exchangeName, pair, side string, baseQty decimal.Decimal) (*analytics.MarketImpact, error) {
+	order, err := osi.GetLatestOrderBook(exchangeName, pair, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	return analytics.EstimateImpact(order, side, baseQty)
+}
+
+/*
+GetConsolidatedOrderBook merges the latest order book of every exchange trading the pair
+into a single book with per-venue attribution and cross-venue arbitrage.
+Exchanges whose latest snapshot is older than maxAge, DefaultMaxBookAge if not positive,
+are left out and listed as excluded so stale quotes do not show as arbitrage.
+*/
+func (osi *orderServiceImpl) GetConsolidatedOrderBook(pair string, maxAge time.Duration) (*analytics.ConsolidatedOrderBook, error) {
+	if maxAge <= 0 {
+		maxAge = DefaultMaxBookAge
+	}
+
+	orders, err := osi.repo.FindLatestOrdersByPair(pair)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]*models.OrderBookDTO, 0, len(orders))
+	for _, order := range orders {
+		dto := order.ToDTO()
+		books = append(books, &dto)
+	}
+
+	fresh, stale := analytics.SplitStale(books, time.Now(), maxAge)
+	consolidated := analytics.Consolidate(pair, fresh)
+	consolidated.Excluded = stale
+	return consolidated, nil
+}
+
+/*
+SaveOrderBook validates and saves an order book snapshot.
+Returns a ValidationError if the levels are unsorted, crossed, duplicated, not positive
+or more precise than the pair precision.
+Stamps the snapshot with the current time if ReceivedTime is not set
+and converts the DTO to a model before saving to the repository.
+*/
+func (osi *orderServiceImpl) SaveOrderBook(orderDTO *models.OrderBookDTO) error {
+	dto, err := osi.prepareOrderBook(orderDTO)
+	if err != nil {
+		return err
+	}
+
+	order := dto.ToOrderBook()
+	if err := osi.repo.SaveOrder(order); err != nil {
+		return err
+	}
+
+	osi.publishSnapshot(dto)
+	return nil
+}
+
+// prepareOrderBook validates an order book and stamps it with the current time if it has no received time.
+func (osi *orderServiceImpl) prepareOrderBook(orderDTO *models.OrderBookDTO) (*models.OrderBookDTO, error) {
+	precision, err := osi.pairPrecision(orderDTO.Exchange, orderDTO.Pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateOrderBook(orderDTO.Asks, orderDTO.Bids, precision); err != nil {
+		return nil, err
+	}
+
+	dto := *orderDTO
+	if dto.ReceivedTime.IsZero() {
+		dto.ReceivedTime = time.Now().UTC()
+	}
+
+	return &dto, nil
+}
+
+/*
+SaveOrderBookDelta applies an incremental update to the order book reconstructed in memory.
+The book is seeded from the latest stored snapshot on the first delta for an exchange and pair
+and is persisted as a full snapshot periodically.
+Returns ErrSequenceGap if updates were missed, the book then requires a new snapshot,
+or a ValidationError if the delta would leave the book invalid.
+*/
+func (osi *orderServiceImpl) SaveOrderBookDelta(delta *models.OrderBookDelta) error {
+	precision, err := osi.pairPrecision(delta.Exchange, delta.Pair)
+	if err != nil {
+		return err
+	}
+
+	unlock := osi.hub.lock(bookKey(delta.Exchange, delta.Pair))
+	defer unlock()
+
+	book := osi.books.get(delta.Exchange, delta.Pair)
+	if book == nil {
+		order, err := osi.repo.FindLatestOrder(delta.Exchange, delta.Pair, time.Time{})
+		if err != nil {
+			return err
+		}
+
+		dto := order.ToDTO()
+		book = osi.books.seed(&dto)
+	}
+
+	book.mu.Lock()
+	defer book.mu.Unlock()
+
+	applied, err := book.apply(delta, precision)
+	if err != nil || !applied {
+		return err
+	}
+
+	osi.hub.publish(&models.OrderBookUpdate{
+		Type:     models.OrderBookUpdateDelta,
+		Exchange: delta.Exchange,
+		Pair:     delta.Pair,
+		Delta:    delta,
+	})
+
+	if !book.shouldPersist() {
+		return nil
+	}
+
+	if err := osi.repo.SaveOrder(book.snapshot().ToOrderBook()); err != nil {
+		return err
+	}
+
+	book.persisted()
+	return nil
+}
+
+// runSnapshots persists books whose applied deltas are older than the snapshot period until the service is closed.
+func (osi *orderServiceImpl) runSnapshots() {
+	ticker := time.NewTicker(snapshotPeriod / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := osi.persistBooks(false); err != nil {
+				log.Printf("failed to persist order books: %v", err)
+			}
+		case <-osi.done:
+			close(osi.closed)
+			return
+		}
+	}
+}
+
+/*
+persistBooks saves a snapshot of every reconstructed book with deltas not yet persisted,
+only of those stale for the snapshot period unless all is set.
+*/
+func (osi *orderServiceImpl) persistBooks(all bool) error {
+	var errs []error
+	for _, book := range osi.books.all() {
+		book.mu.Lock()
+		if book.stale() || (all && book.pending > 0) {
+			if err := osi.repo.SaveOrder(book.snapshot().ToOrderBook()); err != nil {
+				errs = append(errs, fmt.Errorf("%s %s: %w", book.exchange, book.pair, err))
+			} else {
+				book.persisted()
+			}
+		}
+		book.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+/*
+Close stops the background snapshots and persists the deltas applied to every book since its last snapshot.
+Returns the errors of books that could not be saved.
+*/
+func (osi *orderServiceImpl) Close() error {
+	osi.closeOnce.Do(func() { close(osi.done) })
+	<-osi.closed
+	return osi.persistBooks(true)
+}
+
+/*
+GetOrderHistory retrieves a page of the order history of a client matching the filter.
+Every order comes with a summary of its fills.
+Returns ErrInvalidCursor if the cursor was not issued for the sort order of the filter.
+*/
+func (osi *orderServiceImpl) GetOrderHistory(filter *models.OrderHistoryFilter) (*models.OrderHistoryPage, error) {
+	query := *filter
+	if query.Sort == "" {
+		query.Sort = models.SortAsc
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	limit = min(limit, MaxHistoryLimit)
+
+	after, err := decodeCursor(query.Cursor, query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	// One extra order tells whether there is a next page
+	orders, err := osi.repo.FindOrderHistory(&query, after, limit+1)
+	if err != nil {
+		if after != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.OrderHistoryPage{Orders: []*models.HistoryOrder{}}, nil
+		}
+		return nil, err
+	}
+
+	page := &models.OrderHistoryPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+		page.NextCursor = encodeCursor(page.Orders[limit-1], query.Sort)
+	}
+
+	fills, err := osi.findFills(page.Orders)
+	if err != nil {
+		return nil, err
+	}
+	attachFillSummaries(page.Orders, fills)
+
+	return page, nil
 }
 
 /*
 SaveOrder saves an order history record for a given client.
 Adds client details to the order before saving to the repository.
+Orders without an ID get a generated one, orders without a status are NEW.
+Returns the saved order, or a ValidationError if the status is unknown, the order ID already exists
+or prices or quantity are more precise than the pair precision.
+Saves and status updates of the same order ID are serialized, so only one of concurrent saves of an ID succeeds.
+*/
+func (osi *orderServiceImpl) SaveOrder(client *models.Client, order *models.HistoryOrder) (*models.HistoryOrder, error) {
+	unlock := osi.orderLocks.lock(order.OrderID)
+	defer unlock()
+
+	if order.OrderID != "" {
+		if err := osi.checkNewOrderID(order.OrderID); err != nil {
+			return nil, err
+		}
+	}
+
+	newOrder, err := osi.prepareOrder(client, order)
+	if err != nil {
+		return nil, err
+	}
+
+	err = osi.orders.save([]*models.HistoryOrder{newOrder}, func() error {
+		return osi.repo.SaveOrderHistory(*newOrder)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newOrder, nil
+}
+
+/*
+prepareOrder assigns an order to the client, fills in its defaults and validates its status and precision.
+Callers check that an order ID given by the client does not exist yet, stored orders only change through UpdateOrderStatus.
 */
-func (osi *orderServiceImpl) SaveOrder(client *models.Client, order *models.HistoryOrder) error {
+func (osi *orderServiceImpl) prepareOrder(client *models.Client, order *models.HistoryOrder) (*models.HistoryOrder, error) {
 	newOrder := *order
 	newOrder.ClientName = client.ClientName
 	newOrder.ExchangeName = client.ExchangeName
 	newOrder.Label = client.Label
 	newOrder.Pair = client.Pair
 
-	return osi.repo.SaveOrderHistory(newOrder)
+	if newOrder.OrderID == "" {
+		newOrder.OrderID = uuid.NewString()
+	}
+	if newOrder.Status == "" {
+		newOrder.Status = models.OrderStatusNew
+	}
+	if newOrder.UpdatedAt.IsZero() {
+		newOrder.UpdatedAt = time.Now().UTC()
+	}
+
+	if !isKnownStatus(newOrder.Status) {
+		return nil, &ValidationError{Violations: []Violation{{
+			Field:   "status",
+			Message: fmt.Sprintf("unknown status %q", newOrder.Status),
+		}}}
+	}
+
+	precision, err := osi.pairPrecision(newOrder.ExchangeName, newOrder.Pair)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateOrderPrecision(&newOrder, precision); err != nil {
+		return nil, err
+	}
+
+	return &newOrder, nil
+}
+
+/*
+checkNewOrderID returns a ValidationError if an order with the ID is already stored,
+as saving it again would replace its current state without checking the status transition.
+*/
+func (osi *orderServiceImpl) checkNewOrderID(orderID string) error {
+	_, err := osi.repo.FindHistoryOrder(orderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return &ValidationError{Violations: []Violation{existingOrderViolation(orderID)}}
+}
+
+func existingOrderViolation(orderID string) Violation {
+	return Violation{
+		Field:   "orderId",
+		Message: fmt.Sprintf("order %q already exists, its status is updated through POST /order/history/{id}/status", orderID),
+	}
+}
+
+// findHistoryOrders retrieves the current state of the stored orders of the IDs by order ID, in batches of at most MaxHistoryLimit IDs.
+func (osi *orderServiceImpl) findHistoryOrders(orderIDs []string) (map[string]*models.HistoryOrder, error) {
+	orders := make(map[string]*models.HistoryOrder, len(orderIDs))
+	for i := 0; i < len(orderIDs); i += MaxHistoryLimit {
+		batch, err := osi.repo.FindHistoryOrders(orderIDs[i:min(i+MaxHistoryLimit, len(orderIDs))])
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range batch {
+			orders[order.OrderID] = order
+		}
+	}
+
+	return orders, nil
+}
+
+/*
+UpdateOrderStatus records a status transition of an order.
+The current state of the order is stored again with the new status and update time and delivered on the order history stream.
+Returns the updated order, gorm.ErrRecordNotFound for an unknown order,
+ErrInvalidStatusTransition if the order cannot move to the status,
+or a ValidationError for an unknown status or an update not later than the current state.
+Updates of the same order are serialized, so of concurrent transitions from the same state only the first succeeds.
+*/
+func (osi *orderServiceImpl) UpdateOrderStatus(orderID string, update *models.OrderStatusUpdate) (*models.HistoryOrder, error) {
+	unlock := osi.orderLocks.lock(orderID)
+	defer unlock()
+
+	order, err := osi.repo.FindHistoryOrder(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedAt := update.UpdatedAt
+	if updatedAt.IsZero() {
+		updatedAt = time.Now().UTC()
+	}
+
+	if err := validateStatusUpdate(order, update, updatedAt); err != nil {
+		return nil, err
+	}
+
+	newOrder := *order
+	newOrder.Status = update.Status
+	newOrder.UpdatedAt = updatedAt
+
+	err = osi.orders.save([]*models.HistoryOrder{&newOrder}, func() error {
+		return osi.repo.SaveOrderHistory(newOrder)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newOrder, nil
+}
+
+// GetPairPrecision retrieves the precision of a trading pair on an exchange.
+func (osi *orderServiceImpl) GetPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	return osi.repo.FindPairPrecision(exchangeName, pair)
+}
+
+/*
+SavePairPrecision saves the precision of a trading pair on an exchange.
+Stamps the precision with the current time if UpdatedAt is not set,
+the new precision applies to validation immediately.
+*/
+func (osi *orderServiceImpl) SavePairPrecision(precision *models.PairPrecision) error {
+	newPrecision := *precision
+	if newPrecision.UpdatedAt.IsZero() {
+		newPrecision.UpdatedAt = time.Now().UTC()
+	}
+
+	if err := osi.repo.SavePairPrecision(newPrecision); err != nil {
+		return err
+	}
+
+	osi.precisions.set(newPrecision.Exchange, newPrecision.Pair, &newPrecision)
+	return nil
 }
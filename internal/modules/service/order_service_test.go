@@ -2,12 +2,20 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/repository"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // MockOrderRepository is a mock implementation of the OrderRepository interface.
@@ -23,21 +31,189 @@ func (m *MockOrderRepository) FindOrder(exchangeName, pair string) ([]*models.Or
 	return args.Get(0).([]*models.OrderBook), args.Error(1)
 }
 
+func (m *MockOrderRepository) FindLatestOrder(exchangeName, pair string, asOf time.Time) (*models.OrderBook, error) {
+	args := m.Called(exchangeName, pair, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OrderBook), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindLatestOrdersByPair(pair string) ([]*models.OrderBook, error) {
+	args := m.Called(pair)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.OrderBook), args.Error(1)
+}
+
 func (m *MockOrderRepository) SaveOrder(order models.OrderBook) error {
 	args := m.Called(order)
 	return args.Error(0)
 }
 
-func (m *MockOrderRepository) FindOrderHistory(client *models.Client) ([]*models.HistoryOrder, error) {
-	args := m.Called(client)
+func (m *MockOrderRepository) FindOrderHistory(filter *models.OrderHistoryFilter, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error) {
+	args := m.Called(filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.HistoryOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindOrdersSavedAfter(filter *models.OrderStreamFilter, after *models.OrderEventPosition, limit int) ([]*models.HistoryOrder, error) {
+	args := m.Called(filter, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.HistoryOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) StreamOrderHistory(filter *models.OrderHistoryFilter, fn func(*models.HistoryOrder) error) error {
+	args := m.Called(filter, fn)
+	if orders, ok := args.Get(0).([]*models.HistoryOrder); ok {
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockOrderRepository) StreamOrders(exchangeName, pair string, from, to time.Time, fn func(*models.OrderBook) error) error {
+	args := m.Called(exchangeName, pair, from, to, fn)
+	if orders, ok := args.Get(0).([]*models.OrderBook); ok {
+		for _, order := range orders {
+			if err := fn(order); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockOrderRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	args := m.Called(orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.HistoryOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error) {
+	args := m.Called(orderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.HistoryOrder), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindAlgorithms() ([]*models.Algorithm, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Algorithm), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindAlgorithmVolume(name string, from, to time.Time) (*models.AlgorithmVolume, error) {
+	args := m.Called(name, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AlgorithmVolume), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindAlgorithmExecutions(name string, from, to time.Time, after *models.OrderHistoryCursor, limit int) ([]*models.HistoryOrder, error) {
+	args := m.Called(name, from, to, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).([]*models.HistoryOrder), args.Error(1)
 }
 
+func (m *MockOrderRepository) FindTradeCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.Candle, error) {
+	args := m.Called(exchangeName, pair, interval, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Candle), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindMidCandles(exchangeName, pair string, interval time.Duration, from, to time.Time) ([]*models.MidCandle, error) {
+	args := m.Called(exchangeName, pair, interval, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.MidCandle), args.Error(1)
+}
+
+func (m *MockOrderRepository) FindFeeReport(filter *models.FeeReportFilter) ([]*models.FeeReportRow, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FeeReportRow), args.Error(1)
+}
+
+func (m *MockOrderRepository) SaveOrders(orders []models.OrderBook) error {
+	args := m.Called(orders)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) SaveOrderHistories(orders []models.HistoryOrder) error {
+	args := m.Called(orders)
+	return args.Error(0)
+}
+
 func (m *MockOrderRepository) SaveOrderHistory(order models.HistoryOrder) error {
 	args := m.Called(order)
 	return args.Error(0)
 }
 
+func (m *MockOrderRepository) FindFills(orderIDs []string) ([]*models.Fill, error) {
+	args := m.Called(orderIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Fill), args.Error(1)
+}
+
+func (m *MockOrderRepository) SaveFills(fills []models.Fill) error {
+	args := m.Called(fills)
+	return args.Error(0)
+}
+
+func (m *MockOrderRepository) FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	args := m.Called(exchangeName, pair)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PairPrecision), args.Error(1)
+}
+
+func (m *MockOrderRepository) SavePairPrecision(precision models.PairPrecision) error {
+	args := m.Called(precision)
+	return args.Error(0)
+}
+
+func tuple(price, qty string) models.Tuple {
+	return models.Tuple{decimal.RequireFromString(price), decimal.RequireFromString(qty)}
+}
+
+func level(price, qty string) *models.DepthOrder {
+	return &models.DepthOrder{Price: decimal.RequireFromString(price), BaseQty: decimal.RequireFromString(qty)}
+}
+
+// levelStrings formats levels as "price:qty" so they compare regardless of decimal exponents.
+func levelStrings(levels []*models.DepthOrder) []string {
+	result := make([]string, len(levels))
+	for i, level := range levels {
+		result[i] = fmt.Sprintf("%s:%s", level.Price, level.BaseQty)
+	}
+	return result
+}
+
 func TestGetOrderBook(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 	service := NewOrderService(mockRepo)
@@ -53,7 +229,7 @@ func TestGetOrderBook(t *testing.T) {
 
 	mockRepo.On("FindOrder", exchangeName, pair).Return(order, nil)
 
-	result, err := service.GetOrderBook(exchangeName, pair)
+	result, err := service.GetOrderBook(exchangeName, pair, 0, decimal.Zero)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, exchangeName, result[0].Exchange)
@@ -62,6 +238,38 @@ func TestGetOrderBook(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestGetOrderBook_Aggregated(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	order := []*models.OrderBook{
+		{
+			Exchange: "test_exchange",
+			Pair:     "BTC/USD",
+			Asks:     models.Tuples{tuple("100.1", "1"), tuple("100.3", "2"), tuple("100.6", "3"), tuple("101.2", "4")},
+			Bids:     models.Tuples{tuple("99.9", "1"), tuple("99.6", "2"), tuple("99.5", "3"), tuple("98.7", "4")},
+		},
+	}
+
+	mockRepo.On("FindOrder", "test_exchange", "BTC/USD").Return(order, nil)
+
+	result, err := service.GetOrderBook("test_exchange", "BTC/USD", 2, decimal.RequireFromString("0.5"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"100.5:3", "101:3"}, levelStrings(result[0].Asks))
+	assert.Equal(t, []string{"99.5:6", "98.5:4"}, levelStrings(result[0].Bids))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestAggregateLevels(t *testing.T) {
+	levels := []*models.DepthOrder{level("0.3", "1"), level("0.1", "1"), level("0.25", "2")}
+
+	assert.Equal(t, []string{"0.1:1", "0.3:3"},
+		levelStrings(AggregateLevels(levels, false, 0, decimal.RequireFromString("0.1"))))
+	assert.Equal(t, []string{"0.1:1", "0.25:2"},
+		levelStrings(AggregateLevels(levels, false, 2, decimal.Zero)))
+}
+
 func TestGetOrderBook_Error(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 	service := NewOrderService(mockRepo)
@@ -71,46 +279,205 @@ func TestGetOrderBook_Error(t *testing.T) {
 
 	mockRepo.On("FindOrder", exchangeName, pair).Return(nil, errors.New("order not found"))
 
-	result, err := service.GetOrderBook(exchangeName, pair)
+	result, err := service.GetOrderBook(exchangeName, pair, 0, decimal.Zero)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestSaveOrderBook(t *testing.T) {
+func TestGetLatestOrderBook(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 	service := NewOrderService(mockRepo)
 
-	id := int64(1)
-	exchangeName := "test_exchange"
-	pair := "BTC/USD"
-	asks := []*models.DepthOrder{}
-	bids := []*models.DepthOrder{}
+	asOf := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	order := &models.OrderBook{
+		Exchange:     "test_exchange",
+		Pair:         "BTC/USD",
+		Asks:         models.Tuples{tuple("101", "1")},
+		ExchangeTime: asOf.Add(-time.Second),
+		Sequence:     7,
+	}
+
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", asOf).Return(order, nil)
+
+	result, err := service.GetLatestOrderBook("test_exchange", "BTC/USD", asOf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), result.Sequence)
+	assert.Equal(t, "101", result.Asks[0].Price.String())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderBookMetrics(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	order := &models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     models.Tuples{tuple("101", "1"), tuple("102", "1")},
+		Bids:     models.Tuples{tuple("99", "3")},
+	}
+
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(order, nil)
+
+	result, err := service.GetOrderBookMetrics("test_exchange", "BTC/USD", time.Time{}, 5, []float64{100})
+	assert.NoError(t, err)
+	assert.Equal(t, "100", result.Mid.String())
+	assert.Equal(t, "2", result.Spread.String())
+	assert.InDelta(t, 0.2, result.Imbalance, 1e-9)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEstimateMarketImpact(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	order := &models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     models.Tuples{tuple("101", "1"), tuple("103", "1")},
+		Bids:     models.Tuples{tuple("99", "1")},
+	}
+
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(order, nil)
+
+	result, err := service.EstimateMarketImpact("test_exchange", "BTC/USD", "buy", decimal.NewFromInt(2))
+	assert.NoError(t, err)
+	assert.Equal(t, "102", result.VwapPrice.String())
+	assert.Equal(t, "103", result.WorstPrice.String())
+	assert.False(t, result.InsufficientLiquidity)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetConsolidatedOrderBook(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	now := time.Now()
+	orders := []*models.OrderBook{
+		{Exchange: "exchange_a", Pair: "BTC/USD", Asks: models.Tuples{tuple("101", "1")}, Bids: models.Tuples{tuple("100", "1")}, ExchangeTime: now},
+		{Exchange: "exchange_b", Pair: "BTC/USD", Asks: models.Tuples{tuple("101", "2")}, Bids: models.Tuples{tuple("99", "1")}, ExchangeTime: now.Add(-time.Second)},
+		// A stale venue quoting through the others is left out instead of showing as arbitrage
+		{Exchange: "exchange_c", Pair: "BTC/USD", Asks: models.Tuples{tuple("90", "5")}, ExchangeTime: now.Add(-time.Hour)},
+	}
+
+	mockRepo.On("FindLatestOrdersByPair", "BTC/USD").Return(orders, nil)
+
+	result, err := service.GetConsolidatedOrderBook("BTC/USD", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"exchange_a", "exchange_b"}, result.Exchanges)
+	assert.Equal(t, "3", result.Asks[0].BaseQty.String())
+	assert.Len(t, result.Bids, 2)
+	assert.False(t, result.Crossed)
+	if assert.Len(t, result.Excluded, 1) {
+		assert.Equal(t, "exchange_c", result.Excluded[0].Exchange)
+	}
+
+	// A shorter maximum age also excludes the venue a second behind
+	result, err = service.GetConsolidatedOrderBook("BTC/USD", 500*time.Millisecond)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"exchange_a"}, result.Exchanges)
+	assert.Len(t, result.Excluded, 2)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBook(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
 
 	orderDTO := models.OrderBookDTO{
-		ID:       id,
-		Exchange: exchangeName,
-		Pair:     pair,
-		Asks:     asks,
-		Bids:     bids,
+		ID:           1,
+		Exchange:     "test_exchange",
+		Pair:         "BTC/USD",
+		Asks:         []*models.DepthOrder{},
+		Bids:         []*models.DepthOrder{},
+		ExchangeTime: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		ReceivedTime: time.Date(2024, 5, 1, 12, 0, 0, 5000, time.UTC),
+		Sequence:     42,
 	}
 
 	order := orderDTO.ToOrderBook()
 
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
 	mockRepo.On("SaveOrder", order).Return(nil)
 
-	err := service.SaveOrderBook(id, exchangeName, pair, asks, bids)
+	err := service.SaveOrderBook(&orderDTO)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBook_DefaultReceivedTime(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	orderDTO := models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Sequence: 42,
+	}
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrder", mock.MatchedBy(func(order models.OrderBook) bool {
+		return !order.ReceivedTime.IsZero() && order.Sequence == orderDTO.Sequence
+	})).Return(nil)
+
+	err := service.SaveOrderBook(&orderDTO)
 	assert.NoError(t, err)
+	assert.True(t, orderDTO.ReceivedTime.IsZero())
 
 	mockRepo.AssertExpectations(t)
 }
 
+func TestSaveOrderBook_Invalid(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	orderDTO := models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks: []*models.DepthOrder{
+			level("101", "1"),
+			level("100.5", "1"),
+			level("100.5", "0"),
+		},
+		Bids: []*models.DepthOrder{
+			level("102", "1"),
+			level("-1", "1"),
+		},
+	}
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+
+	err := service.SaveOrderBook(&orderDTO)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	fields := make([]string, len(validationErr.Violations))
+	for i, v := range validationErr.Violations {
+		fields[i] = v.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"asks[1].price",
+		"asks[2].baseQty",
+		"asks[2].price",
+		"bids[1].price",
+		"bids[0].price",
+	}, fields)
+
+	mockRepo.AssertNotCalled(t, "SaveOrder", mock.Anything)
+}
+
 func TestGetOrderHistory(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 	service := NewOrderService(mockRepo)
 
-	client := &models.Client{
+	filter := &models.OrderHistoryFilter{
 		ClientName:   "test_client",
 		ExchangeName: "test_exchange",
 		Label:        "test_label",
@@ -119,47 +486,1512 @@ func TestGetOrderHistory(t *testing.T) {
 
 	orderHistory := []*models.HistoryOrder{
 		{
-			ClientName:   client.ClientName,
-			ExchangeName: client.ExchangeName,
-			Label:        client.Label,
-			Pair:         client.Pair,
+			OrderID:      "order-1",
+			ClientName:   filter.ClientName,
+			ExchangeName: filter.ExchangeName,
+			Label:        filter.Label,
+			Pair:         filter.Pair,
 		},
 	}
 
-	mockRepo.On("FindOrderHistory", client).Return(orderHistory, nil)
+	expectedFilter := *filter
+	expectedFilter.Sort = models.SortAsc
+	mockRepo.On("FindOrderHistory", &expectedFilter, (*models.OrderHistoryCursor)(nil), DefaultHistoryLimit+1).Return(orderHistory, nil)
+	mockRepo.On("FindFills", []string{"order-1"}).Return([]*models.Fill{}, nil)
 
-	result, err := service.GetOrderHistory(client)
+	result, err := service.GetOrderHistory(filter)
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.Equal(t, orderHistory, result)
+	assert.Equal(t, orderHistory, result.Orders)
+	assert.Empty(t, result.NextCursor)
+	assert.Equal(t, 0, result.Orders[0].Fills.FillCount)
 
 	mockRepo.AssertExpectations(t)
 }
 
-func TestSaveOrder(t *testing.T) {
+func TestGetOrderHistory_Fills(t *testing.T) {
 	mockRepo := new(MockOrderRepository)
 	service := NewOrderService(mockRepo)
 
-	client := &models.Client{
-		ClientName:   "test_client",
-		ExchangeName: "test_exchange",
-		Label:        "test_label",
-		Pair:         "BTC/USD",
+	filter := &models.OrderHistoryFilter{ClientName: "test_client"}
+	orderHistory := []*models.HistoryOrder{{OrderID: "order-1"}, {OrderID: "order-2"}}
+
+	executedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fills := []*models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.RequireFromString("1"), Price: decimal.RequireFromString("100"),
+			Fee: decimal.RequireFromString("0.1"), FeeAsset: "USD", ExecutedAt: executedAt},
+		{FillID: "fill-2", OrderID: "order-1", BaseQty: decimal.RequireFromString("3"), Price: decimal.RequireFromString("104"),
+			Fee: decimal.RequireFromString("0.2"), FeeAsset: "USD", ExecutedAt: executedAt.Add(time.Second)},
+		{FillID: "fill-3", OrderID: "order-1", BaseQty: decimal.RequireFromString("1"), Price: decimal.RequireFromString("105"),
+			Fee: decimal.RequireFromString("0.001"), FeeAsset: "BNB", ExecutedAt: executedAt.Add(2 * time.Second)},
 	}
 
-	order := &models.HistoryOrder{}
+	mockRepo.On("FindOrderHistory", mock.Anything, mock.Anything, mock.Anything).Return(orderHistory, nil)
+	mockRepo.On("FindFills", []string{"order-1", "order-2"}).Return(fills, nil)
 
-	expectedOrder := models.HistoryOrder{
-		ClientName:   client.ClientName,
-		ExchangeName: client.ExchangeName,
-		Label:        client.Label,
-		Pair:         client.Pair,
+	page, err := service.GetOrderHistory(filter)
+	assert.NoError(t, err)
+	result := page.Orders
+
+	summary := result[0].Fills
+	assert.Equal(t, 3, summary.FillCount)
+	assert.Equal(t, "5", summary.FilledQty.String())
+	assert.Equal(t, "517", summary.QuoteQty.String())
+	assert.Equal(t, "103.4", summary.AvgFillPrice.String())
+	assert.Equal(t, "0.3", summary.TotalFees["USD"].String())
+	assert.Equal(t, "0.001", summary.TotalFees["BNB"].String())
+	assert.Equal(t, executedAt.Add(2*time.Second), summary.LastFillAt)
+
+	assert.Equal(t, 0, result[1].Fills.FillCount)
+	assert.True(t, result[1].Fills.AvgFillPrice.IsZero())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderHistory_Pagination(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	firstPage := []*models.HistoryOrder{
+		{OrderID: "order-3", TimePlaced: placedAt.Add(2 * time.Minute)},
+		{OrderID: "order-2", TimePlaced: placedAt.Add(time.Minute)},
+		{OrderID: "order-1", TimePlaced: placedAt},
+	}
+	secondPage := []*models.HistoryOrder{firstPage[2]}
+
+	filter := &models.OrderHistoryFilter{ClientName: "test_client", Sort: models.SortDesc, Limit: 2}
+	mockRepo.On("FindOrderHistory", filter, (*models.OrderHistoryCursor)(nil), 3).Return(firstPage, nil).Once()
+	mockRepo.On("FindFills", mock.Anything).Return([]*models.Fill{}, nil)
+
+	page, err := service.GetOrderHistory(filter)
+	assert.NoError(t, err)
+	assert.Len(t, page.Orders, 2)
+	assert.Equal(t, "order-2", page.Orders[1].OrderID)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// The same page always yields the same cursor
+	mockRepo.On("FindOrderHistory", filter, (*models.OrderHistoryCursor)(nil), 3).Return(firstPage, nil).Once()
+	again, err := service.GetOrderHistory(filter)
+	assert.NoError(t, err)
+	assert.Equal(t, page.NextCursor, again.NextCursor)
+
+	next := *filter
+	next.Cursor = page.NextCursor
+	cursor := &models.OrderHistoryCursor{TimePlaced: placedAt.Add(time.Minute), OrderID: "order-2", Sort: models.SortDesc}
+	mockRepo.On("FindOrderHistory", &next, cursor, 3).Return(secondPage, nil).Once()
+
+	page, err = service.GetOrderHistory(&next)
+	assert.NoError(t, err)
+	assert.Len(t, page.Orders, 1)
+	assert.Empty(t, page.NextCursor)
+
+	// A cursor issued for descending order is rejected for ascending order
+	next.Sort = models.SortAsc
+	_, err = service.GetOrderHistory(&next)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = service.GetOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client", Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetOrderHistory_PastLastPage(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	cursor := encodeCursor(&models.HistoryOrder{OrderID: "order-1"}, models.SortAsc)
+	mockRepo.On("FindOrderHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	page, err := service.GetOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client", Cursor: cursor})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Orders)
+
+	_, err = service.GetOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestSaveFills(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	executedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fills := []*models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), Liquidity: models.LiquidityMaker, ExecutedAt: executedAt},
+		{FillID: "fill-2", OrderID: "order-1", BaseQty: decimal.NewFromInt(2), Price: decimal.NewFromInt(101), Liquidity: models.LiquidityTaker},
+	}
+
+	mockRepo.On("FindHistoryOrders", []string{"order-1"}).Return([]*models.HistoryOrder{{OrderID: "order-1", ExchangeName: "test_exchange", Pair: "BTC/USD"}}, nil).Once()
+	mockRepo.On("FindFills", []string{"order-1"}).Return([]*models.Fill{}, nil)
+	mockRepo.On("SaveFills", mock.MatchedBy(func(saved []models.Fill) bool {
+		return len(saved) == 2 && saved[0].ExecutedAt.Equal(executedAt) && !saved[1].ExecutedAt.IsZero() &&
+			saved[0].ExchangeName == "test_exchange" && saved[1].Pair == "BTC/USD"
+	})).Return(nil)
+
+	err := service.SaveFills(fills)
+	assert.NoError(t, err)
+	assert.True(t, fills[1].ExecutedAt.IsZero())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveFills_Stored(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	fills := []*models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100)},
+		{FillID: "fill-2", OrderID: "order-1", BaseQty: decimal.NewFromInt(2), Price: decimal.NewFromInt(101)},
+		{FillID: "fill-2", OrderID: "order-1", BaseQty: decimal.NewFromInt(2), Price: decimal.NewFromInt(101)},
 	}
 
-	mockRepo.On("SaveOrderHistory", expectedOrder).Return(nil)
+	mockRepo.On("FindHistoryOrders", []string{"order-1"}).Return([]*models.HistoryOrder{{OrderID: "order-1"}}, nil)
+	mockRepo.On("FindFills", []string{"order-1"}).Return([]*models.Fill{{FillID: "fill-1", OrderID: "order-1"}}, nil)
+	mockRepo.On("SaveFills", mock.MatchedBy(func(saved []models.Fill) bool {
+		return len(saved) == 1 && saved[0].FillID == "fill-2"
+	})).Return(nil).Once()
+
+	assert.NoError(t, service.SaveFills(fills))
+	assert.NoError(t, service.SaveFills(fills[:1]))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveFills_Invalid(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	fills := []*models.Fill{
+		{OrderID: "order-1", BaseQty: decimal.Zero, Price: decimal.NewFromInt(100), Fee: decimal.NewFromInt(-1)},
+		{FillID: "fill-2", OrderID: "missing", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), Liquidity: "BOTH"},
+		nil,
+	}
+
+	mockRepo.On("FindHistoryOrders", []string{"missing", "order-1"}).Return([]*models.HistoryOrder{{OrderID: "order-1"}}, nil).Once()
+
+	err := service.SaveFills(fills)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	fields := make([]string, len(validationErr.Violations))
+	for i, v := range validationErr.Violations {
+		fields[i] = v.Field
+	}
+	assert.ElementsMatch(t, []string{
+		"fills[0].fillId",
+		"fills[0].baseQty",
+		"fills[0].fee",
+		"fills[1].liquidity",
+		"fills[1].orderId",
+		"fills[2]",
+	}, fields)
+
+	mockRepo.AssertNotCalled(t, "SaveFills", mock.Anything)
+}
+
+func TestSaveOrder(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	client := &models.Client{
+		ClientName:   "test_client",
+		ExchangeName: "test_exchange",
+		Label:        "test_label",
+		Pair:         "BTC/USD",
+	}
+
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	order := &models.HistoryOrder{OrderID: "order-1", UpdatedAt: updatedAt}
+
+	expectedOrder := models.HistoryOrder{
+		OrderID:      "order-1",
+		ClientName:   client.ClientName,
+		ExchangeName: client.ExchangeName,
+		Label:        client.Label,
+		Pair:         client.Pair,
+		Status:       models.OrderStatusNew,
+		UpdatedAt:    updatedAt,
+	}
+
+	mockRepo.On("FindHistoryOrder", "order-1").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrderHistory", mock.MatchedBy(func(order models.HistoryOrder) bool {
+		return !order.IngestedAt.IsZero() && assert.ObjectsAreEqual(expectedOrder, withoutIngestTime(order))
+	})).Return(nil)
+
+	saved, err := service.SaveOrder(client, order)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedOrder, withoutIngestTime(*saved))
+
+	mockRepo.AssertExpectations(t)
+}
+
+// withoutIngestTime clears the ingest time the service assigns to saved orders.
+func withoutIngestTime(order models.HistoryOrder) models.HistoryOrder {
+	order.IngestedAt = time.Time{}
+	return order
+}
+
+func TestSaveOrder_GeneratedID(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrderHistory", mock.MatchedBy(func(order models.HistoryOrder) bool {
+		return order.OrderID != "" && !order.UpdatedAt.IsZero()
+	})).Return(nil).Twice()
+
+	first, err := service.SaveOrder(client, &models.HistoryOrder{ClientOrderID: "client-1"})
+	assert.NoError(t, err)
+	second, err := service.SaveOrder(client, &models.HistoryOrder{ClientOrderID: "client-2"})
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, first.OrderID, second.OrderID)
+	assert.Equal(t, "client-1", first.ClientOrderID)
+
+	_, err = service.SaveOrder(client, &models.HistoryOrder{Status: "DONE"})
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrder_ExistingID(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	filled := &models.HistoryOrder{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusFilled}
+	mockRepo.On("FindHistoryOrder", "order-1").Return(filled, nil)
+	mockRepo.On("FindHistoryOrder", "order-2").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindHistoryOrder", "order-3").Return(nil, errors.New("connection lost"))
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrderHistory", mock.MatchedBy(func(order models.HistoryOrder) bool { return order.OrderID == "order-2" })).Return(nil).Once()
+
+	// A filled order cannot be sent back to NEW by saving it again
+	_, err := service.SaveOrder(client, &models.HistoryOrder{OrderID: "order-1", Status: models.OrderStatusNew})
+	var validationErr *ValidationError
+	if assert.ErrorAs(t, err, &validationErr) {
+		assert.Equal(t, "orderId", validationErr.Violations[0].Field)
+	}
+
+	saved, err := service.SaveOrder(client, &models.HistoryOrder{OrderID: "order-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, "order-2", saved.OrderID)
+
+	_, err = service.SaveOrder(client, &models.HistoryOrder{OrderID: "order-3"})
+	assert.EqualError(t, err, "connection lost")
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNumberOfCalls(t, "SaveOrderHistory", 1)
+}
+
+func TestUpdateOrderStatus(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	current := &models.HistoryOrder{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusNew, UpdatedAt: placedAt}
+	mockRepo.On("FindHistoryOrder", "order-1").Return(current, nil)
+
+	expectedOrder := *current
+	expectedOrder.Status = models.OrderStatusPartiallyFilled
+	expectedOrder.UpdatedAt = placedAt.Add(time.Minute)
+	mockRepo.On("SaveOrderHistory", mock.MatchedBy(func(order models.HistoryOrder) bool {
+		return !order.IngestedAt.IsZero() && assert.ObjectsAreEqual(expectedOrder, withoutIngestTime(order))
+	})).Return(nil)
+
+	updated, err := service.UpdateOrderStatus("order-1", &models.OrderStatusUpdate{
+		Status:    models.OrderStatusPartiallyFilled,
+		UpdatedAt: placedAt.Add(time.Minute),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedOrder, withoutIngestTime(*updated))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateOrderStatus_Invalid(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("FindHistoryOrder", "filled").Return(&models.HistoryOrder{OrderID: "filled", Status: models.OrderStatusFilled, UpdatedAt: placedAt}, nil)
+	mockRepo.On("FindHistoryOrder", "new").Return(&models.HistoryOrder{OrderID: "new", Status: models.OrderStatusNew, UpdatedAt: placedAt}, nil)
+	mockRepo.On("FindHistoryOrder", "missing").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.UpdateOrderStatus("filled", &models.OrderStatusUpdate{Status: models.OrderStatusCancelled})
+	assert.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	var validationErr *ValidationError
+	_, err = service.UpdateOrderStatus("new", &models.OrderStatusUpdate{Status: "DONE"})
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = service.UpdateOrderStatus("new", &models.OrderStatusUpdate{Status: models.OrderStatusFilled, UpdatedAt: placedAt.Add(-time.Second)})
+	assert.ErrorAs(t, err, &validationErr)
+
+	// An update at the time of the current state could not be told apart from it
+	_, err = service.UpdateOrderStatus("new", &models.OrderStatusUpdate{Status: models.OrderStatusFilled, UpdatedAt: placedAt})
+	assert.ErrorAs(t, err, &validationErr)
+
+	_, err = service.UpdateOrderStatus("missing", &models.OrderStatusUpdate{Status: models.OrderStatusFilled})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	mockRepo.AssertNotCalled(t, "SaveOrderHistory", mock.Anything)
+}
+
+// historyRepository keeps the latest saved state of every order, reads return it with a delay so that concurrent calls overlap.
+type historyRepository struct {
+	repository.OrderRepository
+
+	mu     sync.Mutex
+	orders map[string]models.HistoryOrder
+	fills  []models.Fill
+}
+
+func (r *historyRepository) FindPairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *historyRepository) FindHistoryOrder(orderID string) (*models.HistoryOrder, error) {
+	r.mu.Lock()
+	order, ok := r.orders[orderID]
+	r.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &order, nil
+}
+
+func (r *historyRepository) SaveOrderHistory(order models.HistoryOrder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.orders[order.OrderID] = order
+	return nil
+}
+
+func (r *historyRepository) FindHistoryOrders(orderIDs []string) ([]*models.HistoryOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []*models.HistoryOrder
+	for _, orderID := range orderIDs {
+		if order, ok := r.orders[orderID]; ok {
+			orders = append(orders, &order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *historyRepository) FindFills(orderIDs []string) ([]*models.Fill, error) {
+	r.mu.Lock()
+	var fills []*models.Fill
+	for _, fill := range r.fills {
+		if slices.Contains(orderIDs, fill.OrderID) {
+			fills = append(fills, &fill)
+		}
+	}
+	r.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	return fills, nil
+}
+
+func (r *historyRepository) SaveFills(fills []models.Fill) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fills = append(r.fills, fills...)
+	return nil
+}
+
+func TestSaveOrder_Concurrent(t *testing.T) {
+	repo := &historyRepository{orders: make(map[string]models.HistoryOrder)}
+	service := NewOrderService(repo)
+	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+
+	// Of concurrent saves of the same ID, and transitions from the same state, only one succeeds
+	var wg sync.WaitGroup
+	var saved, updated atomic.Int32
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.SaveOrder(client, &models.HistoryOrder{OrderID: "order-1", Type: "limit"}); err == nil {
+				saved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), saved.Load())
+
+	for _, status := range []string{models.OrderStatusFilled, models.OrderStatusCancelled, models.OrderStatusFilled, models.OrderStatusCancelled} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.UpdateOrderStatus("order-1", &models.OrderStatusUpdate{Status: status}); err == nil {
+				updated.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), updated.Load())
+
+	assert.Empty(t, service.(*orderServiceImpl).orderLocks.locks)
+}
+
+func TestSaveFills_Concurrent(t *testing.T) {
+	repo := &historyRepository{orders: map[string]models.HistoryOrder{"order-1": {OrderID: "order-1"}}}
+	service := NewOrderService(repo)
+
+	// Concurrent batches with the same fill save it once
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, service.SaveFills([]*models.Fill{{
+				FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100),
+			}}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, repo.fills, 1)
+	assert.Empty(t, service.(*orderServiceImpl).orderLocks.locks)
+}
+
+func TestSaveOrderBookDelta(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	seed := &models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     models.Tuples{tuple("101", "1"), tuple("102", "2")},
+		Bids:     models.Tuples{tuple("100", "1")},
+		Sequence: 10,
+	}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(seed, nil).Once()
+
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Sequence: 11,
+		Asks:     []*models.DepthOrder{level("101", "0"), level("103", "3")},
+		Bids:     []*models.DepthOrder{level("99.5", "4")},
+	})
+	assert.NoError(t, err)
+
+	book := service.(*orderServiceImpl).books.get("test_exchange", "BTC/USD").snapshot()
+	assert.Equal(t, int64(11), book.Sequence)
+	assert.Equal(t, []string{"102:2", "103:3"}, levelStrings(book.Asks))
+	assert.Equal(t, []string{"100:1", "99.5:4"}, levelStrings(book.Bids))
+
+	// Already applied sequence numbers are ignored
+	err = service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBookDelta_SequenceGap(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	seed := &models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 10}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(seed, nil).Once()
+
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 12})
+	assert.ErrorIs(t, err, ErrSequenceGap)
+
+	err = service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 13})
+	assert.ErrorIs(t, err, ErrOrderBookOutOfSync)
+
+	// A new snapshot brings the book back in sync
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Once()
+	err = service.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 20})
+	assert.NoError(t, err)
+
+	err = service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 21})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBookDelta_StaleSnapshot(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	seed := &models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", Asks: models.Tuples{tuple("101", "1")}, Sequence: 10}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(seed, nil).Once()
+
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11, Bids: []*models.DepthOrder{level("100", "2")}})
+	assert.NoError(t, err)
+
+	subscription := service.SubscribeOrderBooks(8)
+	defer subscription.Close()
+	assert.NoError(t, service.JoinOrderBook(subscription, "test_exchange", "BTC/USD"))
+	<-subscription.Updates()
+
+	// A snapshot older than the applied deltas neither rolls the book back nor is published
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Once()
+	err = service.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11})
+	assert.NoError(t, err)
+	assert.Len(t, subscription.Updates(), 0)
+
+	book := service.(*orderServiceImpl).books.get("test_exchange", "BTC/USD").snapshot()
+	assert.Equal(t, int64(11), book.Sequence)
+	assert.Equal(t, []string{"100:2"}, levelStrings(book.Bids))
+
+	err = service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 12})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), (<-subscription.Updates()).Delta.Sequence)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBookDelta_PersistsSnapshot(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	seed := &models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 0}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(seed, nil).Once()
+	mockRepo.On("SaveOrder", mock.MatchedBy(func(order models.OrderBook) bool {
+		return order.Sequence == snapshotEveryDeltas && len(order.Asks) == snapshotEveryDeltas
+	})).Return(nil).Once()
+
+	for i := int64(1); i <= snapshotEveryDeltas; i++ {
+		err := service.SaveOrderBookDelta(&models.OrderBookDelta{
+			Exchange: "test_exchange",
+			Pair:     "BTC/USD",
+			Sequence: i,
+			Asks:     []*models.DepthOrder{{Price: decimal.NewFromInt(100 + i), BaseQty: decimal.NewFromInt(1)}},
+		})
+		assert.NoError(t, err)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBookDelta_NoSnapshot(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(nil, gorm.ErrRecordNotFound)
+
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 1})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBookDelta_Invalid(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+
+	seed := &models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     models.Tuples{tuple("101", "1")},
+		Bids:     models.Tuples{tuple("100", "1")},
+		Sequence: 10,
+	}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(seed, nil).Once()
+
+	tests := map[string]*models.OrderBookDelta{
+		"negative quantity": {Asks: []*models.DepthOrder{level("102", "-1")}},
+		"crossed book":      {Bids: []*models.DepthOrder{level("101.5", "1")}},
+		"negative price":    {Bids: []*models.DepthOrder{level("-1", "1")}},
+	}
+	for name, delta := range tests {
+		delta.Exchange = "test_exchange"
+		delta.Pair = "BTC/USD"
+		delta.Sequence = 11
+
+		err := service.SaveOrderBookDelta(delta)
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr, name)
+	}
+
+	// Rejected deltas leave the book unchanged and in sync
+	book := service.(*orderServiceImpl).books.get("test_exchange", "BTC/USD").snapshot()
+	assert.Equal(t, int64(10), book.Sequence)
+	assert.Equal(t, []string{"101:1"}, levelStrings(book.Asks))
+	assert.Equal(t, []string{"100:1"}, levelStrings(book.Bids))
+
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11, Asks: []*models.DepthOrder{level("101", "2")}})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPersistBooks(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo).(*orderServiceImpl)
+
+	stale := service.books.seed(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "BTC/USD"})
+	stale.pending = 1
+	stale.lastPersisted = time.Now().Add(-snapshotPeriod)
+
+	recent := service.books.seed(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "ETH/USD"})
+	recent.pending = 1
+
+	service.books.seed(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "SOL/USD"})
+
+	// Books of quiet feeds are persisted once their deltas are older than the snapshot period
+	mockRepo.On("SaveOrder", mock.MatchedBy(func(order models.OrderBook) bool { return order.Pair == "BTC/USD" })).Return(nil).Once()
+	assert.NoError(t, service.persistBooks(false))
+	assert.Equal(t, 0, stale.pending)
+	assert.Equal(t, 1, recent.pending)
+
+	// Closing persists every book with pending deltas
+	mockRepo.On("SaveOrder", mock.MatchedBy(func(order models.OrderBook) bool { return order.Pair == "ETH/USD" })).Return(nil).Once()
+	assert.NoError(t, service.Close())
+	assert.Equal(t, 0, recent.pending)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrderBook_Precision(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	precision := &models.PairPrecision{Exchange: "test_exchange", Pair: "BTC/USD", PriceScale: 2, QtyScale: 4}
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(precision, nil).Once()
+
+	orderDTO := models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     []*models.DepthOrder{level("101.125", "1")},
+		Bids:     []*models.DepthOrder{level("100.5", "0.00001")},
+	}
+
+	err := service.SaveOrderBook(&orderDTO)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	fields := make([]string, len(validationErr.Violations))
+	for i, v := range validationErr.Violations {
+		fields[i] = v.Field
+	}
+	assert.ElementsMatch(t, []string{"asks[0].price", "bids[0].baseQty"}, fields)
+
+	// The precision is cached, so a second save does not look it up again
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Once()
+	orderDTO.Asks = []*models.DepthOrder{level("101.10", "1")}
+	orderDTO.Bids = []*models.DepthOrder{level("100.5", "0.0001")}
+	err = service.SaveOrderBook(&orderDTO)
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrder_Precision(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	precision := &models.PairPrecision{Exchange: "test_exchange", Pair: "BTC/USD", PriceScale: 2, QtyScale: 4}
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(precision, nil)
+
+	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	order := &models.HistoryOrder{
+		BaseQty: decimal.RequireFromString("0.12345"),
+		Price:   decimal.RequireFromString("100.01"),
+	}
+
+	_, err := service.SaveOrder(client, order)
+
+	var validationErr *ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Violations, 1)
+	assert.Equal(t, "baseQty", validationErr.Violations[0].Field)
+
+	mockRepo.AssertNotCalled(t, "SaveOrderHistory", mock.Anything)
+}
+
+func TestGetClientPnL(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []*models.HistoryOrder{
+		{OrderID: "order-1", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "buy", BaseQty: decimal.NewFromInt(2),
+			Price: decimal.NewFromInt(100), CommissionQuoteQty: decimal.RequireFromString("0.5"), TimePlaced: placedAt,
+			Status: models.OrderStatusFilled},
+		{OrderID: "order-2", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "sell", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(110), TimePlaced: placedAt.Add(time.Minute), Status: models.OrderStatusCancelled},
+		{OrderID: "order-3", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "sell", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(110), CommissionQuoteQty: decimal.RequireFromString("0.2"), TimePlaced: placedAt.Add(2 * time.Minute),
+			Status: models.OrderStatusCancelled},
+		{OrderID: "order-4", ExchangeName: "test_exchange", Pair: "ETH/USD", Side: "buy", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(10), TimePlaced: placedAt, Status: models.OrderStatusFilled},
+		{OrderID: "order-5", ExchangeName: "test_exchange", Pair: "ETH/USD", Side: "sell", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(12), TimePlaced: placedAt.Add(time.Minute), Status: models.OrderStatusFilled},
+	}
+	// order-3 was cancelled after filling half, and is charged half of its commission
+	fills := []*models.Fill{
+		{FillID: "fill-1", OrderID: "order-3", BaseQty: decimal.RequireFromString("0.5"), Price: decimal.NewFromInt(120),
+			ExecutedAt: placedAt.Add(3 * time.Minute)},
+	}
+
+	filter := &models.OrderHistoryFilter{ClientName: "test_client"}
+	mockRepo.On("FindOrderHistory", mock.Anything, (*models.OrderHistoryCursor)(nil), MaxHistoryLimit).Return(orders, nil)
+	mockRepo.On("FindFills", []string{"order-1", "order-2", "order-3", "order-4", "order-5"}).Return(fills, nil)
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(&models.OrderBook{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     models.Tuples{tuple("131", "1")},
+		Bids:     models.Tuples{tuple("129", "1")},
+	}, nil)
+
+	result, err := service.GetClientPnL(filter, "fifo")
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+
+	btc := result[0]
+	assert.Equal(t, "BTC/USD", btc.Pair)
+	assert.Equal(t, 2, btc.Trades)
+	assert.Equal(t, "10", btc.RealizedPnL.String())
+	assert.Equal(t, "1.5", btc.Position.String())
+	assert.Equal(t, "100", btc.AvgEntryPrice.String())
+	assert.Equal(t, "130", btc.MarkPrice.String())
+	assert.Equal(t, "45", btc.UnrealizedPnL.String())
+	assert.Equal(t, "0.6", btc.Commission.String())
+	assert.Equal(t, "54.4", btc.NetPnL.String())
+
+	eth := result[1]
+	assert.Equal(t, "ETH/USD", eth.Pair)
+	assert.Equal(t, "2", eth.RealizedPnL.String())
+	assert.True(t, eth.Position.IsZero())
+	assert.Nil(t, eth.MarkPrice)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetClientPnL_UnfilledAndInvalidSide(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []*models.HistoryOrder{
+		{OrderID: "order-1", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "buy", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(100), TimePlaced: placedAt, Status: models.OrderStatusFilled},
+		{OrderID: "order-2", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "sell", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(150), TimePlaced: placedAt.Add(time.Minute), Status: models.OrderStatusNew},
+		{OrderID: "order-3", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "sell", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(150), TimePlaced: placedAt.Add(2 * time.Minute), Status: models.OrderStatusPartiallyFilled},
+		{OrderID: "order-4", ExchangeName: "test_exchange", Pair: "BTC/USD", Side: "hold", BaseQty: decimal.NewFromInt(1),
+			Price: decimal.NewFromInt(150), TimePlaced: placedAt.Add(3 * time.Minute), Status: models.OrderStatusFilled},
+	}
+
+	mockRepo.On("FindOrderHistory", mock.Anything, (*models.OrderHistoryCursor)(nil), MaxHistoryLimit).Return(orders, nil)
+	mockRepo.On("FindFills", []string{"order-1", "order-2", "order-3", "order-4"}).Return([]*models.Fill{}, nil)
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(nil, gorm.ErrRecordNotFound)
+
+	result, err := service.GetClientPnL(&models.OrderHistoryFilter{ClientName: "test_client"}, "fifo")
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	// the open and partially filled orders without fills have not sold anything
+	btc := result[0]
+	assert.Equal(t, 1, btc.Trades)
+	assert.Equal(t, "1", btc.Position.String())
+	assert.True(t, btc.RealizedPnL.IsZero())
+	assert.Equal(t, []string{"order-4"}, btc.SkippedOrders)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetClientPnL_NotFound(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	mockRepo.On("FindOrderHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.GetClientPnL(&models.OrderHistoryFilter{ClientName: "test_client"}, "fifo")
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetExecutionQuality(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	orders := []*models.HistoryOrder{
+		{OrderID: "order-1", AlgorithmNamePlaced: "twap", Side: "buy", BaseQty: decimal.NewFromInt(2), Price: decimal.NewFromInt(101),
+			HighestBuyPrc: decimal.NewFromInt(99), LowestSellPrc: decimal.NewFromInt(101), TimePlaced: placedAt},
+		{OrderID: "order-2", AlgorithmNamePlaced: "twap", Side: "sell", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100),
+			HighestBuyPrc: decimal.NewFromInt(99), LowestSellPrc: decimal.NewFromInt(101), TimePlaced: placedAt, Status: models.OrderStatusCancelled},
+	}
+	fills := []*models.Fill{
+		{FillID: "fill-1", OrderID: "order-1", BaseQty: decimal.NewFromInt(1), Price: decimal.RequireFromString("100.5"),
+			ExecutedAt: placedAt.Add(time.Second)},
+	}
+
+	mockRepo.On("FindOrderHistory", mock.Anything, (*models.OrderHistoryCursor)(nil), MaxHistoryLimit).Return(orders, nil)
+	mockRepo.On("FindFills", []string{"order-1", "order-2"}).Return(fills, nil)
+
+	report, err := service.GetExecutionQuality(&models.OrderHistoryFilter{ClientName: "test_client"})
+	assert.NoError(t, err)
+	assert.Len(t, report.Orders, 1)
+	assert.Equal(t, 1, report.Skipped)
+
+	order := report.Orders[0]
+	assert.Equal(t, "order-1", order.OrderID)
+	assert.Equal(t, "1", order.ExecutedQty.String())
+	assert.Equal(t, "100.5", order.ExecutionPrice.String())
+	assert.Equal(t, "100", order.ArrivalMid.String())
+	assert.InDelta(t, 50, order.SlippageBps, 1e-9)
+	assert.False(t, order.CrossedSpread)
+
+	assert.Len(t, report.Algorithms, 1)
+	assert.Equal(t, "twap", report.Algorithms[0].Algorithm)
+	assert.Equal(t, 1, report.Algorithms[0].Orders)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetExecutionQuality_NotFound(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	mockRepo.On("FindOrderHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.GetExecutionQuality(&models.OrderHistoryFilter{ClientName: "test_client"})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetAlgorithmStats(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	placedAt := from.Add(12 * time.Hour)
+	orders := []*models.HistoryOrder{
+		{OrderID: "order-1", ClientName: "client-a", AlgorithmNamePlaced: "twap", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			Side: "buy", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(100), CommissionQuoteQty: decimal.NewFromInt(1),
+			HighestBuyPrc: decimal.NewFromInt(99), LowestSellPrc: decimal.NewFromInt(101), TimePlaced: placedAt, Status: models.OrderStatusFilled},
+		{OrderID: "order-2", ClientName: "client-b", AlgorithmNamePlaced: "twap", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			Side: "sell", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(110),
+			HighestBuyPrc: decimal.NewFromInt(109), LowestSellPrc: decimal.NewFromInt(111), TimePlaced: placedAt, Status: models.OrderStatusFilled},
+		{OrderID: "order-3", ClientName: "client-a", AlgorithmNamePlaced: "twap", ExchangeName: "test_exchange", Pair: "BTC/USD",
+			Side: "sell", BaseQty: decimal.NewFromInt(1), Price: decimal.NewFromInt(120),
+			HighestBuyPrc: decimal.NewFromInt(119), LowestSellPrc: decimal.NewFromInt(121), TimePlaced: placedAt.Add(time.Minute), Status: models.OrderStatusFilled},
+	}
+
+	mockRepo.On("FindAlgorithmVolume", "twap", from, time.Time{}).Return(&models.AlgorithmVolume{
+		Orders: 3, BuyOrders: 1, SellOrders: 2, BaseVolume: decimal.NewFromInt(3), QuoteVolume: decimal.NewFromInt(330),
+		Commission: decimal.NewFromInt(1),
+	}, nil)
+	mockRepo.On("FindAlgorithmExecutions", "twap", from, time.Time{}, (*models.OrderHistoryCursor)(nil), MaxHistoryLimit).
+		Return(orders, nil)
+	mockRepo.On("FindFills", []string{"order-1", "order-2", "order-3"}).Return([]*models.Fill{}, nil)
+	// client-b is left short
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(&models.OrderBook{
+		Asks: models.Tuples{tuple("106", "1")},
+		Bids: models.Tuples{tuple("104", "1")},
+	}, nil)
+
+	stats, err := service.GetAlgorithmStats("twap", from, time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "twap", stats.Algorithm)
+	assert.Equal(t, from, *stats.From)
+	assert.Nil(t, stats.To)
+	assert.Equal(t, uint64(3), stats.Orders)
+	assert.Equal(t, 0.5, *stats.BuySellRatio)
+
+	// client-a bought at 100 and sold at 120, client-b sold at 110 marked at 105
+	assert.Equal(t, "20", stats.RealizedPnL.String())
+	assert.Equal(t, "5", stats.UnrealizedPnL.String())
+	assert.Equal(t, "24", stats.NetPnL.String())
+
+	assert.Equal(t, 3, stats.EvaluatedOrders)
+	assert.InDelta(t, 0, stats.SlippageBps, 1e-9)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetAlgorithmStats_NotFound(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	mockRepo.On("FindAlgorithmVolume", "twap", time.Time{}, time.Time{}).Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := service.GetAlgorithmStats("twap", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestGetCandles(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	trades := []*models.Candle{{Time: from, Open: decimal.NewFromInt(100), Close: decimal.NewFromInt(101), Trades: 2}}
+	mids := []*models.MidCandle{{Time: from, Open: decimal.NewFromInt(100), Snapshots: 5}}
+
+	mockRepo.On("FindTradeCandles", "test_exchange", "BTC/USD", 5*time.Minute, from, time.Time{}).Return(trades, nil)
+	mockRepo.On("FindMidCandles", "test_exchange", "BTC/USD", 5*time.Minute, from, time.Time{}).Return(mids, nil)
+
+	candles, err := service.GetCandles("test_exchange", "BTC/USD", "5m", from, time.Time{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "5m", candles.Interval)
+	assert.Equal(t, trades, candles.Trades)
+	assert.Equal(t, mids, candles.Mids)
+
+	candles, err = service.GetCandles("test_exchange", "BTC/USD", "5m", from, time.Time{}, false)
+	assert.NoError(t, err)
+	assert.Nil(t, candles.Mids)
+
+	mockRepo.AssertNumberOfCalls(t, "FindMidCandles", 1)
+}
+
+func TestGetFeeReport(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	rows := []*models.FeeReportRow{
+		{ClientName: "client-a", Orders: 2, Notional: decimal.NewFromInt(1000), Commission: decimal.NewFromInt(1)},
+		{ClientName: "client-b", Orders: 1, Notional: decimal.Zero, Commission: decimal.RequireFromString("0.5")},
+	}
+	mockRepo.On("FindFeeReport", &models.FeeReportFilter{ClientName: "", Period: models.FeePeriodMonth}).Return(rows, nil)
+
+	report, err := service.GetFeeReport(&models.FeeReportFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.FeePeriodMonth, report.Period)
+	assert.InDelta(t, 10, report.Rows[0].FeeRateBps, 1e-9)
+	assert.Equal(t, 0.0, report.Rows[1].FeeRateBps)
+	assert.Equal(t, uint64(3), report.Orders)
+	assert.Equal(t, "1000", report.Notional.String())
+	assert.Equal(t, "1.5", report.Commission.String())
+	assert.InDelta(t, 15, report.FeeRateBps, 1e-9)
+}
+
+func TestSaveOrderBooks(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	exchangeTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	valid := &models.OrderBookDTO{
+		Exchange:     "test_exchange",
+		Pair:         "BTC/USD",
+		Asks:         []*models.DepthOrder{level("101", "1")},
+		Bids:         []*models.DepthOrder{level("100", "1")},
+		ExchangeTime: exchangeTime,
+		ReceivedTime: exchangeTime,
+	}
+	crossed := &models.OrderBookDTO{
+		Exchange: "test_exchange",
+		Pair:     "BTC/USD",
+		Asks:     []*models.DepthOrder{level("100", "1")},
+		Bids:     []*models.DepthOrder{level("101", "1")},
+	}
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrders", []models.OrderBook{valid.ToOrderBook()}).Return(nil)
+
+	result, err := service.SaveOrderBooks([]*models.OrderBookDTO{valid, crossed, {Pair: "BTC/USD"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	assert.True(t, result.Items[0].Accepted)
+	assert.Equal(t, "bids[0].price", result.Items[1].Violations[0].Field)
+	assert.Equal(t, "exchange", result.Items[2].Violations[0].Field)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrders(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	client := models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	payloads := []*models.HistoryOrderPayload{
+		{Client: client, History: models.HistoryOrder{OrderID: "order-1", Type: "limit", Price: decimal.RequireFromString("100.5")}},
+		{Client: client, History: models.HistoryOrder{OrderID: "order-2", Type: "limit", Price: decimal.RequireFromString("100.555")}},
+		{Client: client, History: models.HistoryOrder{Type: "limit", Status: "DONE"}},
+		{Client: client},
+		{Client: client, History: models.HistoryOrder{OrderID: "order-1", Type: "limit"}},
+		{Client: client, History: models.HistoryOrder{OrderID: "filled", Type: "limit"}},
+	}
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(&models.PairPrecision{PriceScale: 2, QtyScale: 8}, nil)
+	mockRepo.On("FindHistoryOrders", []string{"order-1", "order-2", "filled"}).
+		Return([]*models.HistoryOrder{{OrderID: "filled", Status: models.OrderStatusFilled}}, nil).Once()
+	mockRepo.On("SaveOrderHistories", mock.MatchedBy(func(orders []models.HistoryOrder) bool {
+		return len(orders) == 1 && orders[0].OrderID == "order-1" && orders[0].ClientName == "test_client" &&
+			orders[0].Status == models.OrderStatusNew
+	})).Return(nil)
+
+	result, err := service.SaveOrders(payloads)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.Accepted)
+	assert.Equal(t, 5, result.Rejected)
+	assert.Equal(t, "order-1", result.Items[0].OrderID)
+	assert.Equal(t, "price", result.Items[1].Violations[0].Field)
+	assert.Equal(t, "status", result.Items[2].Violations[0].Field)
+	assert.Equal(t, "history.type", result.Items[3].Violations[0].Field)
+	assert.Equal(t, "history.orderId", result.Items[4].Violations[0].Field)
+	assert.Equal(t, "orderId", result.Items[5].Violations[0].Field)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSaveOrders_InsertError(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	client := models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Pair: "BTC/USD"}
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("SaveOrderHistories", mock.Anything).Return(gorm.ErrInvalidDB)
+
+	_, err := service.SaveOrders([]*models.HistoryOrderPayload{{Client: client, History: models.HistoryOrder{Type: "limit"}}})
+	assert.ErrorIs(t, err, gorm.ErrInvalidDB)
+}
+
+func TestSubscribeOrderBooks(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	stored := &models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", Asks: models.Tuples{tuple("101", "1")}, Sequence: 10}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(stored, nil).Once()
+	mockRepo.On("FindLatestOrder", "test_exchange", "ETH/USD", time.Time{}).Return(nil, gorm.ErrRecordNotFound).Once()
+
+	subscription := service.SubscribeOrderBooks(8)
+	defer subscription.Close()
+
+	assert.NoError(t, service.JoinOrderBook(subscription, "test_exchange", "BTC/USD"))
+	assert.NoError(t, service.JoinOrderBook(subscription, "test_exchange", "ETH/USD"))
+
+	// Joining starts with the stored snapshot, pairs without one start with the first update
+	update := <-subscription.Updates()
+	assert.Equal(t, models.OrderBookUpdateSnapshot, update.Type)
+	assert.Equal(t, int64(10), update.Book.Sequence)
+
+	// The delta is seeded from the stored snapshot
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(stored, nil).Once()
+	err := service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 11, Bids: []*models.DepthOrder{level("100", "2")}})
+	assert.NoError(t, err)
+
+	update = <-subscription.Updates()
+	assert.Equal(t, models.OrderBookUpdateDelta, update.Type)
+	assert.Equal(t, int64(11), update.Delta.Sequence)
+
+	mockRepo.On("FindPairPrecision", "test_exchange", "ETH/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Once()
+	err = service.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "ETH/USD", Sequence: 5})
+	assert.NoError(t, err)
+
+	update = <-subscription.Updates()
+	assert.Equal(t, models.OrderBookUpdateSnapshot, update.Type)
+	assert.Equal(t, "ETH/USD", update.Pair)
+
+	// Unsubscribed pairs are no longer delivered
+	subscription.Leave("test_exchange", "BTC/USD")
+	err = service.SaveOrderBookDelta(&models.OrderBookDelta{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 12})
+	assert.NoError(t, err)
+	assert.Len(t, subscription.Updates(), 0)
+
+	// Reconstructed books are delivered from memory when joining
+	assert.NoError(t, service.JoinOrderBook(subscription, "test_exchange", "BTC/USD"))
+	update = <-subscription.Updates()
+	assert.Equal(t, models.OrderBookUpdateSnapshot, update.Type)
+	assert.Equal(t, int64(12), update.Book.Sequence)
+	assert.Equal(t, []string{"100:2"}, levelStrings(update.Book.Bids))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeOrderBooks_ConcurrentSnapshot(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	stored := &models.OrderBook{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 1}
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(stored, nil).Once().Run(func(mock.Arguments) {
+		close(loading)
+		<-release
+	})
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Once()
+
+	subscription := service.SubscribeOrderBooks(8)
+	defer subscription.Close()
+
+	joined := make(chan error, 1)
+	go func() {
+		joined <- service.JoinOrderBook(subscription, "test_exchange", "BTC/USD")
+	}()
+	<-loading
+
+	// A snapshot saved while the stored one is loaded is delivered after it
+	saved := make(chan error, 1)
+	go func() {
+		saved <- service.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 2})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	assert.NoError(t, <-joined)
+	assert.NoError(t, <-saved)
+	assert.Equal(t, int64(1), (<-subscription.Updates()).Book.Sequence)
+	assert.Equal(t, int64(2), (<-subscription.Updates()).Book.Sequence)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeOrderBooks_SlowConsumer(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	mockRepo.On("FindLatestOrder", "test_exchange", "BTC/USD", time.Time{}).Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("FindPairPrecision", "test_exchange", "BTC/USD").Return(nil, gorm.ErrRecordNotFound).Once()
+	mockRepo.On("SaveOrder", mock.Anything).Return(nil).Times(3)
+
+	slow := service.SubscribeOrderBooks(2)
+	assert.NoError(t, service.JoinOrderBook(slow, "test_exchange", "BTC/USD"))
+
+	for i := int64(1); i <= 3; i++ {
+		err := service.SaveOrderBook(&models.OrderBookDTO{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: i})
+		assert.NoError(t, err)
+	}
+
+	// Buffered updates are still delivered before the channel is closed
+	var received int
+	for range slow.Updates() {
+		received++
+	}
+	assert.Equal(t, 2, received)
+	assert.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+
+	slow.Close()
+	assert.Empty(t, service.(*orderServiceImpl).hub.subscriptions)
+
+	// Closed subscriptions can not join again
+	assert.NoError(t, service.JoinOrderBook(slow, "test_exchange", "BTC/USD"))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeOrders(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	mockRepo.On("FindPairPrecision", mock.Anything, mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindHistoryOrder", mock.Anything).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("FindHistoryOrders", mock.Anything).Return([]*models.HistoryOrder{}, nil)
+	mockRepo.On("SaveOrderHistory", mock.Anything).Return(nil)
+	mockRepo.On("SaveOrderHistories", mock.Anything).Return(nil)
+
+	filter := &models.OrderStreamFilter{ClientName: "test_client", Pair: "BTC/USD"}
+	subscription, err := service.SubscribeOrders(filter, "", 8)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	client := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Label: "test_label", Pair: "BTC/USD"}
+	other := &models.Client{ClientName: "test_client", ExchangeName: "test_exchange", Label: "test_label", Pair: "ETH/USD"}
+
+	_, err = service.SaveOrder(other, &models.HistoryOrder{OrderID: "order-1"})
+	assert.NoError(t, err)
+	saved, err := service.SaveOrder(client, &models.HistoryOrder{OrderID: "order-2"})
+	assert.NoError(t, err)
+
+	// Only orders matching the filter are delivered
+	event := <-subscription.Events()
+	assert.Equal(t, "order-2", event.Order.OrderID)
+	assert.Equal(t, encodeEventID(saved), event.ID)
+
+	_, err = service.SaveOrders([]*models.HistoryOrderPayload{
+		{Client: *client, History: models.HistoryOrder{OrderID: "order-3", Type: "limit"}},
+		{Client: *other, History: models.HistoryOrder{OrderID: "order-4", Type: "limit"}},
+	})
+	assert.NoError(t, err)
+
+	event = <-subscription.Events()
+	assert.Equal(t, "order-3", event.Order.OrderID)
+	assert.Len(t, subscription.Events(), 0)
+}
+
+func TestSubscribeOrders_StatusUpdate(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	placedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	stored := &models.HistoryOrder{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusNew, UpdatedAt: placedAt}
+	mockRepo.On("FindHistoryOrder", "order-1").Return(stored, nil)
+	mockRepo.On("SaveOrderHistory", mock.Anything).Return(nil).Times(2)
+
+	subscription, err := service.SubscribeOrders(&models.OrderStreamFilter{ClientName: "test_client"}, "", 8)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	// Every saved state is delivered with its own position, even with an earlier update time
+	for _, status := range []string{models.OrderStatusPartiallyFilled, models.OrderStatusFilled} {
+		_, err = service.UpdateOrderStatus("order-1", &models.OrderStatusUpdate{Status: status, UpdatedAt: placedAt.Add(time.Second)})
+		assert.NoError(t, err)
+	}
+
+	first := <-subscription.Events()
+	second := <-subscription.Events()
+	assert.Equal(t, models.OrderStatusPartiallyFilled, first.Order.Status)
+	assert.Equal(t, models.OrderStatusFilled, second.Order.Status)
+	assert.True(t, models.EventPosition(first.Order).Before(models.EventPosition(second.Order)))
+	assert.NotEqual(t, first.ID, second.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeOrders_Resume(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	ingestedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	last := &models.HistoryOrder{OrderID: "order-1", IngestedAt: ingestedAt}
+	missed := []*models.HistoryOrder{
+		{OrderID: "order-2", ClientName: "test_client", Status: models.OrderStatusNew, IngestedAt: ingestedAt.Add(time.Second)},
+		{OrderID: "order-1", ClientName: "test_client", Status: models.OrderStatusFilled, IngestedAt: ingestedAt.Add(2 * time.Second)},
+		{OrderID: "order-2", ClientName: "test_client", Status: models.OrderStatusFilled, IngestedAt: ingestedAt.Add(3 * time.Second)},
+	}
+	hub := service.(*orderServiceImpl).orders
+	hub.lastIngest = missed[2].IngestedAt
+
+	filter := &models.OrderStreamFilter{ClientName: "test_client"}
+	position := &models.OrderEventPosition{IngestedAt: ingestedAt, OrderID: "order-1"}
+	mockRepo.On("FindOrdersSavedAfter", filter, position, OrderReplayPageSize).Run(func(args mock.Arguments) {
+		// Orders saved while the replay is read are delivered once, after the replay
+		hub.publish(missed[2], &models.HistoryOrder{OrderID: "order-3", ClientName: "test_client", IngestedAt: ingestedAt.Add(4 * time.Second)})
+	}).Return(missed, nil).Once()
+
+	subscription, err := service.SubscribeOrders(filter, encodeEventID(last), 8)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	assert.Equal(t, []string{"order-2:NEW", "order-1:FILLED", "order-2:FILLED", "order-3:"}, receiveOrders(t, subscription, 4))
+
+	_, err = service.SubscribeOrders(filter, "not an event", 8)
+	assert.ErrorIs(t, err, ErrInvalidEventID)
+
+	mockRepo.On("FindOrdersSavedAfter", filter, position, OrderReplayPageSize).Return(nil, gorm.ErrInvalidDB).Once()
+	_, err = service.SubscribeOrders(filter, encodeEventID(last), 8)
+	assert.ErrorIs(t, err, gorm.ErrInvalidDB)
+
+	mockRepo.AssertExpectations(t)
+	assert.Len(t, hub.subscriptions, 1)
+}
+
+func TestSubscribeOrders_ResumePages(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	ingestedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	page := make([]*models.HistoryOrder, OrderReplayPageSize)
+	for i := range page {
+		page[i] = &models.HistoryOrder{OrderID: fmt.Sprintf("order-%04d", i), IngestedAt: ingestedAt.Add(time.Duration(i+1) * time.Millisecond)}
+	}
+	next := []*models.HistoryOrder{
+		{OrderID: "order-a", IngestedAt: ingestedAt.Add(2 * time.Second)},
+		// Saves from the watermark on are delivered live rather than replayed
+		{OrderID: "order-b", IngestedAt: ingestedAt.Add(4 * time.Second)},
+	}
+	hub := service.(*orderServiceImpl).orders
+	hub.lastIngest = ingestedAt.Add(3 * time.Second)
+
+	filter := &models.OrderStreamFilter{}
+	position := &models.OrderEventPosition{IngestedAt: ingestedAt, OrderID: "order-0"}
+	lastOfPage := models.EventPosition(page[len(page)-1])
+	mockRepo.On("FindOrdersSavedAfter", filter, position, OrderReplayPageSize).Return(page, nil).Once()
+	mockRepo.On("FindOrdersSavedAfter", filter, &lastOfPage, OrderReplayPageSize).Return(next, nil).Once()
+
+	subscription, err := service.SubscribeOrders(filter, encodeEventID(&models.HistoryOrder{OrderID: "order-0", IngestedAt: ingestedAt}), 8)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	orders := receiveOrders(t, subscription, OrderReplayPageSize+1)
+	assert.Equal(t, "order-0000:", orders[0])
+	assert.Equal(t, "order-a:", orders[OrderReplayPageSize])
+
+	hub.publish(next[1])
+	assert.Equal(t, []string{"order-b:"}, receiveOrders(t, subscription, 1))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSubscribeOrders_ResumeFails(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	ingestedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	page := make([]*models.HistoryOrder, OrderReplayPageSize)
+	for i := range page {
+		page[i] = &models.HistoryOrder{OrderID: fmt.Sprintf("order-%04d", i), IngestedAt: ingestedAt.Add(time.Duration(i+1) * time.Millisecond)}
+	}
+	hub := service.(*orderServiceImpl).orders
+	hub.lastIngest = ingestedAt.Add(time.Hour)
+
+	filter := &models.OrderStreamFilter{}
+	mockRepo.On("FindOrdersSavedAfter", filter, mock.Anything, OrderReplayPageSize).Return(page, nil).Once()
+	mockRepo.On("FindOrdersSavedAfter", filter, mock.Anything, OrderReplayPageSize).Return(nil, gorm.ErrInvalidDB).Once()
+
+	subscription, err := service.SubscribeOrders(filter, encodeEventID(&models.HistoryOrder{OrderID: "order-0", IngestedAt: ingestedAt}), 8)
+	assert.NoError(t, err)
+	defer subscription.Close()
+
+	// The replayed orders are delivered, then the subscription is closed with the error
+	assert.Len(t, receiveOrders(t, subscription, OrderReplayPageSize), OrderReplayPageSize)
+	_, ok := <-subscription.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, subscription.Err(), gorm.ErrInvalidDB)
+	assert.Empty(t, hub.subscriptions)
+}
+
+func TestOrderHub_Save(t *testing.T) {
+	hub := newOrderHub()
+	subscription := &OrderSubscription{hub: hub, live: make(chan *models.OrderEvent, 8), done: make(chan struct{})}
+	subscription.events = subscription.live
+	hub.join(subscription)
+	defer subscription.Close()
+
+	// A save storing slowly does not hold back the next one, which is published after it
+	first := &models.HistoryOrder{OrderID: "order-1"}
+	second := &models.HistoryOrder{OrderID: "order-2"}
+	release := make(chan struct{})
+	stored := make(chan error)
+	go func() {
+		stored <- hub.save([]*models.HistoryOrder{first}, func() error {
+			<-release
+			return nil
+		})
+	}()
+	for {
+		hub.saveMu.Lock()
+		storing := len(hub.inFlight) > 0
+		hub.saveMu.Unlock()
+		if storing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.NoError(t, hub.save([]*models.HistoryOrder{second}, func() error { return nil }))
+	assert.True(t, first.IngestedAt.Before(second.IngestedAt))
+	assert.Equal(t, first.IngestedAt, hub.watermark())
+	assert.Len(t, subscription.Events(), 0)
+
+	close(release)
+	assert.NoError(t, <-stored)
+	assert.Equal(t, []string{"order-1:", "order-2:"}, receiveOrders(t, subscription, 2))
+	assert.Equal(t, second.IngestedAt.Add(time.Microsecond), hub.watermark())
+
+	// Orders that fail to store are not published
+	assert.ErrorIs(t, hub.save([]*models.HistoryOrder{{OrderID: "order-3"}}, func() error { return gorm.ErrInvalidDB }), gorm.ErrInvalidDB)
+	assert.Len(t, subscription.Events(), 0)
+}
+
+// receiveOrders receives n events of the subscription as order ID and status.
+func receiveOrders(t *testing.T, subscription *OrderSubscription, n int) []string {
+	t.Helper()
+
+	states := make([]string, 0, n)
+	for len(states) < n {
+		select {
+		case event, ok := <-subscription.Events():
+			if !ok {
+				t.Fatalf("subscription closed after %d events: %v", len(states), subscription.Err())
+			}
+			states = append(states, event.Order.OrderID+":"+event.Order.Status)
+		case <-time.After(time.Second):
+			t.Fatalf("received %d of %d events", len(states), n)
+		}
+	}
+	return states
+}
+
+func TestSubscribeOrders_SlowConsumer(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	subscription, err := service.SubscribeOrders(&models.OrderStreamFilter{}, "", 1)
+	assert.NoError(t, err)
+
+	hub := service.(*orderServiceImpl).orders
+	hub.publish(&models.HistoryOrder{OrderID: "order-1"}, &models.HistoryOrder{OrderID: "order-2"})
+
+	event, ok := <-subscription.Events()
+	assert.True(t, ok)
+	assert.Equal(t, "order-1", event.Order.OrderID)
+
+	_, ok = <-subscription.Events()
+	assert.False(t, ok)
+	assert.ErrorIs(t, subscription.Err(), ErrSlowConsumer)
+
+	subscription.Close()
+	assert.Empty(t, hub.subscriptions)
+}
+
+func TestExportOrderBooks(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	orders := []*models.OrderBook{
+		{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 1, Asks: models.Tuples{tuple("100.1", "1"), tuple("100.3", "2"), tuple("101.2", "4")}},
+		{Exchange: "test_exchange", Pair: "BTC/USD", Sequence: 2, Asks: models.Tuples{tuple("100.6", "3")}},
+	}
+	mockRepo.On("StreamOrders", "test_exchange", "BTC/USD", from, to, mock.Anything).Return(orders, nil)
+
+	var exported []*models.OrderBookDTO
+	err := service.ExportOrderBooks("test_exchange", "BTC/USD", from, to, 1, decimal.RequireFromString("0.5"), func(order *models.OrderBookDTO) error {
+		exported = append(exported, order)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, exported, 2) {
+		assert.Equal(t, []string{"100.5:3"}, levelStrings(exported[0].Asks))
+		assert.Equal(t, []string{"101:3"}, levelStrings(exported[1].Asks))
+	}
+
+	// Errors of fn stop the export
+	stop := errors.New("stop")
+	calls := 0
+	err = service.ExportOrderBooks("test_exchange", "BTC/USD", from, to, 0, decimal.Zero, func(order *models.OrderBookDTO) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportOrderHistory(t *testing.T) {
+	mockRepo := new(MockOrderRepository)
+	service := NewOrderService(mockRepo)
+
+	orders := []*models.HistoryOrder{{OrderID: "order-1"}, {OrderID: "order-2"}}
+	mockRepo.On("StreamOrderHistory", &models.OrderHistoryFilter{ClientName: "test_client", Sort: models.SortAsc}, mock.Anything).Return(orders, nil)
 
-	err := service.SaveOrder(client, order)
+	var exported []string
+	err := service.ExportOrderHistory(&models.OrderHistoryFilter{ClientName: "test_client"}, func(order *models.HistoryOrder) error {
+		exported = append(exported, order.OrderID)
+		return nil
+	})
 	assert.NoError(t, err)
+	assert.Equal(t, []string{"order-1", "order-2"}, exported)
 
 	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+)
+
+// ErrInvalidStatusTransition is returned when an order cannot move from its current status to the requested one.
+var ErrInvalidStatusTransition = errors.New("invalid order status transition")
+
+/*
+Statuses an order may move to from each status.
+Filled, cancelled and rejected orders are final and have no transitions.
+*/
+var statusTransitions = map[string][]string{
+	models.OrderStatusNew: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCancelled,
+		models.OrderStatusRejected,
+	},
+	models.OrderStatusPartiallyFilled: {
+		models.OrderStatusPartiallyFilled,
+		models.OrderStatusFilled,
+		models.OrderStatusCancelled,
+	},
+	models.OrderStatusFilled:    {},
+	models.OrderStatusCancelled: {},
+	models.OrderStatusRejected:  {},
+}
+
+func isKnownStatus(status string) bool {
+	_, ok := statusTransitions[status]
+	return ok
+}
+
+func canTransition(from, to string) bool {
+	for _, status := range statusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+validateStatusUpdate checks that an order can move to the status of the update.
+Returns a ValidationError for an unknown status or an update not later than the current state,
+as the stored state with the latest update time is the current one, and ErrInvalidStatusTransition
+if the transition is not allowed.
+*/
+func validateStatusUpdate(order *models.HistoryOrder, update *models.OrderStatusUpdate, updatedAt time.Time) error {
+	if !isKnownStatus(update.Status) {
+		return &ValidationError{Violations: []Violation{{
+			Field:   "status",
+			Message: fmt.Sprintf("unknown status %q", update.Status),
+		}}}
+	}
+
+	if !updatedAt.After(order.UpdatedAt) {
+		return &ValidationError{Violations: []Violation{{
+			Field:   "updatedAt",
+			Message: fmt.Sprintf("%v does not follow the current state updated at %v", updatedAt, order.UpdatedAt),
+		}}}
+	}
+
+	if !canTransition(order.Status, update.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, order.Status, update.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// How long a looked up pair precision, or its absence, is reused before reading it again.
+const precisionCacheTTL = time.Minute
+
+type precisionEntry struct {
+	precision *models.PairPrecision
+	loaded    time.Time
+}
+
+// precisionCache keeps pair precisions in memory so validation does not query the database on every save.
+type precisionCache struct {
+	mu      sync.RWMutex
+	entries map[string]precisionEntry
+}
+
+func newPrecisionCache() *precisionCache {
+	return &precisionCache{entries: make(map[string]precisionEntry)}
+}
+
+func (c *precisionCache) get(exchangeName, pair string) (*models.PairPrecision, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[bookKey(exchangeName, pair)]
+	if !ok || time.Since(entry.loaded) > precisionCacheTTL {
+		return nil, false
+	}
+	return entry.precision, true
+}
+
+func (c *precisionCache) set(exchangeName, pair string, precision *models.PairPrecision) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[bookKey(exchangeName, pair)] = precisionEntry{precision: precision, loaded: time.Now()}
+}
+
+/*
+pairPrecision returns the precision of a trading pair on an exchange, or nil if none is stored.
+*/
+func (osi *orderServiceImpl) pairPrecision(exchangeName, pair string) (*models.PairPrecision, error) {
+	if precision, ok := osi.precisions.get(exchangeName, pair); ok {
+		return precision, nil
+	}
+
+	precision, err := osi.repo.FindPairPrecision(exchangeName, pair)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	osi.precisions.set(exchangeName, pair, precision)
+	return precision, nil
+}
+
+/*
+validateOrderPrecision checks that the prices and quantity of a history order fit the precision of its pair.
+Returns a ValidationError with all violations, or nil for a valid order.
+*/
+func validateOrderPrecision(order *models.HistoryOrder, precision *models.PairPrecision) error {
+	if precision == nil {
+		return nil
+	}
+
+	var violations []Violation
+	violations = append(violations, validateScale("baseQty", order.BaseQty, precision.QtyScale)...)
+	violations = append(violations, validateScale("price", order.Price, precision.PriceScale)...)
+	violations = append(violations, validateScale("lowestSellPrc", order.LowestSellPrc, precision.PriceScale)...)
+	violations = append(violations, validateScale("highestBuyPrc", order.HighestBuyPrc, precision.PriceScale)...)
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
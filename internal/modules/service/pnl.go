@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kymaka/vortex-test/internal/models"
+	"github.com/kymaka/vortex-test/internal/modules/analytics"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// pnlKey identifies the position PnL is computed for.
+type pnlKey struct {
+	client   string
+	exchange string
+	label    string
+	pair     string
+}
+
+type timedTrade struct {
+	analytics.Trade
+	time time.Time
+}
+
+/*
+GetClientPnL replays the orders of a client matching the filter and computes PnL per exchange, label and pair.
+Orders with fills are replayed fill by fill, FILLED orders without fills are treated as executed in full at their price
+and other orders without fills are not replayed. Every execution is charged its share of the order commission.
+Orders whose side is neither buy nor sell are skipped and reported in the PnL of their position.
+Open positions are marked to the mid of the latest stored order book, if there is one with both sides.
+Returns gorm.ErrRecordNotFound if the client has no matching orders.
+*/
+func (osi *orderServiceImpl) GetClientPnL(filter *models.OrderHistoryFilter, method string) ([]*analytics.PnL, error) {
+	orders, err := osi.allOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := osi.findFills(orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return osi.replayPnL(orders, fills, method)
+}
+
+/*
+GetExecutionQuality evaluates the orders of a client matching the filter against the book at their placement,
+per order and aggregated by the algorithm that placed them.
+Returns gorm.ErrRecordNotFound if the client has no matching orders.
+*/
+func (osi *orderServiceImpl) GetExecutionQuality(filter *models.OrderHistoryFilter) (*analytics.ExecutionQualityReport, error) {
+	orders, err := osi.allOrders(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fills, err := osi.findFills(orders)
+	if err != nil {
+		return nil, err
+	}
+	attachFillSummaries(orders, fills)
+
+	return analytics.ExecutionQuality(orders), nil
+}
+
+/*
+replayPnL computes PnL per client, exchange, label and pair from the orders and their fills,
+sorted by those keys. Open positions are marked to the latest order book.
+*/
+func (osi *orderServiceImpl) replayPnL(orders []*models.HistoryOrder, fills []*models.Fill, method string) ([]*analytics.PnL, error) {
+	fillsByOrder := make(map[string][]*models.Fill)
+	for _, fill := range fills {
+		fillsByOrder[fill.OrderID] = append(fillsByOrder[fill.OrderID], fill)
+	}
+
+	trades := make(map[pnlKey][]timedTrade)
+	skipped := make(map[pnlKey][]string)
+	for _, order := range orders {
+		key := pnlKey{client: order.ClientName, exchange: order.ExchangeName, label: order.Label, pair: order.Pair}
+		timed := trades[key]
+		switch strings.ToLower(order.Side) {
+		case analytics.SideBuy, analytics.SideSell:
+			timed = append(timed, orderTrades(order, fillsByOrder[order.OrderID])...)
+		default:
+			skipped[key] = append(skipped[key], order.OrderID)
+		}
+		trades[key] = timed
+	}
+
+	keys := make([]pnlKey, 0, len(trades))
+	for key := range trades {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].client != keys[j].client {
+			return keys[i].client < keys[j].client
+		}
+		if keys[i].exchange != keys[j].exchange {
+			return keys[i].exchange < keys[j].exchange
+		}
+		if keys[i].label != keys[j].label {
+			return keys[i].label < keys[j].label
+		}
+		return keys[i].pair < keys[j].pair
+	})
+
+	result := make([]*analytics.PnL, 0, len(keys))
+	for _, key := range keys {
+		timed := trades[key]
+		sort.SliceStable(timed, func(i, j int) bool { return timed[i].time.Before(timed[j].time) })
+
+		replay := make([]analytics.Trade, len(timed))
+		for i, trade := range timed {
+			replay[i] = trade.Trade
+		}
+
+		pnl, err := analytics.ComputePnL(replay, method)
+		if err != nil {
+			return nil, err
+		}
+		pnl.Exchange, pnl.Label, pnl.Pair = key.exchange, key.label, key.pair
+		pnl.SkippedOrders = skipped[key]
+
+		if !pnl.Position.IsZero() {
+			if err := osi.markPnL(pnl); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, pnl)
+	}
+
+	return result, nil
+}
+
+// allOrders pages through the whole order history matching the filter, oldest first.
+func (osi *orderServiceImpl) allOrders(filter *models.OrderHistoryFilter) ([]*models.HistoryOrder, error) {
+	query := *filter
+	query.Sort = models.SortAsc
+	query.Cursor = ""
+
+	var orders []*models.HistoryOrder
+	var after *models.OrderHistoryCursor
+	for {
+		page, err := osi.repo.FindOrderHistory(&query, after, MaxHistoryLimit)
+		if err != nil {
+			if after != nil && errors.Is(err, gorm.ErrRecordNotFound) {
+				break
+			}
+			return nil, err
+		}
+
+		orders = append(orders, page...)
+		if len(page) < MaxHistoryLimit {
+			break
+		}
+
+		last := page[len(page)-1]
+		after = &models.OrderHistoryCursor{TimePlaced: last.TimePlaced, OrderID: last.OrderID, Sort: query.Sort}
+	}
+
+	return orders, nil
+}
+
+/*
+orderTrades returns the executions of an order replayed for PnL.
+Without fills, only a FILLED order is known to have executed, in full at its price.
+Every fill is charged the share of the order commission for its quantity, up to the whole commission.
+*/
+func orderTrades(order *models.HistoryOrder, fills []*models.Fill) []timedTrade {
+	if len(fills) == 0 {
+		if order.Status != models.OrderStatusFilled {
+			return nil
+		}
+
+		return []timedTrade{{
+			Trade: analytics.Trade{
+				Side:       order.Side,
+				BaseQty:    order.BaseQty,
+				Price:      order.Price,
+				Commission: order.CommissionFor(order.BaseQty),
+			},
+			time: order.TimePlaced,
+		}}
+	}
+
+	trades := make([]timedTrade, len(fills))
+	executed, charged := decimal.Zero, decimal.Zero
+	for i, fill := range fills {
+		executed = executed.Add(fill.BaseQty)
+		commission := order.CommissionFor(executed).Sub(charged)
+		charged = charged.Add(commission)
+
+		trades[i] = timedTrade{
+			Trade: analytics.Trade{Side: order.Side, BaseQty: fill.BaseQty, Price: fill.Price, Commission: commission},
+			time:  fill.ExecutedAt,
+		}
+	}
+
+	return trades
+}
+
+// markPnL values an open position at the mid of the latest order book of its exchange and pair.
+func (osi *orderServiceImpl) markPnL(pnl *analytics.PnL) error {
+	book, err := osi.GetLatestOrderBook(pnl.Exchange, pnl.Pair, time.Time{})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	asks := analytics.SortedLevels(book.Asks, false)
+	bids := analytics.SortedLevels(book.Bids, true)
+	if len(asks) == 0 || len(bids) == 0 {
+		return nil
+	}
+
+	pnl.Mark(analytics.Mid(bids[0], asks[0]))
+	return nil
+}
@@ -1,23 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	orderv1 "github.com/kymaka/vortex-test/api/order/v1"
 	"github.com/kymaka/vortex-test/internal/infrastructure/db"
 	"github.com/kymaka/vortex-test/internal/modules/controller"
 	"github.com/kymaka/vortex-test/internal/modules/repository"
+	"github.com/kymaka/vortex-test/internal/modules/rpc"
 	"github.com/kymaka/vortex-test/internal/modules/service"
 
 	_ "github.com/kymaka/vortex-test/docs"
 
+	"github.com/shopspring/decimal"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/httprate"
+	"google.golang.org/grpc"
 )
 
+// Time the HTTP and gRPC servers each have to finish their calls when shutting down
+const shutdownTimeout = 10 * time.Second
+
 func main() {
 	gormDB, err := db.Connect(".env")
 	if err != nil {
@@ -30,9 +46,31 @@ func main() {
 		log.Fatalf("failed to migrate schema: %v", err)
 	}
 
-	repository := repository.NewOrderRepository(gormDB)
-	service := service.NewOrderService(repository)
-	controller := controller.NewOrderController(service)
+	// Prices and quantities are encoded as JSON numbers unless strings are requested to keep full precision
+	decimal.MarshalJSONWithoutQuotes = os.Getenv("DECIMAL_JSON_STRINGS") != "true"
+
+	orderRepository := repository.NewOrderRepository(gormDB)
+
+	// Single rows are buffered and inserted in batches if enabled, ClickHouse creates a part per insert
+	var buffer *repository.BufferedOrderRepository
+	if os.Getenv("WRITE_BUFFER_ENABLED") == "true" {
+		config := writeBufferConfig()
+		if path := os.Getenv("WRITE_BUFFER_DEAD_LETTER_FILE"); path != "" {
+			deadLetters, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				log.Fatalf("failed to open dead letter file: %v", err)
+			}
+			defer deadLetters.Close()
+			config.DeadLetter = deadLetters
+		}
+
+		buffer = repository.NewBufferedOrderRepository(orderRepository, config)
+		expvar.Publish("writeBuffer", expvar.Func(func() any { return buffer.Stats() }))
+		orderRepository = buffer
+	}
+
+	service := service.NewOrderService(orderRepository)
+	controller := controller.NewOrderController(service, allowedOrigins()...)
 
 	r := chi.NewMux()
 
@@ -42,15 +80,166 @@ func main() {
 		r.Use(httprate.LimitByIP(100, 1*time.Second))
 
 		r.Get("/order/book", controller.GetOrderBookHandler)
+		r.Get("/order/book/latest", controller.GetLatestOrderBookHandler)
+		r.Get("/order/book/metrics", controller.GetOrderBookMetricsHandler)
+		r.Get("/order/book/impact", controller.GetMarketImpactHandler)
+		r.Get("/order/book/consolidated", controller.GetConsolidatedOrderBookHandler)
 		r.Get("/order/history", controller.GetOrderHistoryHandler)
+		r.Get("/order/history/stream", controller.StreamOrderHistoryHandler)
+		r.Get("/pair/precision", controller.GetPairPrecisionHandler)
+		r.Get("/client/pnl", controller.GetClientPnLHandler)
+		r.Get("/order/execution-quality", controller.GetExecutionQualityHandler)
+		r.Get("/algorithms", controller.GetAlgorithmsHandler)
+		r.Get("/algorithms/{name}/stats", controller.GetAlgorithmStatsHandler)
+		r.Get("/candles", controller.GetCandlesHandler)
+		r.Get("/fees", controller.GetFeeReportHandler)
+		r.Get("/export", controller.ExportHandler)
+		r.Get("/ws/order/book", controller.StreamOrderBookHandler)
 	})
 
 	r.Group(func(r chi.Router) {
 		r.Use(httprate.LimitByIP(200, 1*time.Second))
 
 		r.Post("/order/book", controller.SaveOrderBookHandler)
+		r.Post("/order/book/delta", controller.SaveOrderBookDeltaHandler)
+		r.Post("/order/book/batch", controller.SaveOrderBookBatchHandler)
 		r.Post("/order/history", controller.SaveOrderHandler)
+		r.Post("/order/history/batch", controller.SaveOrderBatchHandler)
+		r.Post("/order/history/search", controller.SearchOrderHistoryHandler)
+		r.Post("/order/history/{id}/status", controller.UpdateOrderStatusHandler)
+		r.Post("/order/fills", controller.SaveFillsHandler)
+		r.Post("/pair/precision", controller.SavePairPrecisionHandler)
 	})
 
-	http.ListenAndServe(":8080", r)
+	server := &http.Server{Addr: ":8080", Handler: r}
+	// Shutdown does not wait for streams, they end once it starts
+	server.RegisterOnShutdown(controller.CloseStreams)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+
+	// Internal metrics are served on their own address, which only listens on loopback by default
+	debugAddr := os.Getenv("DEBUG_ADDR")
+	if debugAddr == "" {
+		debugAddr = "127.0.0.1:6060"
+	}
+	debugMux := http.NewServeMux()
+	debugMux.Handle("/debug/vars", expvar.Handler())
+	debugServer := &http.Server{Addr: debugAddr, Handler: debugMux}
+	go func() {
+		if err := debugServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("failed to serve debug endpoints: %v", err)
+		}
+	}()
+
+	// The gRPC API shares the service with the HTTP API on its own port
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	listener, err := net.Listen("tcp", ":"+grpcPort)
+	if err != nil {
+		log.Fatalf("failed to listen on gRPC port: %v", err)
+	}
+	// gRPC calls are limited per peer address like HTTP requests, streams count as one call
+	limiter := rpc.NewRateLimiter(100, 200, 1*time.Second)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(limiter.UnaryInterceptor),
+		grpc.ChainStreamInterceptor(limiter.StreamInterceptor),
+	)
+	orderv1.RegisterOrderServiceServer(grpcServer, rpc.NewOrderServer(service))
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.Fatalf("failed to serve gRPC: %v", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+	if err := debugServer.Shutdown(ctx); err != nil {
+		log.Printf("failed to shut down debug server: %v", err)
+	}
+
+	// gRPC streams do not end by themselves, they are cancelled if graceful stop does not finish in time
+	grpcCtx, grpcCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer grpcCancel()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-grpcCtx.Done():
+		grpcServer.Stop()
+	}
+
+	if err := service.Close(); err != nil {
+		log.Printf("failed to persist order books: %v", err)
+	}
+
+	if buffer != nil {
+		if err := buffer.Close(); err != nil {
+			log.Printf("failed to flush write buffer: %v", err)
+		}
+	}
+}
+
+/*
+writeBufferConfig reads the write buffer limits from WRITE_BUFFER_FLUSH_SIZE, WRITE_BUFFER_FLUSH_INTERVAL,
+WRITE_BUFFER_CAPACITY, WRITE_BUFFER_ENQUEUE_TIMEOUT, WRITE_BUFFER_MAX_RETRIES and WRITE_BUFFER_MAX_BACKOFF,
+durations as accepted by time.ParseDuration. Unset limits keep their defaults.
+*/
+func writeBufferConfig() repository.BufferConfig {
+	var config repository.BufferConfig
+
+	for name, limit := range map[string]*int{
+		"WRITE_BUFFER_FLUSH_SIZE":  &config.FlushSize,
+		"WRITE_BUFFER_CAPACITY":    &config.Capacity,
+		"WRITE_BUFFER_MAX_RETRIES": &config.MaxFlushRetries,
+	} {
+		if value := os.Getenv(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				log.Fatalf("invalid %s: %v", name, err)
+			}
+			*limit = n
+		}
+	}
+
+	for name, limit := range map[string]*time.Duration{
+		"WRITE_BUFFER_FLUSH_INTERVAL":  &config.FlushInterval,
+		"WRITE_BUFFER_ENQUEUE_TIMEOUT": &config.EnqueueTimeout,
+		"WRITE_BUFFER_MAX_BACKOFF":     &config.MaxRetryBackoff,
+	} {
+		if value := os.Getenv(name); value != "" {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				log.Fatalf("invalid %s: %v", name, err)
+			}
+			*limit = d
+		}
+	}
+
+	return config
+}
+
+// allowedOrigins returns the comma separated origins browsers may open order book streams from besides the server's own.
+func allowedOrigins() []string {
+	var origins []string
+	for _, origin := range strings.Split(os.Getenv("WS_ALLOWED_ORIGINS"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	return origins
 }